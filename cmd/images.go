@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/docker/go-units"
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var olderThan time.Duration
+
+func init() {
+	pf := imagesPruneCmd.PersistentFlags()
+	pf.BoolVar(&debug, "debug", false, "print debug logs")
+	pf.DurationVar(&olderThan, "older-than", 7*24*time.Hour, "only remove images created more than this long ago, 0 removes all unused images")
+
+	rootCmd.AddCommand(imagesCmd)
+	imagesCmd.AddCommand(imagesPruneCmd)
+}
+
+var imagesCmd = &cobra.Command{
+	Use:   "images",
+	Short: "Image related commands",
+	Long:  "Commands for managing the Docker images built by Hide.",
+}
+
+var imagesPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Removes stale images built by Hide",
+	Long:  "Removes the Docker images built by Hide for devcontainers that are not used by any container.",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		setupLogger(debug)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+		if err != nil {
+			log.Fatal().Err(err).Msg("Cannot initialize docker client")
+		}
+		defer dockerClient.Close()
+
+		imageManager := devcontainer.NewImageManager(dockerClient, devcontainer.NewDockerConfigRegistryCredentials(devcontainer.DefaultDockerConfigPath()))
+		removed, reclaimed, err := imageManager.PruneImages(context.Background(), olderThan)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to remove images")
+		}
+
+		for _, id := range removed {
+			fmt.Println(id)
+		}
+		fmt.Printf("Removed %d images, reclaimed %s\n", len(removed), units.HumanSize(float64(reclaimed)))
+	},
+}
@@ -8,12 +8,13 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"runtime"
 	"syscall"
 	"time"
 
 	"github.com/docker/docker/client"
 	"github.com/go-playground/validator/v10"
-	"github.com/hide-org/hide/pkg/devcontainer"
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
 	"github.com/hide-org/hide/pkg/files"
 	"github.com/hide-org/hide/pkg/git"
 	"github.com/hide-org/hide/pkg/gitignore"
@@ -36,9 +37,10 @@ const (
 )
 
 var (
-	envPath string
-	debug   bool
-	port    int
+	envPath      string
+	debug        bool
+	port         int
+	serverBinary string
 )
 
 func init() {
@@ -46,6 +48,7 @@ func init() {
 	pf.StringVar(&envPath, "env", DefaultDotEnvPath, "path to the .env file")
 	pf.BoolVar(&debug, "debug", false, "run service in a debug mode")
 	pf.IntVar(&port, "port", 8080, "service port")
+	pf.StringVar(&serverBinary, "server-binary", "", "path to the Linux hide binary copied into devcontainers, defaults to this executable on Linux")
 }
 
 var runCmd = &cobra.Command{
@@ -76,19 +79,17 @@ var runCmd = &cobra.Command{
 			}
 		}
 
-		dockerUser := os.Getenv("DOCKER_USER")
-		dockerToken := os.Getenv("DOCKER_TOKEN")
-
-		if dockerUser == "" || dockerToken == "" {
-			log.Warn().Msg("DOCKER_USER or DOCKER_TOKEN environment variables are empty. This might cause problems when pulling images from Docker Hub.")
-		}
-
 		dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
 		if err != nil {
 			log.Fatal().Err(err).Msg("Cannot initialize docker client")
 		}
 
-		containerRunner := devcontainer.NewDockerRunner(devcontainer.NewExecutorImpl(), devcontainer.NewImageManager(dockerClient, random.String, devcontainer.NewDockerHubRegistryCredentials(dockerUser, dockerToken)), devcontainer.NewDockerContainerManager(dockerClient))
+		var containerManagerOpts []devcontainer.ContainerManagerOption
+		if binary := serverBinaryPath(); binary != "" {
+			containerManagerOpts = append(containerManagerOpts, devcontainer.ContainerManagerWithServerBinary(binary))
+		}
+
+		containerRunner := devcontainer.NewDockerRunner(devcontainer.NewExecutorImpl(), devcontainer.NewImageManager(dockerClient, registryCredentials(), devcontainer.ImageManagerWithOutput(os.Stderr)), devcontainer.NewDockerContainerManager(dockerClient, containerManagerOpts...))
 		projectStore := project.NewInMemoryStore(make(map[string]*model.Project))
 		home, err := os.UserHomeDir()
 		if err != nil {
@@ -113,6 +114,8 @@ var runCmd = &cobra.Command{
 			WithGetProjectHandler(handlers.GetProjectHandler{Manager: projectManager}).
 			WithGetProjectsHandler(handlers.GetProjectsHandler{Manager: projectManager}).
 			WithListTasksHandler(handlers.ListTasksHandler{Manager: projectManager}).
+			WithListPortsHandler(handlers.ListPortsHandler{Manager: projectManager}).
+			WithListLifecycleLogsHandler(handlers.ListLifecycleLogsHandler{Manager: projectManager}).
 			WithCreateFileHandler(handlers.CreateFileHandler{ProjectManager: projectManager}).
 			WithListFilesHandler(handlers.ListFilesHandler{ProjectManager: projectManager}).
 			WithReadFileHandler(middleware.PathValidator(handlers.ReadFileHandler{ProjectManager: projectManager})).
@@ -158,6 +161,41 @@ var runCmd = &cobra.Command{
 	},
 }
 
+// registryCredentials returns the Docker Hub credentials of DOCKER_USER and DOCKER_TOKEN if they are set, which are not
+// sent to other registries. Otherwise, the credentials of every registry are resolved from the Docker config, like the
+// Docker CLI does.
+func registryCredentials() devcontainer.RegistryCredentials {
+	dockerUser := os.Getenv("DOCKER_USER")
+	dockerToken := os.Getenv("DOCKER_TOKEN")
+
+	if dockerUser != "" && dockerToken != "" {
+		return devcontainer.NewDockerHubRegistryCredentials(dockerUser, dockerToken)
+	}
+
+	return devcontainer.NewDockerConfigRegistryCredentials(devcontainer.DefaultDockerConfigPath())
+}
+
+// serverBinaryPath returns the hide binary copied into devcontainers, the --server-binary flag or this executable. The
+// executable is only used on Linux, as devcontainers are Linux containers.
+func serverBinaryPath() string {
+	if serverBinary != "" {
+		return serverBinary
+	}
+
+	if runtime.GOOS != "linux" {
+		log.Warn().Msg("Devcontainers do not get a hide server, set --server-binary to a Linux hide binary")
+		return ""
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		log.Warn().Err(err).Msg("Cannot find the hide executable, devcontainers do not get a hide server")
+		return ""
+	}
+
+	return executable
+}
+
 func setupLogger(debug bool) {
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnixMs
 	output := zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339Nano}
@@ -9,16 +9,22 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"sort"
 	"syscall"
 	"time"
 
+	"github.com/hide-org/hide/pkg/completion"
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
+	"github.com/hide-org/hide/pkg/diagnostics"
 	"github.com/hide-org/hide/pkg/files"
 	"github.com/hide-org/hide/pkg/gitignore"
 	"github.com/hide-org/hide/pkg/handlers/v2"
 	"github.com/hide-org/hide/pkg/lsp/v2"
 	lang "github.com/hide-org/hide/pkg/lsp/v2/languages"
 	"github.com/hide-org/hide/pkg/middleware"
+	"github.com/hide-org/hide/pkg/navigation"
 	"github.com/hide-org/hide/pkg/outline"
+	"github.com/hide-org/hide/pkg/repomap"
 	"github.com/hide-org/hide/pkg/symbols"
 	"github.com/hide-org/hide/pkg/tasks"
 	"github.com/hide-org/hide/pkg/util"
@@ -28,8 +34,11 @@ import (
 )
 
 var (
-	workspaceDir string
-	lspBinaryDir string
+	workspaceDir      string
+	lspBinaryDir      string
+	languageThreshold float64
+	serverVersions    map[string]string
+	serverConfigPath  string
 )
 
 func init() {
@@ -39,7 +48,10 @@ func init() {
 	pf.IntVar(&port, "port", 8080, "service port")
 	cwd, _ := os.Getwd() // how can it fail?
 	pf.StringVar(&workspaceDir, "workspace-dir", cwd, "path to workspace directory")
-	pf.StringVar(&lspBinaryDir, "binary-dir", "", "path to directory where language server binaries are installed")
+	pf.StringVar(&lspBinaryDir, "binary-dir", defaultBinaryDir(), "path to directory where language server binaries are installed")
+	pf.StringVar(&serverConfigPath, "language-servers", "", "path to a YAML or JSON file declaring language servers not built into hide, in the shape of customizations.hide")
+	pf.StringToStringVar(&serverVersions, "server-version", nil, "pin language servers to versions, e.g. gopls=v0.16.2,pyright=1.1.385")
+	pf.Float64Var(&languageThreshold, "language-threshold", 0.1, "minimum share of source code (0-1) for a language to get a language server")
 
 	rootCmd.AddCommand(serverCmd)
 	serverCmd.AddCommand(serverRunCmd)
@@ -79,32 +91,41 @@ var serverRunCmd = &cobra.Command{
 			}
 		}
 
-		delegate := lang.NewDefaultDelegate(afero.NewOsFs(), *http.DefaultClient, workspaceDir, lspBinaryDir)
-		if err := lsp.SetupServers(cmd.Context(), delegate); err != nil {
-			// this should work (in the future)
-			panic(err)
-		}
-
-		languageDetector := lsp.NewLanguageDetector()
+		settings, configs := languageServers(workspaceDir, serverConfigPath)
+		languageDetector := lsp.NewLanguageDetector(registerLanguageServers(configs)...)
 		diagnosticsStore := lsp.NewDiagnosticsStore()
 		clientPool := lsp.NewClientPool()
 
-		lspService := lsp.NewService(languageDetector, diagnosticsStore, clientPool, "file://"+workspaceDir)
+		lspService := lsp.NewService(languageDetector, diagnosticsStore, clientPool, "file://"+workspaceDir, lsp.ServiceWithServerSettings(settings))
 		fileService := files.NewService(gitignore.NewMatcherFactory(), lspService, afero.NewBasePathFs(afero.NewOsFs(), workspaceDir))
-		languages, err := detectLanguages(fileService, languageDetector)
+		languages, err := detectLanguages(fileService, languageDetector, languageThreshold)
 		if err != nil {
-			log.Error().Err(err).Msg("Failed to detect languages")
-			panic(err)
+			// the server is still useful without language servers
+			log.Error().Err(err).Msg("Failed to detect languages, language servers are not started")
+		}
+
+		delegate := lang.NewDefaultDelegate(afero.NewOsFs(), *http.DefaultClient, workspaceDir, lspBinaryDir, lang.DelegateWithPinnedVersions(serverVersions))
+		if len(languages) > 0 {
+			if err := lsp.SetupServers(cmd.Context(), delegate, languages...); err != nil {
+				log.Warn().Err(err).Msg("Some language servers are not available")
+			}
 		}
 
 		for _, lang := range languages {
-			lspService.StartServer(context.Background(), lang)
+			if err := lspService.StartServer(context.Background(), lang); err != nil {
+				log.Warn().Err(err).Str("languageId", lang).Msg("Failed to start language server")
+				continue
+			}
 			defer lspService.StopServer(context.Background(), lang)
 		}
 
 		taskService := tasks.NewService(tasks.NewExecutorImpl(), map[string]tasks.Task{}, workspaceDir)
-		symbolSearch := symbols.NewService(lspService)
-		outlineService := outline.NewService(lspService, workspaceDir)
+		symbolSearch := symbols.NewService(lspService, fileService)
+		outlineService := outline.NewService(lspService, fileService, workspaceDir)
+		diagnosticsService := diagnostics.NewService(lspService, workspaceDir)
+		navigationService := navigation.NewService(fileService, lspService, workspaceDir)
+		completionService := completion.NewService(fileService, lspService, workspaceDir)
+		repoMapService := repomap.NewService(fileService, outlineService, symbolSearch)
 		router := handlers.
 			NewRouter().
 			WithCreateTaskHandler(handlers.CreateTaskHandler{Tasks: taskService}).
@@ -117,6 +138,14 @@ var serverRunCmd = &cobra.Command{
 			WithSearchFileHandler(handlers.SearchFilesHandler{Files: fileService}).
 			WithSearchSymbolsHandler(handlers.NewSearchSymbolsHandler(symbolSearch)).
 			WithDocumentOutlineHandler(handlers.DocumentOutline{Outline: outlineService}).
+			WithListOutlinesHandler(handlers.ListOutlinesHandler{Outline: outlineService}).
+			WithRepoMapHandler(handlers.RepoMapHandler{RepoMap: repoMapService}).
+			WithListDiagnosticsHandler(handlers.ListDiagnosticsHandler{Diagnostics: diagnosticsService}).
+			WithListLanguageServersHandler(handlers.ListLanguageServersHandler{LSP: lspService}).
+			WithCallHierarchyHandler(middleware.PathValidator(handlers.CallHierarchyHandler{Navigation: navigationService})).
+			WithImplementationsHandler(middleware.PathValidator(handlers.ImplementationsHandler{Navigation: navigationService})).
+			WithCompletionHandler(middleware.PathValidator(handlers.CompletionHandler{Completion: completionService})).
+			WithSignatureHelpHandler(middleware.PathValidator(handlers.SignatureHelpHandler{Completion: completionService})).
 			Build()
 
 		addr := fmt.Sprintf("0.0.0.0:%d", port)
@@ -154,14 +183,125 @@ var serverRunCmd = &cobra.Command{
 	},
 }
 
-func detectLanguages(fileService files.Service, languageDetector lsp.LanguageDetector) ([]lang.LanguageID, error) {
+func detectLanguages(fileService files.Service, languageDetector lsp.LanguageDetector, threshold float64) ([]lang.LanguageID, error) {
 	files, err := fileService.ListFiles(context.Background(), files.ListFilesWithContent())
 	if err != nil {
 		return nil, fmt.Errorf("failed to list files: %w", err)
 	}
 
-	// TODO: handle multiple main language
-	language := languageDetector.DetectMainLanguage(files)
-	log.Debug().Msgf("Detected main language %s", language)
-	return []lang.LanguageID{language}, nil
+	languages := languageDetector.DetectMainLanguages(files, threshold)
+	log.Debug().Msgf("Detected main languages %v", languages)
+	return languages, nil
+}
+
+// languageServers reads customizations.hide.languageServers in the workspace's devcontainer.json and the language server
+// config file, which wins. Customizations with a command declare servers, the others override settings of built-in servers.
+func languageServers(workspaceDir string, configPath string) (map[lang.ServerName]lsp.ServerSettings, map[lang.ServerName]lang.ServerConfig) {
+	settings := make(map[lang.ServerName]lsp.ServerSettings)
+	configs := make(map[lang.ServerName]lang.ServerConfig)
+
+	for name, customization := range devcontainerLanguageServers(workspaceDir) {
+		if customization.Command == "" {
+			settings[name] = lsp.ServerSettings{
+				InitializationOptions: customization.InitializationOptions,
+				Settings:              customization.Settings,
+			}
+			continue
+		}
+
+		configs[name] = lang.ServerConfig{
+			Command:               customization.Command,
+			Args:                  customization.Args,
+			Env:                   customization.Env,
+			Languages:             customization.Languages,
+			FilePatterns:          customization.FilePatterns,
+			InitializationOptions: customization.InitializationOptions,
+			Settings:              customization.Settings,
+		}
+	}
+
+	if configPath == "" {
+		return settings, configs
+	}
+
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		log.Warn().Err(err).Str("path", configPath).Msg("Failed to read language server config")
+		return settings, configs
+	}
+
+	declared, err := lang.ParseServerConfigs(content)
+	if err != nil {
+		log.Warn().Err(err).Str("path", configPath).Msg("Failed to parse language server config")
+		return settings, configs
+	}
+
+	for name, config := range declared {
+		configs[name] = config
+		delete(settings, name)
+	}
+
+	return settings, configs
+}
+
+// registerLanguageServers registers adapters of the declared language servers and returns the file patterns of their
+// languages for the language detector.
+func registerLanguageServers(configs map[lang.ServerName]lang.ServerConfig) []lsp.LanguageDetectorOption {
+	// sort for determinism, later servers replace earlier ones serving the same language
+	names := make([]lang.ServerName, 0, len(configs))
+	for name := range configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var opts []lsp.LanguageDetectorOption
+	for _, name := range names {
+		config := configs[name]
+		adapter, err := lang.NewStdioAdapter(name, config)
+		if err != nil {
+			log.Warn().Err(err).Msg("Skipping language server")
+			continue
+		}
+
+		if replaced := lang.RegisterAdapter(adapter); len(replaced) > 0 {
+			log.Info().Str("server", name).Msgf("Language server replaces %v", replaced)
+		}
+
+		if len(config.FilePatterns) > 0 {
+			opts = append(opts, lsp.LanguageDetectorWithFilePatterns(config.Languages[0], config.FilePatterns...))
+		}
+	}
+
+	return opts
+}
+
+// devcontainerLanguageServers reads customizations.hide.languageServers in the workspace's devcontainer.json.
+func devcontainerLanguageServers(workspaceDir string) map[string]devcontainer.LanguageServerCustomization {
+	configFile, err := devcontainer.FindConfig(os.DirFS(workspaceDir))
+	if err != nil {
+		log.Debug().Err(err).Msg("No devcontainer.json found, using default language server settings")
+		return nil
+	}
+
+	config, err := devcontainer.ParseConfig(configFile)
+	if err != nil {
+		log.Warn().Err(err).Str("path", configFile.Path).Msg("Failed to parse devcontainer.json, using default language server settings")
+		return nil
+	}
+
+	if config.Customizations.Hide == nil {
+		return nil
+	}
+
+	return config.Customizations.Hide.LanguageServers
+}
+
+// defaultBinaryDir installs language servers into ~/.hide/bin, so that they are shared across workspaces.
+func defaultBinaryDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, HidePath, "bin")
 }
@@ -0,0 +1,49 @@
+package completion
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/hide-org/hide/pkg/lsp/v2"
+)
+
+type CompleteOptions struct {
+	// Prefix is matched case-insensitively against the start of the item's filter text
+	Prefix string
+	// Kinds of items to keep, e.g. Method or Function
+	Kinds []string
+	// Limit caps the number of returned items, 0 means no limit
+	Limit int
+}
+
+type CompleteOption func(opts *CompleteOptions)
+
+func CompleteWithPrefix(prefix string) CompleteOption {
+	return func(opts *CompleteOptions) {
+		opts.Prefix = prefix
+	}
+}
+
+func CompleteWithKinds(kinds ...string) CompleteOption {
+	return func(opts *CompleteOptions) {
+		opts.Kinds = append(opts.Kinds, kinds...)
+	}
+}
+
+func CompleteWithLimit(limit int) CompleteOption {
+	return func(opts *CompleteOptions) {
+		opts.Limit = limit
+	}
+}
+
+func (o *CompleteOptions) keep(item lsp.CompletionItem) bool {
+	if o.Prefix != "" && !strings.HasPrefix(strings.ToLower(item.FilterText), strings.ToLower(o.Prefix)) {
+		return false
+	}
+
+	if len(o.Kinds) > 0 && !slices.ContainsFunc(o.Kinds, func(kind string) bool { return strings.EqualFold(kind, item.Kind) }) {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,96 @@
+package completion
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/lsp/v2"
+	"github.com/hide-org/hide/pkg/model"
+)
+
+type Service interface {
+	// Complete returns completion items at position, ranked by the language server.
+	Complete(ctx context.Context, path string, position lsp.Position, opts ...CompleteOption) ([]lsp.CompletionItem, error)
+	// SignatureHelp returns signatures of the call at position.
+	SignatureHelp(ctx context.Context, path string, position lsp.Position) (lsp.SignatureHelp, error)
+}
+
+type ServiceImpl struct {
+	workspaceDir string
+	files        files.Service
+	lsp          lsp.Service
+}
+
+func NewService(files files.Service, lsp lsp.Service, workspaceDir string) Service {
+	return &ServiceImpl{files: files, lsp: lsp, workspaceDir: workspaceDir}
+}
+
+func (s *ServiceImpl) Complete(ctx context.Context, path string, position lsp.Position, opts ...CompleteOption) ([]lsp.CompletionItem, error) {
+	opt := &CompleteOptions{}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	file, err := s.readFile(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.lsp.GetCompletions(ctx, file, toProtocolPosition(position))
+	if err != nil {
+		log.Error().Err(err).Str("path", path).Msg("failed to get completions")
+		return nil, fmt.Errorf("failed to get completions: %w", err)
+	}
+
+	result := make([]lsp.CompletionItem, 0, len(items))
+	for _, item := range items {
+		if !opt.keep(item) {
+			continue
+		}
+
+		result = append(result, item)
+		if opt.Limit > 0 && len(result) == opt.Limit {
+			break
+		}
+	}
+
+	log.Debug().Str("path", path).Msgf("found %d completions, returning %d", len(items), len(result))
+	return result, nil
+}
+
+func (s *ServiceImpl) SignatureHelp(ctx context.Context, path string, position lsp.Position) (lsp.SignatureHelp, error) {
+	file, err := s.readFile(ctx, path)
+	if err != nil {
+		return lsp.SignatureHelp{}, err
+	}
+
+	help, err := s.lsp.GetSignatureHelp(ctx, file, toProtocolPosition(position))
+	if err != nil {
+		log.Error().Err(err).Str("path", path).Msg("failed to get signature help")
+		return lsp.SignatureHelp{}, fmt.Errorf("failed to get signature help: %w", err)
+	}
+
+	return help, nil
+}
+
+// readFile reads the file content, language servers need it to open the document.
+func (s *ServiceImpl) readFile(ctx context.Context, path string) (model.File, error) {
+	file, err := s.files.ReadFile(ctx, path)
+	if err != nil {
+		return model.File{}, err
+	}
+
+	return *file.WithPath("file://" + filepath.Join(s.workspaceDir, path)), nil
+}
+
+func toProtocolPosition(position lsp.Position) protocol.Position {
+	return protocol.Position{
+		Line:      protocol.UInteger(position.Line - 1), // Hide uses 1-based lines
+		Character: protocol.UInteger(position.Character),
+	}
+}
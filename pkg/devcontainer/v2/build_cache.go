@@ -0,0 +1,224 @@
+package devcontainer
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/moby/patternmatcher"
+	"github.com/rs/zerolog/log"
+)
+
+// ImageBuildHashLabel is set on images built by hide to the hash of their build inputs. The images are tagged with the
+// hash too, so that unchanged devcontainers reuse their image, and stale images are removed by PruneImages.
+const ImageBuildHashLabel = "dev.hide.build-hash"
+
+// buildHash returns the first 12 hex digits of the SHA-256 of the build inputs hashed by write, for use as image tag.
+func buildHash(write func(h hash.Hash) error) (string, error) {
+	h := sha256.New()
+	if err := write(h); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12], nil
+}
+
+// writeHashPart hashes the parts, each of them terminated by a NUL byte so that they can not run into each other.
+func writeHashPart(h hash.Hash, parts ...string) {
+	for _, part := range parts {
+		io.WriteString(h, part)
+		h.Write([]byte{0})
+	}
+}
+
+// writeBuildOptionsHash hashes the options of a build that change the resulting image.
+func writeBuildOptionsHash(h hash.Hash, options types.ImageBuildOptions) {
+	writeHashPart(h, "dockerfile", options.Dockerfile, "target", options.Target)
+	writeHashPart(h, "cacheFrom")
+	writeHashPart(h, options.CacheFrom...)
+
+	names := make([]string, 0, len(options.BuildArgs))
+	for name := range options.BuildArgs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	writeHashPart(h, "args")
+	for _, name := range names {
+		if value := options.BuildArgs[name]; value != nil {
+			writeHashPart(h, name+"="+*value)
+		} else {
+			writeHashPart(h, name)
+		}
+	}
+}
+
+// writeBuildContextHash hashes the paths, modes and contents of the files of the build context, except the ones
+// excluded by .dockerignore.
+func writeBuildContextHash(h hash.Hash, contextPath string, excludes []string) error {
+	matcher, err := patternmatcher.New(excludes)
+	if err != nil {
+		return fmt.Errorf("Invalid .dockerignore: %w", err)
+	}
+
+	return filepath.WalkDir(contextPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(contextPath, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		excluded, err := matcher.MatchesOrParentMatches(rel)
+		if err != nil {
+			return err
+		}
+		if excluded {
+			// files in excluded directories can only be included again by exclusions, e.g. !dir/file
+			if d.IsDir() && !matcher.Exclusions() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		writeHashPart(h, rel, info.Mode().String())
+
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			writeHashPart(h, target)
+		case info.Mode().IsRegular():
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			if _, err := io.Copy(h, f); err != nil {
+				return err
+			}
+			h.Write([]byte{0})
+		}
+
+		return nil
+	})
+}
+
+// readDockerignore returns the patterns of the .dockerignore file of the build context. Like with the Docker CLI, the
+// Dockerfile and .dockerignore are always sent to the daemon.
+func readDockerignore(contextPath, dockerfile string) ([]string, error) {
+	f, err := os.Open(filepath.Join(contextPath, ".dockerignore"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		exclusion := strings.HasPrefix(line, "!")
+		pattern := filepath.Clean(strings.TrimSpace(strings.TrimPrefix(line, "!")))
+		pattern = strings.TrimPrefix(filepath.ToSlash(pattern), "/")
+		if exclusion {
+			pattern = "!" + pattern
+		}
+		patterns = append(patterns, pattern)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(patterns) > 0 {
+		patterns = append(patterns, "!"+filepath.ToSlash(dockerfile), "!.dockerignore")
+	}
+
+	return patterns, nil
+}
+
+// jsonMessage is a message in the response of the Docker API when building or pulling images.
+type jsonMessage struct {
+	Stream      string `json:"stream,omitempty"`
+	Status      string `json:"status,omitempty"`
+	Progress    string `json:"progress,omitempty"`
+	ID          string `json:"id,omitempty"`
+	Error       string `json:"error,omitempty"`
+	ErrorDetail *struct {
+		Message string `json:"message"`
+	} `json:"errorDetail,omitempty"`
+}
+
+// streamResponse writes the build logs and progress of a build or pull response to the output of the image manager
+// and the log. It returns the error reported by Docker, if any.
+func (im *DockerImageManager) streamResponse(src io.Reader) error {
+	decoder := json.NewDecoder(src)
+	for {
+		var msg jsonMessage
+		if err := decoder.Decode(&msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("Failed to read Docker response: %w", err)
+		}
+
+		if msg.ErrorDetail != nil && msg.ErrorDetail.Message != "" {
+			return errors.New(msg.ErrorDetail.Message)
+		}
+		if msg.Error != "" {
+			return errors.New(msg.Error)
+		}
+
+		var line string
+		switch {
+		case msg.Stream != "":
+			line = msg.Stream
+			if text := strings.TrimSpace(msg.Stream); text != "" {
+				log.Info().Msg(text)
+			}
+		case msg.Status != "":
+			line = msg.Status
+			if msg.ID != "" {
+				line = msg.ID + ": " + line
+			}
+			if msg.Progress != "" {
+				line += " " + msg.Progress
+			}
+			line += "\n"
+			log.Debug().Str("id", msg.ID).Str("progress", msg.Progress).Msg(msg.Status)
+		default:
+			continue
+		}
+
+		if im.output != nil {
+			io.WriteString(im.output, line)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package devcontainer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+var composeProjectNameInvalidChars = regexp.MustCompile(`[^a-z0-9_-]`)
+
+// composeProject is a Docker Compose project started for a devcontainer.
+type composeProject struct {
+	name string
+	// directory of devcontainer.json, compose files are relative to it
+	dir   string
+	files []string
+	// override publishes the hide server of the service, it is the last of files
+	override string
+}
+
+func newComposeProject(projectPath string, config Config) composeProject {
+	dir := filepath.Join(projectPath, config.Path)
+
+	files := make([]string, 0, len(config.DockerComposeFile))
+	for _, file := range config.DockerComposeFile {
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		files = append(files, file)
+	}
+
+	name := composeProjectName(projectPath, config)
+	override := filepath.Join(os.TempDir(), "hide", "compose", name+".yml")
+
+	return composeProject{name: name, dir: dir, files: append(files, override), override: override}
+}
+
+// writeOverride writes a Compose file that publishes ServerPort of the service on a free host port, like CreateContainer
+// does for single containers.
+func (p composeProject) writeOverride(service string) error {
+	content, err := yaml.Marshal(map[string]any{
+		"services": map[string]any{
+			service: map[string]any{
+				"ports": []string{fmt.Sprintf("127.0.0.1::%d", ServerPort)},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p.override), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(p.override, content, 0o644)
+}
+
+// composeProjectName follows the devcontainer CLI, e.g. "<project folder>_devcontainer", as Compose requires lowercase
+// names of letters, digits, dashes and underscores.
+func composeProjectName(projectPath string, config Config) string {
+	name := config.Name
+	if name == "" {
+		name = filepath.Base(projectPath) + "_devcontainer"
+	}
+
+	name = strings.ToLower(strings.ReplaceAll(name, " ", "-"))
+	return composeProjectNameInvalidChars.ReplaceAllString(name, "")
+}
+
+// command returns the docker compose command with the project name and files.
+func (p composeProject) command(args ...string) []string {
+	command := []string{"docker", "compose", "--project-name", p.name}
+	for _, file := range p.files {
+		command = append(command, "--file", file)
+	}
+	return append(command, args...)
+}
+
+// composeUp starts the service and the services in runServices, or all services if runServices is not set, and returns
+// the ID of the service's container. The hide server port of the service is published and the server binary is copied
+// into its container.
+func (r *DockerRunner) composeUp(ctx context.Context, projectPath string, config Config) (string, error) {
+	project := newComposeProject(projectPath, config)
+
+	if err := project.writeOverride(config.Service); err != nil {
+		return "", fmt.Errorf("Failed to write Docker Compose override of service %s: %w", config.Service, err)
+	}
+
+	args := []string{"up", "--detach", "--build"}
+	if len(config.RunServices) > 0 {
+		args = append(args, config.Service)
+		for _, service := range config.RunServices {
+			if service != config.Service {
+				args = append(args, service)
+			}
+		}
+	}
+
+	log.Debug().Str("project", project.name).Strs("files", project.files).Msg("Starting Docker Compose project")
+	if err := r.commandExecutor.Run(project.command(args...), project.dir, os.Stdout, os.Stderr); err != nil {
+		return "", fmt.Errorf("Failed to start Docker Compose project %s: %w", project.name, err)
+	}
+
+	var stdout bytes.Buffer
+	if err := r.commandExecutor.Run(project.command("ps", "--quiet", config.Service), project.dir, &stdout, os.Stderr); err != nil {
+		return "", fmt.Errorf("Failed to find container of service %s: %w", config.Service, err)
+	}
+
+	containerId := strings.TrimSpace(stdout.String())
+	if containerId == "" || strings.Contains(containerId, "\n") {
+		return "", fmt.Errorf("Failed to find container of service %s: got %q", config.Service, containerId)
+	}
+
+	r.mu.Lock()
+	r.composeProjects[containerId] = project
+	r.mu.Unlock()
+
+	if err := r.containerManager.CopyServerBinary(ctx, containerId); err != nil {
+		if _, downErr := r.composeDown(containerId); downErr != nil {
+			log.Error().Err(downErr).Str("project", project.name).Msg("Failed to stop Docker Compose project")
+		}
+		return "", err
+	}
+
+	return containerId, nil
+}
+
+// composeDown removes the containers and networks of the Docker Compose project the container belongs to. Volumes are
+// kept, e.g. databases survive a restart.
+func (r *DockerRunner) composeDown(containerId string) (bool, error) {
+	r.mu.Lock()
+	project, ok := r.composeProjects[containerId]
+	r.mu.Unlock()
+
+	if !ok {
+		return false, nil
+	}
+
+	if err := r.commandExecutor.Run(project.command("down"), project.dir, os.Stdout, os.Stderr); err != nil {
+		return true, fmt.Errorf("Failed to stop Docker Compose project %s: %w", project.name, err)
+	}
+
+	r.mu.Lock()
+	delete(r.composeProjects, containerId)
+	r.mu.Unlock()
+
+	if err := os.Remove(project.override); err != nil && !os.IsNotExist(err) {
+		log.Warn().Err(err).Str("path", project.override).Msg("Failed to remove Docker Compose override")
+	}
+
+	return true, nil
+}
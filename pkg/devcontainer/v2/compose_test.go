@@ -0,0 +1,217 @@
+package devcontainer_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
+	"github.com/hide-org/hide/pkg/devcontainer/v2/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// composeOverride is the path of the Compose file that publishes the hide server of the project.
+func composeOverride(project string) string {
+	return filepath.Join(os.TempDir(), "hide", "compose", project+".yml")
+}
+
+func composeCommand(args ...string) []string {
+	return append([]string{"docker", "compose", "--project-name", "my-app", "--file", "/test/project/.devcontainer/docker-compose.yml", "--file", "/test/project/docker-compose.dev.yml", "--file", composeOverride("my-app")}, args...)
+}
+
+func writeStdout(output string) func(mock.Arguments) {
+	return func(args mock.Arguments) {
+		args.Get(2).(io.Writer).Write([]byte(output))
+	}
+}
+
+func TestDockerRunner_RunCompose(t *testing.T) {
+	composeConfig := func(runServices ...string) devcontainer.Config {
+		return devcontainer.Config{
+			Path: ".devcontainer",
+			DockerComposeProps: devcontainer.DockerComposeProps{
+				DockerComposeFile: []string{"docker-compose.yml", "../docker-compose.dev.yml"},
+				Service:           "app",
+				RunServices:       runServices,
+			},
+			LifecycleProps: devcontainer.LifecycleProps{
+				OnCreateCommand: devcontainer.LifecycleCommand{"": []string{"make", "setup"}},
+			},
+			GeneralProperties: devcontainer.GeneralProperties{Name: "My App"},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		config     devcontainer.Config
+		setupMocks func(*mocks.MockExecutor, *mocks.MockContainerManager)
+		wantResult string
+		wantError  string
+	}{
+		{
+			name:   "Starts all services",
+			config: composeConfig(),
+			setupMocks: func(me *mocks.MockExecutor, mcm *mocks.MockContainerManager) {
+				me.On("Run", composeCommand("up", "--detach", "--build"), "/test/project/.devcontainer", mock.Anything, mock.Anything).Return(nil)
+				me.On("Run", composeCommand("ps", "--quiet", "app"), "/test/project/.devcontainer", mock.Anything, mock.Anything).Run(writeStdout("container-id\n")).Return(nil)
+				mcm.On("CopyServerBinary", mock.Anything, "container-id").Return(nil)
+				mcm.On("ExecStream", mock.Anything, "container-id", []string{"make", "setup"}, mock.Anything, mock.Anything).Return(0, nil)
+			},
+			wantResult: "container-id",
+		},
+		{
+			name:   "Starts only run services",
+			config: composeConfig("db", "app", "cache"),
+			setupMocks: func(me *mocks.MockExecutor, mcm *mocks.MockContainerManager) {
+				me.On("Run", composeCommand("up", "--detach", "--build", "app", "db", "cache"), "/test/project/.devcontainer", mock.Anything, mock.Anything).Return(nil)
+				me.On("Run", composeCommand("ps", "--quiet", "app"), "/test/project/.devcontainer", mock.Anything, mock.Anything).Run(writeStdout("container-id\n")).Return(nil)
+				mcm.On("CopyServerBinary", mock.Anything, "container-id").Return(nil)
+				mcm.On("ExecStream", mock.Anything, "container-id", []string{"make", "setup"}, mock.Anything, mock.Anything).Return(0, nil)
+			},
+			wantResult: "container-id",
+		},
+		{
+			name:   "Stops the project if a lifecycle command fails",
+			config: composeConfig(),
+			setupMocks: func(me *mocks.MockExecutor, mcm *mocks.MockContainerManager) {
+				me.On("Run", composeCommand("up", "--detach", "--build"), "/test/project/.devcontainer", mock.Anything, mock.Anything).Return(nil)
+				me.On("Run", composeCommand("ps", "--quiet", "app"), "/test/project/.devcontainer", mock.Anything, mock.Anything).Run(writeStdout("container-id\n")).Return(nil)
+				mcm.On("CopyServerBinary", mock.Anything, "container-id").Return(nil)
+				mcm.On("ExecStream", mock.Anything, "container-id", []string{"make", "setup"}, mock.Anything, mock.Anything).Return(1, nil)
+				me.On("Run", composeCommand("down"), "/test/project/.devcontainer", mock.Anything, mock.Anything).Return(nil)
+			},
+			wantError: "onCreateCommand [make setup] failed with exit code 1",
+		},
+		{
+			name:   "Stops the project if the server binary cannot be copied",
+			config: composeConfig(),
+			setupMocks: func(me *mocks.MockExecutor, mcm *mocks.MockContainerManager) {
+				me.On("Run", composeCommand("up", "--detach", "--build"), "/test/project/.devcontainer", mock.Anything, mock.Anything).Return(nil)
+				me.On("Run", composeCommand("ps", "--quiet", "app"), "/test/project/.devcontainer", mock.Anything, mock.Anything).Run(writeStdout("container-id\n")).Return(nil)
+				mcm.On("CopyServerBinary", mock.Anything, "container-id").Return(errors.New("no such file"))
+				me.On("Run", composeCommand("down"), "/test/project/.devcontainer", mock.Anything, mock.Anything).Return(nil)
+			},
+			wantError: "no such file",
+		},
+		{
+			name:   "Failed compose up",
+			config: composeConfig(),
+			setupMocks: func(me *mocks.MockExecutor, mcm *mocks.MockContainerManager) {
+				me.On("Run", composeCommand("up", "--detach", "--build"), mock.Anything, mock.Anything, mock.Anything).Return(errors.New("exit status 1"))
+			},
+			wantError: "Failed to start Docker Compose project my-app: exit status 1",
+		},
+		{
+			name:   "Service container not found",
+			config: composeConfig(),
+			setupMocks: func(me *mocks.MockExecutor, mcm *mocks.MockContainerManager) {
+				me.On("Run", composeCommand("up", "--detach", "--build"), mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				me.On("Run", composeCommand("ps", "--quiet", "app"), mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			},
+			wantError: "Failed to find container of service app",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TMPDIR", t.TempDir())
+			mockExecutor := &mocks.MockExecutor{}
+			mockImageManager := &mocks.MockImageManager{}
+			mockContainerManager := &mocks.MockContainerManager{}
+			probedUserEnv(mockContainerManager, "").Maybe()
+			tt.setupMocks(mockExecutor, mockContainerManager)
+
+			runner := devcontainer.NewDockerRunner(mockExecutor, mockImageManager, mockContainerManager)
+			result, err := runner.Run(context.Background(), "/test/project", tt.config)
+
+			if tt.wantError != "" {
+				assert.ErrorContains(t, err, tt.wantError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantResult, result)
+			}
+
+			mockExecutor.AssertExpectations(t)
+			mockImageManager.AssertExpectations(t)
+			mockContainerManager.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDockerRunner_RunCompose_PublishesServerPort(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	mockExecutor := &mocks.MockExecutor{}
+	mockContainerManager := &mocks.MockContainerManager{}
+	probedUserEnv(mockContainerManager, "").Maybe()
+	runner := devcontainer.NewDockerRunner(mockExecutor, &mocks.MockImageManager{}, mockContainerManager)
+
+	config := devcontainer.Config{
+		Path:               ".devcontainer",
+		DockerComposeProps: devcontainer.DockerComposeProps{DockerComposeFile: []string{"docker-compose.yml"}, Service: "app"},
+		GeneralProperties:  devcontainer.GeneralProperties{Name: "My App"},
+	}
+	command := func(args ...string) []string {
+		return append([]string{"docker", "compose", "--project-name", "my-app", "--file", "/test/project/.devcontainer/docker-compose.yml", "--file", composeOverride("my-app")}, args...)
+	}
+
+	var override string
+	mockExecutor.On("Run", command("up", "--detach", "--build"), mock.Anything, mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) {
+			content, err := os.ReadFile(composeOverride("my-app"))
+			require.NoError(t, err)
+			override = string(content)
+		}).
+		Return(nil)
+	mockExecutor.On("Run", command("ps", "--quiet", "app"), mock.Anything, mock.Anything, mock.Anything).Run(writeStdout("container-id")).Return(nil)
+	mockContainerManager.On("CopyServerBinary", mock.Anything, "container-id").Return(nil)
+	mockExecutor.On("Run", command("down"), mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	containerId, err := runner.Run(context.Background(), "/test/project", config)
+	require.NoError(t, err)
+	assert.YAMLEq(t, "services: {app: {ports: ['127.0.0.1::8081']}}", override)
+
+	// the override is removed with the project
+	require.NoError(t, runner.Stop(context.Background(), containerId))
+	assert.NoFileExists(t, composeOverride("my-app"))
+
+	mockExecutor.AssertExpectations(t)
+	mockContainerManager.AssertExpectations(t)
+}
+
+func TestDockerRunner_StopCompose(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	mockExecutor := &mocks.MockExecutor{}
+	mockContainerManager := &mocks.MockContainerManager{}
+	probedUserEnv(mockContainerManager, "").Maybe()
+	runner := devcontainer.NewDockerRunner(mockExecutor, &mocks.MockImageManager{}, mockContainerManager)
+
+	config := devcontainer.Config{
+		DockerComposeProps: devcontainer.DockerComposeProps{DockerComposeFile: []string{"/abs/compose.yml"}, Service: "app"},
+	}
+	command := func(args ...string) []string {
+		return append([]string{"docker", "compose", "--project-name", "project_devcontainer", "--file", "/abs/compose.yml", "--file", composeOverride("project_devcontainer")}, args...)
+	}
+
+	mockExecutor.On("Run", command("up", "--detach", "--build"), mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockExecutor.On("Run", command("ps", "--quiet", "app"), mock.Anything, mock.Anything, mock.Anything).Run(writeStdout("container-id")).Return(nil)
+	mockContainerManager.On("CopyServerBinary", mock.Anything, "container-id").Return(nil)
+	mockExecutor.On("Run", command("down"), "/test/project", mock.Anything, mock.Anything).Return(nil).Once()
+
+	containerId, err := runner.Run(context.Background(), "/test/project", config)
+	assert.NoError(t, err)
+
+	// the whole project is stopped, not only the service container
+	assert.NoError(t, runner.Stop(context.Background(), containerId))
+
+	// other containers are stopped as usual
+	mockContainerManager.On("StopContainer", mock.Anything, "other-id").Return(nil)
+	assert.NoError(t, runner.Stop(context.Background(), "other-id"))
+
+	mockExecutor.AssertExpectations(t)
+	mockContainerManager.AssertExpectations(t)
+}
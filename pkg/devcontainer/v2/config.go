@@ -1,6 +1,7 @@
 package devcontainer
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"maps"
@@ -17,6 +18,9 @@ type Config struct {
 	LifecycleProps
 	HostRequirements
 	GeneralProperties
+
+	// ImageMetadata are the entries of the devcontainer.metadata label of the image merged into the config
+	ImageMetadata []ImageMetadata `json:"-"`
 }
 
 type ImageDevContainer struct {
@@ -68,7 +72,7 @@ type DockerImageProps struct {
 	WorkspaceFolder string `json:"workspaceFolder,omitempty"`
 
 	// An array of Docker CLI arguments that should be used when running the container. Defaults to [].
-	// NOTE: this args are currently ignored because it's too cumbersome to manually parse them into the container.Config or container.HostConfig, there should be a better way to do it
+	// Supported docker run flags are translated into the container config, others fail the creation of the container.
 	RunArgs []string `json:"runArgs,omitempty"`
 }
 
@@ -203,15 +207,12 @@ type LifecycleProps struct {
 	PostCreateCommand LifecycleCommand `json:"postCreateCommand,omitempty"`
 
 	// A command to run each time the container is successfully started.
-	// TODO: should we run it inside of container?
 	PostStartCommand LifecycleCommand `json:"postStartCommand,omitempty"`
 
 	// A command to run each time a tool has successfully attached to the container.
-	// TODO: should we run it inside of container?
 	PostAttachCommand LifecycleCommand `json:"postAttachCommand,omitempty"`
 
 	// An enum that specifies the command any tool should wait for before connecting. Defaults to updateContentCommand.
-	// TODO: use it
 	WaitFor string `json:"waitFor,omitempty"`
 }
 
@@ -243,8 +244,7 @@ type GeneralProperties struct {
 	Name string `json:"name,omitempty"`
 
 	// An array of port numbers or "host:port" values (e.g. [3000, "db:5432"]) that should always be forwarded from inside the primary container to the local machine (including on the web). Defaults to [].
-	// TODO: how to use this?
-	ForwardPorts []string `json:"forwardPorts,omitempty"`
+	ForwardPorts ForwardPorts `json:"forwardPorts,omitempty"`
 
 	// Object that maps a port number, "host:port" value, range, or regular expression to a set of default options.
 	PortsAttributes map[string]PortAttributes `json:"portsAttributes,omitempty"`
@@ -256,22 +256,18 @@ type GeneralProperties struct {
 	ContainerEnv map[string]string `json:"containerEnv,omitempty"`
 
 	// A set of name-value pairs that sets or overrides environment variables for the devcontainer.json supporting service / tool (or sub-processes like terminals) but not the container as a whole.
-	// TODO: how to use it?
 	RemoteEnv map[string]string `json:"remoteEnv,omitempty"`
 
 	// Overrides the user that Hide uses to run processes inside the container. Defaults to the user the container as a whole is running as (often root).
-	// TODO: use when running commands?
 	RemoteUser string `json:"remoteUser,omitempty"`
 
 	// Overrides the user for all operations run as inside the container. Defaults to either root or the last USER instruction in the related Dockerfile used to create the image.
 	ContainerUser string `json:"containerUser,omitempty"`
 
 	// On Linux, if containerUser or remoteUser is specified, the user’s UID/GID will be updated to match the local user’s UID/GID to avoid permission problems with bind mounts. Defaults to true.
-	// TODO: how to use it?
-	UpdateRemoteUserUID bool `json:"updateRemoteUserUID,omitempty"`
+	UpdateRemoteUserUID *bool `json:"updateRemoteUserUID,omitempty"`
 
 	// Indicates the type of shell to use to “probe” for user environment variables: "none", "interactiveShell", "loginShell", or "loginInteractiveShell" (default)
-	// NOTE: most likely we don't need this
 	UserEnvProbe string `json:"userEnvProbe,omitempty"`
 
 	// Tells devcontainer.json supporting services / tools whether they should run /bin/sh -c "while sleep 1000; do :; done" when starting the container instead of the container’s default command (since the container can shut down if the default command fails). Set to false if the default command must run for the container to function properly. Defaults to true for when using an image or Dockerfile and false when referencing a Docker Compose file.
@@ -298,8 +294,7 @@ type GeneralProperties struct {
 	Mounts []Mount `json:"mounts,omitempty"`
 
 	// An object of Dev Container Feature IDs and related options to be added into your primary container.
-	// NOTE: this is not supported yet
-	// Features                    map[string]map[string]string `json:"features,omitempty"`
+	Features map[string]FeatureOptions `json:"features,omitempty"`
 
 	// By default, Features will attempt to automatically set the order they are installed based on a installsAfter property within each of them. This property allows you to override the Feature install order when needed.
 	OverrideFeatureInstallOrder []string `json:"overrideFeatureInstallOrder,omitempty"`
@@ -317,7 +312,7 @@ func (g *GeneralProperties) Equals(other *GeneralProperties) bool {
 		maps.Equal(g.RemoteEnv, other.RemoteEnv) &&
 		g.RemoteUser == other.RemoteUser &&
 		g.ContainerUser == other.ContainerUser &&
-		g.UpdateRemoteUserUID == other.UpdateRemoteUserUID &&
+		boolPointerEqual(g.UpdateRemoteUserUID, other.UpdateRemoteUserUID) &&
 		g.UserEnvProbe == other.UserEnvProbe &&
 		g.OverrideCommand == other.OverrideCommand &&
 		g.ShutdownAction == other.ShutdownAction &&
@@ -326,7 +321,7 @@ func (g *GeneralProperties) Equals(other *GeneralProperties) bool {
 		slices.Equal(g.CapAdd, other.CapAdd) &&
 		slices.Equal(g.SecurityOpt, other.SecurityOpt) &&
 		slices.Equal(g.Mounts, other.Mounts) &&
-		// maps.Equal(g.Features, other.Features) &&
+		maps.EqualFunc(g.Features, other.Features, func(a, b FeatureOptions) bool { return reflect.DeepEqual(a, b) }) &&
 		slices.Equal(g.OverrideFeatureInstallOrder, other.OverrideFeatureInstallOrder) &&
 		g.Customizations.Equals(other.Customizations)
 }
@@ -444,11 +439,66 @@ func (p *PortAttributes) Equals(other *PortAttributes) bool {
 		p.ElevateIfNeeded == other.ElevateIfNeeded
 }
 
-// TODO: implement type
-type Customizations struct{}
+type Customizations struct {
+	Hide *HideCustomization `json:"hide,omitempty"`
+}
 
 func (c Customizations) Equals(other Customizations) bool {
-	return c == other
+	return c.Hide.Equals(other.Hide)
+}
+
+type HideCustomization struct {
+	Tasks []Task `json:"tasks,omitempty"`
+	// LanguageServers overrides language server settings, keyed by server name, e.g. "gopls"
+	LanguageServers map[string]LanguageServerCustomization `json:"languageServers,omitempty"`
+}
+
+func (h *HideCustomization) Equals(other *HideCustomization) bool {
+	if h == nil && other == nil {
+		return true
+	}
+
+	if h == nil || other == nil {
+		return false
+	}
+
+	return slices.Equal(h.Tasks, other.Tasks) &&
+		maps.EqualFunc(h.LanguageServers, other.LanguageServers, func(a, b LanguageServerCustomization) bool {
+			return a.Equals(&b)
+		})
+}
+
+type Task struct {
+	Alias   string `json:"alias"`
+	Command string `json:"command"`
+}
+
+type LanguageServerCustomization struct {
+	// Command declares a language server that is not built into hide, started with the command and talking LSP over stdio.
+	// Without a command the customization applies to a built-in server
+	Command string `json:"command,omitempty"`
+	// Args are the command line arguments of the command
+	Args []string `json:"args,omitempty"`
+	// Env are additional environment variables of the command
+	Env map[string]string `json:"env,omitempty"`
+	// Languages served by the command, e.g. ["Nix"]
+	Languages []string `json:"languages,omitempty"`
+	// FilePatterns assign files to the language of the command, e.g. ["*.nix"]
+	FilePatterns []string `json:"filePatterns,omitempty"`
+	// InitializationOptions are merged into the options sent with initialize
+	InitializationOptions json.RawMessage `json:"initializationOptions,omitempty"`
+	// Settings are merged into the workspace configuration returned for workspace/configuration
+	Settings json.RawMessage `json:"settings,omitempty"`
+}
+
+func (l *LanguageServerCustomization) Equals(other *LanguageServerCustomization) bool {
+	return l.Command == other.Command &&
+		slices.Equal(l.Args, other.Args) &&
+		maps.Equal(l.Env, other.Env) &&
+		slices.Equal(l.Languages, other.Languages) &&
+		slices.Equal(l.FilePatterns, other.FilePatterns) &&
+		bytes.Equal(l.InitializationOptions, other.InitializationOptions) &&
+		bytes.Equal(l.Settings, other.Settings)
 }
 
 func stringPointerEqual(x, y *string) bool {
@@ -460,3 +510,13 @@ func stringPointerEqual(x, y *string) bool {
 	}
 	return *x == *y
 }
+
+func boolPointerEqual(x, y *bool) bool {
+	if x == nil && y == nil {
+		return true
+	}
+	if x == nil || y == nil {
+		return false
+	}
+	return *x == *y
+}
@@ -2,7 +2,7 @@ package devcontainer_test
 
 import (
 	"encoding/json"
-	"github.com/hide-org/hide/pkg/devcontainer"
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
 	"testing"
 )
 
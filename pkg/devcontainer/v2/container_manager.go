@@ -1,12 +1,16 @@
 package devcontainer
 
 import (
+	"archive/tar"
 	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"os"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
@@ -14,11 +18,17 @@ import (
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/go-connections/nat"
+	"github.com/rs/zerolog/log"
 )
 
 const (
 	DefaultShell      = "/bin/sh"
 	DefaultWorkingDir = "/workspace"
+
+	// ServerPort is the port of the hide server in the container, published on a host port allocated by Docker.
+	ServerPort = 8081
+	// ServerBinaryPath is where the hide server binary is copied to in the container.
+	ServerBinaryPath = "/opt/hide/bin/hide"
 )
 
 var DefaultContainerCommand = []string{DefaultShell, "-c", "while sleep 1000; do :; done"}
@@ -27,25 +37,87 @@ type ContainerManager interface {
 	CreateContainer(ctx context.Context, image string, projectPath string, config Config) (string, error)
 	StartContainer(ctx context.Context, containerId string) error
 	StopContainer(ctx context.Context, containerId string) error
-	Exec(ctx context.Context, containerId string, command []string) (ExecResult, error)
-	ExecDetached(ctx context.Context, containerId string, command []string) (string, error)
+	Exec(ctx context.Context, containerId string, command []string, opts ...ExecOption) (ExecResult, error)
+	ExecDetached(ctx context.Context, containerId string, command []string, opts ...ExecOption) (string, error)
+	// ExecStream runs the command and writes its output to stdout and stderr as it is produced. It returns the exit code.
+	ExecStream(ctx context.Context, containerId string, command []string, stdout, stderr io.Writer, opts ...ExecOption) (int, error)
+	// HostPort returns the host port the TCP port of the container is published on.
+	HostPort(ctx context.Context, containerId string, port int) (int, error)
+	// PortMappings returns the published ports of the container, labeled with the portsAttributes of the config.
+	PortMappings(ctx context.Context, containerId string, config Config) ([]PortMapping, error)
+	// ContainerEnv returns the environment of the container, KEY=value pairs.
+	ContainerEnv(ctx context.Context, containerId string) ([]string, error)
+	// CopyServerBinary copies the hide server binary into the container at ServerBinaryPath, if the container manager
+	// has one. Containers created with CreateContainer already have it.
+	CopyServerBinary(ctx context.Context, containerId string) error
+}
+
+// ExecOptions are the options of a command run in the container.
+type ExecOptions struct {
+	// User runs the command as this user instead of the user of the container.
+	User string
+	// Env are KEY=value pairs added to the environment of the command.
+	Env []string
+}
+
+type ExecOption func(o *ExecOptions)
+
+func ExecWithUser(user string) ExecOption {
+	return func(o *ExecOptions) {
+		o.User = user
+	}
+}
+
+func ExecWithEnv(env map[string]string) ExecOption {
+	return func(o *ExecOptions) {
+		keys := make([]string, 0, len(env))
+		for key := range env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			o.Env = append(o.Env, key+"="+env[key])
+		}
+	}
+}
+
+func NewExecOptions(opts ...ExecOption) ExecOptions {
+	var options ExecOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
 }
 
 type DockerContainerManager struct {
 	client.ContainerAPIClient
+	serverBinary  string
+	portAvailable func(hostIP string, port int) bool
 }
 
-func NewDockerContainerManager(dockerContainerCli client.ContainerAPIClient) ContainerManager {
-	return &DockerContainerManager{ContainerAPIClient: dockerContainerCli}
+type ContainerManagerOption func(cm *DockerContainerManager)
+
+// ContainerManagerWithServerBinary copies the hide server binary at path on the host into created containers, at
+// ServerBinaryPath.
+func ContainerManagerWithServerBinary(path string) ContainerManagerOption {
+	return func(cm *DockerContainerManager) {
+		cm.serverBinary = path
+	}
+}
+
+func NewDockerContainerManager(dockerContainerCli client.ContainerAPIClient, opts ...ContainerManagerOption) ContainerManager {
+	cm := &DockerContainerManager{ContainerAPIClient: dockerContainerCli, portAvailable: isPortAvailable}
+	for _, opt := range opts {
+		opt(cm)
+	}
+	return cm
 }
 
 func (cm *DockerContainerManager) CreateContainer(ctx context.Context, image string, projectPath string, config Config) (string, error) {
 	containerConfig := &container.Config{
 		Image: image,
 		Cmd:   DefaultContainerCommand,
-		ExposedPorts: nat.PortSet{
-			"8081/tcp": {},
-		},
 	}
 
 	if len(config.ContainerEnv) > 0 {
@@ -70,23 +142,17 @@ func (cm *DockerContainerManager) CreateContainer(ctx context.Context, image str
 		SecurityOpt:     config.SecurityOpt,
 	}
 
-	portBindings := make(nat.PortMap)
-
-	// mounting port 8081 for hide server
-	// TODO: remove hardcoded port
-	port, _ := nat.NewPort("tcp", "8081")
-	portBindings[port] = []nat.PortBinding{{HostIP: "", HostPort: "8081"}}
-
-	for _, port := range config.AppPort {
-		port_str := strconv.Itoa(port)
-		port, err := nat.NewPort("tcp", port_str)
-		if err != nil {
-			return "", fmt.Errorf("Failed to create new TCP port from port %s: %w", port_str, err)
-		}
-
-		portBindings[port] = []nat.PortBinding{{HostIP: "127.0.0.1", HostPort: port_str}}
+	exposedPorts, portBindings, err := cm.publishedPorts(config)
+	if err != nil {
+		return "", err
 	}
 
+	// publish the hide server on a free host port, so that containers do not collide
+	serverPort := nat.Port(fmt.Sprintf("%d/tcp", ServerPort))
+	exposedPorts[serverPort] = struct{}{}
+	portBindings[serverPort] = []nat.PortBinding{{HostIP: "127.0.0.1", HostPort: ""}}
+	containerConfig.ExposedPorts = exposedPorts
+
 	hostConfig.PortBindings = portBindings
 
 	mounts := []mount.Mount{}
@@ -106,13 +172,6 @@ func (cm *DockerContainerManager) CreateContainer(ctx context.Context, image str
 		Target: workspaceTarget,
 	})
 
-	// mounting GOBIN for hide server
-	mounts = append(mounts, mount.Mount{
-		Type:   mount.TypeBind,
-		Source: "/Users/artemm/go/bin",
-		Target: "/go/bin",
-	})
-
 	if len(config.Mounts) > 0 {
 		for _, m := range config.Mounts {
 			mountType, err := stringToType(m.Type)
@@ -129,14 +188,93 @@ func (cm *DockerContainerManager) CreateContainer(ctx context.Context, image str
 	}
 
 	hostConfig.Mounts = mounts
+
+	if err := applyHostRequirements(config.HostRequirements, hostConfig); err != nil {
+		return "", err
+	}
+
+	// runArgs come last so that they override the properties of devcontainer.json, like with the Docker CLI
+	if err := applyRunArgs(config.RunArgs, containerConfig, hostConfig); err != nil {
+		return "", err
+	}
+
 	createResponse, err := cm.ContainerCreate(ctx, containerConfig, hostConfig, nil, nil, "")
 	if err != nil {
 		return "", err
 	}
 
+	if err := cm.CopyServerBinary(ctx, createResponse.ID); err != nil {
+		// a container without the hide server is of no use, so it is not left behind
+		if removeErr := cm.ContainerRemove(context.WithoutCancel(ctx), createResponse.ID, container.RemoveOptions{Force: true}); removeErr != nil {
+			log.Error().Err(removeErr).Str("containerId", createResponse.ID).Msg("Failed to remove container")
+		}
+		return "", err
+	}
+
 	return createResponse.ID, nil
 }
 
+// CopyServerBinary copies the hide server binary into the container with the Docker API, which works with remote
+// Docker hosts too, unlike a bind mount.
+func (cm *DockerContainerManager) CopyServerBinary(ctx context.Context, containerId string) error {
+	if cm.serverBinary == "" {
+		return nil
+	}
+
+	content, err := os.ReadFile(cm.serverBinary)
+	if err != nil {
+		return fmt.Errorf("Failed to read hide server binary %s: %w", cm.serverBinary, err)
+	}
+
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	if err := tw.WriteHeader(&tar.Header{Name: strings.TrimPrefix(ServerBinaryPath, "/"), Mode: 0o755, Size: int64(len(content))}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(content); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	if err := cm.CopyToContainer(ctx, containerId, "/", &archive, types.CopyToContainerOptions{}); err != nil {
+		return fmt.Errorf("Failed to copy hide server binary into container %s: %w", containerId, err)
+	}
+
+	return nil
+}
+
+func (cm *DockerContainerManager) HostPort(ctx context.Context, containerId string, port int) (int, error) {
+	inspect, err := cm.ContainerInspect(ctx, containerId)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to inspect container %s: %w", containerId, err)
+	}
+
+	if inspect.NetworkSettings != nil {
+		for _, binding := range inspect.NetworkSettings.Ports[nat.Port(fmt.Sprintf("%d/tcp", port))] {
+			if hostPort, err := strconv.Atoi(binding.HostPort); err == nil {
+				return hostPort, nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("Port %d of container %s is not published", port, containerId)
+}
+
+func (cm *DockerContainerManager) ContainerEnv(ctx context.Context, containerId string) ([]string, error) {
+	inspect, err := cm.ContainerInspect(ctx, containerId)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to inspect container %s: %w", containerId, err)
+	}
+
+	if inspect.Config == nil {
+		return nil, nil
+	}
+
+	return inspect.Config.Env, nil
+}
+
 func (cm *DockerContainerManager) StartContainer(ctx context.Context, containerId string) error {
 	return cm.ContainerStart(ctx, containerId, container.StartOptions{})
 }
@@ -145,8 +283,23 @@ func (cm *DockerContainerManager) StopContainer(ctx context.Context, containerId
 	return cm.ContainerStop(ctx, containerId, container.StopOptions{})
 }
 
-func (cm *DockerContainerManager) Exec(ctx context.Context, containerId string, command []string) (ExecResult, error) {
+func (cm *DockerContainerManager) Exec(ctx context.Context, containerId string, command []string, opts ...ExecOption) (ExecResult, error) {
+	var stdOut, stdErr bytes.Buffer
+	logPipe := &logPipe{}
+
+	exitCode, err := cm.ExecStream(ctx, containerId, command, io.MultiWriter(&stdOut, logPipe), io.MultiWriter(&stdErr, logPipe), opts...)
+	if err != nil {
+		return ExecResult{}, err
+	}
+
+	return ExecResult{StdOut: stdOut.String(), StdErr: stdErr.String(), ExitCode: exitCode}, nil
+}
+
+func (cm *DockerContainerManager) ExecStream(ctx context.Context, containerId string, command []string, stdout, stderr io.Writer, opts ...ExecOption) (int, error) {
+	options := NewExecOptions(opts...)
 	execConfig := types.ExecConfig{
+		User:         options.User,
+		Env:          options.Env,
 		Cmd:          command,
 		AttachStdout: true,
 		AttachStderr: true,
@@ -154,43 +307,43 @@ func (cm *DockerContainerManager) Exec(ctx context.Context, containerId string,
 
 	execIDResp, err := cm.ContainerExecCreate(ctx, containerId, execConfig)
 	if err != nil {
-		return ExecResult{}, fmt.Errorf("Failed to create exec configuration for command %s in container %s: %w", command, containerId, err)
+		return 0, fmt.Errorf("Failed to create exec configuration for command %s in container %s: %w", command, containerId, err)
 	}
 
 	execID := execIDResp.ID
 	resp, err := cm.ContainerExecAttach(ctx, execID, types.ExecStartCheck{})
 	if err != nil {
-		return ExecResult{}, fmt.Errorf("Failed to attach to exec process %s in container %s: %w", execID, containerId, err)
+		return 0, fmt.Errorf("Failed to attach to exec process %s in container %s: %w", execID, containerId, err)
 	}
 	defer resp.Close()
 
-	var stdOut, stdErr bytes.Buffer
-	logPipe := &logPipe{}
-
-	if err := readOutputFromContainer(ctx, resp, io.MultiWriter(&stdOut, logPipe), io.MultiWriter(&stdErr, logPipe)); err != nil {
+	if err := readOutputFromContainer(ctx, resp, stdout, stderr); err != nil {
 		if errors.Is(err, context.Canceled) {
-			return ExecResult{}, err
+			return 0, err
 		}
 		if errors.Is(err, context.DeadlineExceeded) {
-			// return exec result with correct exit code instead of err
-			return ExecResult{StdOut: stdOut.String(), StdErr: stdErr.String(), ExitCode: 124}, nil
+			// return the exit code of timeout instead of err
+			return 124, nil
 		}
 
-		return ExecResult{}, fmt.Errorf("Failed reading output from container %s: %w", containerId, err)
+		return 0, fmt.Errorf("Failed reading output from container %s: %w", containerId, err)
 	}
 
 	inspectResp, err := cm.ContainerExecInspect(ctx, execID)
 	if err != nil {
-		return ExecResult{}, fmt.Errorf("Failed to inspect exec process %s in container %s: %w", execID, containerId, err)
+		return 0, fmt.Errorf("Failed to inspect exec process %s in container %s: %w", execID, containerId, err)
 	}
 
-	return ExecResult{StdOut: stdOut.String(), StdErr: stdErr.String(), ExitCode: inspectResp.ExitCode}, nil
+	return inspectResp.ExitCode, nil
 }
 
-func (cm *DockerContainerManager) ExecDetached(ctx context.Context, containerId string, command []string) (string, error) {
+func (cm *DockerContainerManager) ExecDetached(ctx context.Context, containerId string, command []string, opts ...ExecOption) (string, error) {
+	options := NewExecOptions(opts...)
 	execConfig := types.ExecConfig{
-		Cmd:          command,
-		Detach:       true,
+		User:   options.User,
+		Env:    options.Env,
+		Cmd:    command,
+		Detach: true,
 	}
 
 	execIDResp, err := cm.ContainerExecCreate(ctx, containerId, execConfig)
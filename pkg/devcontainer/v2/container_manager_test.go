@@ -1,7 +1,11 @@
 package devcontainer_test
 
 import (
+	"archive/tar"
 	"context"
+	"io"
+	"os"
+	"path/filepath"
 	"slices"
 	"testing"
 
@@ -9,10 +13,11 @@ import (
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/go-connections/nat"
-	"github.com/hide-org/hide/pkg/devcontainer"
-	"github.com/hide-org/hide/pkg/devcontainer/mocks"
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
+	"github.com/hide-org/hide/pkg/devcontainer/v2/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDockerContainerManager_CreateContainer(t *testing.T) {
@@ -331,3 +336,122 @@ func TestDockerContainerManager_Exec(t *testing.T) {
 		})
 	}
 }
+
+func TestDockerContainerManager_CreateContainerWithServer(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "hide")
+	require.NoError(t, os.WriteFile(binary, []byte("hide binary"), 0o755))
+
+	serverPort := nat.Port("8081/tcp")
+
+	mockClient := &mocks.MockDockerContainerClient{}
+	mockClient.On("ContainerCreate", mock.Anything, mock.MatchedBy(func(config *container.Config) bool {
+		_, ok := config.ExposedPorts[serverPort]
+		return ok
+	}), mock.MatchedBy(func(hostConfig *container.HostConfig) bool {
+		// the host port is allocated by Docker and no host directories besides the project are mounted
+		return slices.Equal(hostConfig.PortBindings[serverPort], []nat.PortBinding{{HostIP: "127.0.0.1", HostPort: ""}}) &&
+			len(hostConfig.Mounts) == 1
+	}), mock.Anything, mock.Anything, "").Return(container.CreateResponse{ID: "test-container-id"}, nil)
+
+	var copied map[string]string
+	mockClient.On("CopyToContainer", mock.Anything, "test-container-id", "/", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			copied = make(map[string]string)
+			tr := tar.NewReader(args.Get(3).(io.Reader))
+			for {
+				hdr, err := tr.Next()
+				if err != nil {
+					break
+				}
+				content, _ := io.ReadAll(tr)
+				copied[hdr.Name] = string(content)
+				assert.Equal(t, int64(0o755), hdr.Mode)
+			}
+		}).Return(nil)
+
+	containerManager := devcontainer.NewDockerContainerManager(mockClient, devcontainer.ContainerManagerWithServerBinary(binary))
+	containerId, err := containerManager.CreateContainer(context.Background(), "test-image", "/test/project", devcontainer.Config{})
+
+	require.NoError(t, err)
+	assert.Equal(t, "test-container-id", containerId)
+	assert.Equal(t, map[string]string{"opt/hide/bin/hide": "hide binary"}, copied)
+	mockClient.AssertExpectations(t)
+}
+
+func TestDockerContainerManager_CreateContainerWithServer_CopyFails(t *testing.T) {
+	mockClient := &mocks.MockDockerContainerClient{}
+	mockClient.On("ContainerCreate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, "").Return(container.CreateResponse{ID: "test-container-id"}, nil)
+	mockClient.On("ContainerRemove", mock.Anything, "test-container-id", container.RemoveOptions{Force: true}).Return(nil)
+
+	// the binary does not exist
+	binary := filepath.Join(t.TempDir(), "hide")
+	containerManager := devcontainer.NewDockerContainerManager(mockClient, devcontainer.ContainerManagerWithServerBinary(binary))
+	_, err := containerManager.CreateContainer(context.Background(), "test-image", "/test/project", devcontainer.Config{})
+
+	assert.ErrorContains(t, err, "Failed to read hide server binary")
+	// the container without the server is removed
+	mockClient.AssertExpectations(t)
+}
+
+func TestDockerContainerManager_CopyServerBinary(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "hide")
+	require.NoError(t, os.WriteFile(binary, []byte("hide binary"), 0o755))
+
+	var copied []string
+	mockClient := &mocks.MockDockerContainerClient{}
+	mockClient.On("CopyToContainer", mock.Anything, "service-container-id", "/", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			tr := tar.NewReader(args.Get(3).(io.Reader))
+			for hdr, err := tr.Next(); err == nil; hdr, err = tr.Next() {
+				copied = append(copied, hdr.Name)
+			}
+		}).Return(nil)
+
+	// containers that are not created by the manager, e.g. Docker Compose services, get the binary too
+	err := devcontainer.NewDockerContainerManager(mockClient, devcontainer.ContainerManagerWithServerBinary(binary)).CopyServerBinary(context.Background(), "service-container-id")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"opt/hide/bin/hide"}, copied)
+
+	// without a binary nothing is copied
+	err = devcontainer.NewDockerContainerManager(mockClient).CopyServerBinary(context.Background(), "other-container-id")
+	require.NoError(t, err)
+	mockClient.AssertNumberOfCalls(t, "CopyToContainer", 1)
+}
+
+func TestDockerContainerManager_HostPort(t *testing.T) {
+	tests := []struct {
+		name          string
+		ports         nat.PortMap
+		expected      int
+		expectedError string
+	}{
+		{
+			name:     "Published port",
+			ports:    nat.PortMap{"8081/tcp": []nat.PortBinding{{HostIP: "127.0.0.1", HostPort: "49153"}}},
+			expected: 49153,
+		},
+		{
+			name:          "Port not published",
+			ports:         nat.PortMap{"3000/tcp": []nat.PortBinding{{HostIP: "127.0.0.1", HostPort: "3000"}}},
+			expectedError: "Port 8081 of container test-container-id is not published",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mocks.MockDockerContainerClient{}
+			mockClient.On("ContainerInspect", mock.Anything, "test-container-id").Return(types.ContainerJSON{
+				NetworkSettings: &types.NetworkSettings{NetworkSettingsBase: types.NetworkSettingsBase{Ports: tt.ports}},
+			}, nil)
+
+			port, err := devcontainer.NewDockerContainerManager(mockClient).HostPort(context.Background(), "test-container-id", devcontainer.ServerPort)
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, port)
+			}
+		})
+	}
+}
@@ -0,0 +1,482 @@
+package devcontainer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hide-org/hide/pkg/util"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/afero"
+)
+
+const (
+	ociManifestMediaType  = "application/vnd.oci.image.manifest.v1+json"
+	featureLayerMediaType = "application/vnd.devcontainers.layer.v1+tar"
+	ociRefNameAnnotation  = "org.opencontainers.image.ref.name"
+)
+
+type FeatureResolver interface {
+	// Resolve makes the features of the config available locally and returns them in install order.
+	Resolve(ctx context.Context, projectPath string, config Config) ([]ResolvedFeature, error)
+}
+
+// OCIFeatureResolver resolves local features, referenced relative to devcontainer.json, and features published to OCI
+// registries, e.g. ghcr.io/devcontainers/features/go:1. Published features are kept in an OCI image layout, which is
+// used when the registry cannot be reached. The layout can be populated in advance for offline use.
+type OCIFeatureResolver struct {
+	client    *http.Client
+	layoutDir string
+
+	mu sync.Mutex // guards the layout
+}
+
+func NewOCIFeatureResolver(client *http.Client, layoutDir string) FeatureResolver {
+	return &OCIFeatureResolver{client: client, layoutDir: layoutDir}
+}
+
+func (r *OCIFeatureResolver) Resolve(ctx context.Context, projectPath string, config Config) ([]ResolvedFeature, error) {
+	features := make([]ResolvedFeature, 0, len(config.Features))
+	for ref, options := range config.Features {
+		dir, err := r.featureDir(ctx, filepath.Join(projectPath, config.Path), ref)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to resolve feature %s: %w", ref, err)
+		}
+
+		content, err := os.ReadFile(filepath.Join(dir, "devcontainer-feature.json"))
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read devcontainer-feature.json of feature %s: %w", ref, err)
+		}
+
+		var feature Feature
+		if err := json.Unmarshal(content, &feature); err != nil {
+			return nil, fmt.Errorf("Failed to parse devcontainer-feature.json of feature %s: %w", ref, err)
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, "install.sh")); err != nil {
+			return nil, fmt.Errorf("Feature %s has no install.sh: %w", ref, err)
+		}
+
+		features = append(features, ResolvedFeature{Ref: ref, Feature: feature, Dir: dir, Options: options})
+	}
+
+	return orderFeatures(features, config.OverrideFeatureInstallOrder)
+}
+
+func (r *OCIFeatureResolver) featureDir(ctx context.Context, configDir string, ref string) (string, error) {
+	if strings.HasPrefix(ref, "./") || strings.HasPrefix(ref, "../") {
+		return filepath.Join(configDir, ref), nil
+	}
+
+	if strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") {
+		return "", errors.New("features referenced by URL are not supported")
+	}
+
+	return r.ociFeatureDir(ctx, ref)
+}
+
+// ociFeatureDir pulls the feature into the layout and returns the directory the feature layer is extracted to.
+func (r *OCIFeatureResolver) ociFeatureDir(ctx context.Context, ref string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	layer, err := r.pull(ctx, ref)
+	if err != nil {
+		cached, ok := r.lookup(ref)
+		if !ok {
+			return "", err
+		}
+
+		log.Warn().Err(err).Str("feature", ref).Msg("Failed to pull feature, using cached version")
+		layer = cached
+	}
+
+	dir := filepath.Join(r.layoutDir, "features", strings.ReplaceAll(layer, ":", "-"))
+	if _, err := os.Stat(filepath.Join(dir, "devcontainer-feature.json")); err == nil {
+		return dir, nil
+	}
+
+	blob, err := os.Open(r.blobPath(layer))
+	if err != nil {
+		return "", err
+	}
+	defer blob.Close()
+
+	if err := extractTar(blob, dir); err != nil {
+		return "", fmt.Errorf("Failed to extract feature layer %s: %w", layer, err)
+	}
+
+	return dir, nil
+}
+
+type ociReference struct {
+	registry   string
+	repository string
+	reference  string // tag or digest
+}
+
+func parseOCIReference(ref string) (ociReference, error) {
+	registry, path, ok := strings.Cut(ref, "/")
+	if !ok || !strings.ContainsAny(registry, ".:") {
+		return ociReference{}, fmt.Errorf("invalid feature reference %s, expected <registry>/<namespace>/<feature>[:<version>]", ref)
+	}
+
+	repository, reference := path, "latest"
+	if i := strings.Index(path, "@"); i >= 0 {
+		repository, reference = path[:i], path[i+1:]
+	} else if i := strings.LastIndex(path, ":"); i >= 0 {
+		repository, reference = path[:i], path[i+1:]
+	}
+
+	return ociReference{registry: registry, repository: repository, reference: reference}, nil
+}
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type ociManifest struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	MediaType     string          `json:"mediaType,omitempty"`
+	Layers        []ociDescriptor `json:"layers"`
+}
+
+type ociIndex struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	Manifests     []ociDescriptor `json:"manifests"`
+}
+
+// pull downloads the manifest and feature layer of ref into the layout and returns the digest of the layer.
+func (r *OCIFeatureResolver) pull(ctx context.Context, ref string) (string, error) {
+	oci, err := parseOCIReference(ref)
+	if err != nil {
+		return "", err
+	}
+
+	registry := newRegistryClient(r.client, oci.registry, oci.repository)
+
+	manifestBytes, err := registry.get(ctx, "manifests/"+oci.reference, ociManifestMediaType)
+	if err != nil {
+		return "", fmt.Errorf("Failed to get manifest: %w", err)
+	}
+
+	var manifest ociManifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return "", fmt.Errorf("Failed to parse manifest: %w", err)
+	}
+
+	var layer *ociDescriptor
+	for i := range manifest.Layers {
+		if manifest.Layers[i].MediaType == featureLayerMediaType {
+			layer = &manifest.Layers[i]
+			break
+		}
+	}
+	if layer == nil {
+		return "", fmt.Errorf("manifest has no layer of type %s", featureLayerMediaType)
+	}
+
+	if _, err := os.Stat(r.blobPath(layer.Digest)); err != nil {
+		blob, err := registry.get(ctx, "blobs/"+layer.Digest, "")
+		if err != nil {
+			return "", fmt.Errorf("Failed to get feature layer: %w", err)
+		}
+
+		if err := r.writeBlob(layer.Digest, blob); err != nil {
+			return "", err
+		}
+	}
+
+	manifestDigest := digestOf(manifestBytes)
+	if err := r.writeBlob(manifestDigest, manifestBytes); err != nil {
+		return "", err
+	}
+
+	if err := r.tag(ref, ociDescriptor{MediaType: ociManifestMediaType, Digest: manifestDigest, Size: int64(len(manifestBytes))}); err != nil {
+		return "", err
+	}
+
+	return layer.Digest, nil
+}
+
+// lookup returns the digest of the feature layer of ref in the layout.
+func (r *OCIFeatureResolver) lookup(ref string) (string, bool) {
+	index, err := r.readIndex()
+	if err != nil {
+		return "", false
+	}
+
+	for _, m := range index.Manifests {
+		if m.Annotations[ociRefNameAnnotation] != ref {
+			continue
+		}
+
+		content, err := os.ReadFile(r.blobPath(m.Digest))
+		if err != nil {
+			return "", false
+		}
+
+		var manifest ociManifest
+		if err := json.Unmarshal(content, &manifest); err != nil {
+			return "", false
+		}
+
+		for _, layer := range manifest.Layers {
+			if layer.MediaType == featureLayerMediaType {
+				if _, err := os.Stat(r.blobPath(layer.Digest)); err == nil {
+					return layer.Digest, true
+				}
+			}
+		}
+	}
+
+	return "", false
+}
+
+// tag records the manifest of ref in index.json of the layout.
+func (r *OCIFeatureResolver) tag(ref string, manifest ociDescriptor) error {
+	index, err := r.readIndex()
+	if err != nil {
+		index = ociIndex{SchemaVersion: 2}
+	}
+
+	manifests := make([]ociDescriptor, 0, len(index.Manifests)+1)
+	for _, m := range index.Manifests {
+		if m.Annotations[ociRefNameAnnotation] != ref {
+			manifests = append(manifests, m)
+		}
+	}
+
+	manifest.Annotations = map[string]string{ociRefNameAnnotation: ref}
+	index.Manifests = append(manifests, manifest)
+
+	content, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(r.layoutDir, 0o755); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filepath.Join(r.layoutDir, "oci-layout"), []byte(`{"imageLayoutVersion":"1.0.0"}`), 0o644); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(r.layoutDir, "index.json"), content, 0o644)
+}
+
+func (r *OCIFeatureResolver) readIndex() (ociIndex, error) {
+	var index ociIndex
+
+	content, err := os.ReadFile(filepath.Join(r.layoutDir, "index.json"))
+	if err != nil {
+		return index, err
+	}
+
+	err = json.Unmarshal(content, &index)
+	return index, err
+}
+
+func (r *OCIFeatureResolver) writeBlob(digest string, content []byte) error {
+	if got := digestOf(content); got != digest {
+		return fmt.Errorf("digest mismatch, expected %s, got %s", digest, got)
+	}
+
+	path := r.blobPath(digest)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// write to a temporary file first, partial blobs must not end up in the layout
+	if err := os.WriteFile(path+".part", content, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(path+".part", path)
+}
+
+func (r *OCIFeatureResolver) blobPath(digest string) string {
+	algorithm, hash, _ := strings.Cut(digest, ":")
+	return filepath.Join(r.layoutDir, "blobs", algorithm, hash)
+}
+
+func digestOf(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// registryClient gets manifests and blobs of a repository with the OCI distribution API. Anonymous bearer tokens are
+// requested when the registry asks for them, e.g. ghcr.io does for public packages.
+type registryClient struct {
+	client     *http.Client
+	registry   string
+	repository string
+	token      string
+}
+
+func newRegistryClient(client *http.Client, registry, repository string) *registryClient {
+	return &registryClient{client: client, registry: registry, repository: repository}
+}
+
+func (c *registryClient) get(ctx context.Context, path string, accept string) ([]byte, error) {
+	uri := fmt.Sprintf("https://%s/v2/%s/%s", c.registry, c.repository, path)
+
+	resp, err := c.do(ctx, uri, accept)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized && c.token == "" {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+
+		if err := c.authenticate(ctx, challenge); err != nil {
+			return nil, err
+		}
+
+		if resp, err = c.do(ctx, uri, accept); err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s for %s", resp.Status, uri)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func (c *registryClient) do(ctx context.Context, uri string, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	return c.client.Do(req)
+}
+
+// authenticate requests a token as described by a challenge like
+// Bearer realm="https://ghcr.io/token",service="ghcr.io",scope="repository:devcontainers/features/go:pull"
+func (c *registryClient) authenticate(ctx context.Context, challenge string) error {
+	scheme, params, ok := strings.Cut(challenge, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return fmt.Errorf("unsupported authentication challenge %q", challenge)
+	}
+
+	values := make(map[string]string)
+	for _, param := range strings.Split(params, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if ok {
+			values[key] = strings.Trim(value, `"`)
+		}
+	}
+
+	realm := values["realm"]
+	if realm == "" {
+		return fmt.Errorf("authentication challenge %q has no realm", challenge)
+	}
+
+	scope := values["scope"]
+	if scope == "" {
+		scope = fmt.Sprintf("repository:%s:pull", c.repository)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm, nil)
+	if err != nil {
+		return err
+	}
+
+	query := req.URL.Query()
+	query.Set("scope", scope)
+	if service := values["service"]; service != "" {
+		query.Set("service", service)
+	}
+	req.URL.RawQuery = query.Encode()
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s for token of %s", resp.Status, c.repository)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return err
+	}
+
+	c.token = token.Token
+	if c.token == "" {
+		c.token = token.AccessToken
+	}
+
+	if c.token == "" {
+		return fmt.Errorf("no token for %s", c.repository)
+	}
+
+	return nil
+}
+
+// extractTar unpacks a tar archive, gzip compressed or not, into dir.
+func extractTar(src io.Reader, dir string) error {
+	content, err := io.ReadAll(src)
+	if err != nil {
+		return err
+	}
+
+	var reader io.Reader = bytes.NewReader(content)
+	if len(content) > 2 && content[0] == 0x1f && content[1] == 0x8b {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	// extract next to dir first, a partially extracted feature must not be used
+	tmp := dir + ".part"
+	if err := os.RemoveAll(tmp); err != nil {
+		return err
+	}
+
+	if err := util.ExtractTar(afero.NewOsFs(), reader, tmp); err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, dir)
+}
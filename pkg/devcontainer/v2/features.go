@@ -0,0 +1,247 @@
+package devcontainer
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// FeatureOptions are the options of a feature in devcontainer.json. A string is a shorthand for the version option,
+// e.g. "ghcr.io/devcontainers/features/go:1": "1.22".
+type FeatureOptions map[string]any
+
+func (f *FeatureOptions) UnmarshalJSON(data []byte) error {
+	var jsonObj interface{}
+	if err := json.Unmarshal(data, &jsonObj); err != nil {
+		return fmt.Errorf("Failed to unmarshal FeatureOptions: %w", err)
+	}
+
+	switch obj := jsonObj.(type) {
+	case string:
+		*f = FeatureOptions{"version": obj}
+		return nil
+	case bool:
+		// "feature": true installs the feature with default options
+		*f = FeatureOptions{}
+		return nil
+	case map[string]interface{}:
+		*f = obj
+		return nil
+	}
+
+	return fmt.Errorf("Unsupported type for FeatureOptions: %T", jsonObj)
+}
+
+// Feature is the metadata of a feature in devcontainer-feature.json. See https://containers.dev/implementors/features/
+type Feature struct {
+	ID            string                   `json:"id"`
+	Version       string                   `json:"version,omitempty"`
+	Name          string                   `json:"name,omitempty"`
+	Options       map[string]FeatureOption `json:"options,omitempty"`
+	InstallsAfter []string                 `json:"installsAfter,omitempty"`
+	ContainerEnv  map[string]string        `json:"containerEnv,omitempty"`
+}
+
+type FeatureOption struct {
+	Type        string `json:"type,omitempty"`
+	Default     any    `json:"default,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// ResolvedFeature is a feature of the devcontainer with its files available locally.
+type ResolvedFeature struct {
+	// Ref is the feature as referenced in devcontainer.json, e.g. "ghcr.io/devcontainers/features/go:1" or "./local-feature"
+	Ref string
+	Feature
+	// Dir contains install.sh and devcontainer-feature.json
+	Dir string
+	// Options are the options set in devcontainer.json
+	Options FeatureOptions
+}
+
+// baseRef returns the ref without version, as used by installsAfter and overrideFeatureInstallOrder.
+func (f ResolvedFeature) baseRef() string {
+	return featureBaseRef(f.Ref)
+}
+
+func featureBaseRef(ref string) string {
+	if i := strings.Index(ref, "@"); i >= 0 {
+		return ref[:i]
+	}
+	// a colon after the last slash separates the tag, others separate the registry port
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return ref[:i]
+	}
+	return ref
+}
+
+// matches reports whether the feature is referenced by id, which is either a ref without version or a feature id.
+func (f ResolvedFeature) matches(id string) bool {
+	id = featureBaseRef(id)
+	return id == f.baseRef() || id == f.ID || strings.HasSuffix(f.baseRef(), "/"+id)
+}
+
+// orderFeatures orders features so that features are installed after the ones in their installsAfter. Features in
+// overrideFeatureInstallOrder are installed first, in the given order, as far as installsAfter allows. Other features
+// keep the order of their refs.
+func orderFeatures(features []ResolvedFeature, overrideOrder []string) ([]ResolvedFeature, error) {
+	priority := func(f ResolvedFeature) int {
+		for i, id := range overrideOrder {
+			if f.matches(id) {
+				return i
+			}
+		}
+		return len(overrideOrder)
+	}
+
+	sorted := slices.Clone(features)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if pi, pj := priority(sorted[i]), priority(sorted[j]); pi != pj {
+			return pi < pj
+		}
+		return sorted[i].Ref < sorted[j].Ref
+	})
+
+	// installsAfter is a soft dependency, features that are not installed are ignored
+	after := make(map[int][]int, len(sorted))
+	for i, f := range sorted {
+		for _, id := range f.InstallsAfter {
+			for j, other := range sorted {
+				if i != j && other.matches(id) {
+					after[i] = append(after[i], j)
+				}
+			}
+		}
+	}
+
+	out := make([]ResolvedFeature, 0, len(sorted))
+	installed := make([]bool, len(sorted))
+	for len(out) < len(sorted) {
+		next := -1
+		for i := range sorted {
+			if installed[i] {
+				continue
+			}
+			if slices.ContainsFunc(after[i], func(j int) bool { return !installed[j] }) {
+				continue
+			}
+			next = i
+			break
+		}
+
+		if next < 0 {
+			var cycle []string
+			for i, f := range sorted {
+				if !installed[i] {
+					cycle = append(cycle, f.Ref)
+				}
+			}
+			return nil, fmt.Errorf("Failed to order features, installsAfter has a cycle between %s", strings.Join(cycle, ", "))
+		}
+
+		installed[next] = true
+		out = append(out, sorted[next])
+	}
+
+	return out, nil
+}
+
+var featureOptionInvalidChars = regexp.MustCompile(`[^\w_]`)
+var featureOptionLeadingInvalid = regexp.MustCompile(`^[\d_]+`)
+
+// featureOptionEnvName converts an option name to the variable passed to install.sh, e.g. "go-version" to GO_VERSION.
+func featureOptionEnvName(name string) string {
+	name = featureOptionInvalidChars.ReplaceAllString(name, "_")
+	name = featureOptionLeadingInvalid.ReplaceAllString(name, "")
+	return strings.ToUpper(name)
+}
+
+// env returns the options as variables for install.sh, defaults of options not set in devcontainer.json included.
+func (f ResolvedFeature) env() map[string]string {
+	env := make(map[string]string, len(f.Feature.Options)+len(f.Options))
+	for name, option := range f.Feature.Options {
+		if option.Default != nil {
+			env[featureOptionEnvName(name)] = fmt.Sprint(option.Default)
+		}
+	}
+
+	for name, value := range f.Options {
+		env[featureOptionEnvName(name)] = fmt.Sprint(value)
+	}
+
+	return env
+}
+
+// envFile returns the options as a shell file, sourced before install.sh.
+func (f ResolvedFeature) envFile() string {
+	env := f.env()
+
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s=%s\n", name, shellQuote(env[name]))
+	}
+	return b.String()
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+const featuresDir = "/tmp/devcontainer-features"
+
+// featuresDockerfile returns a Dockerfile that installs the features on top of the base image. Features are expected
+// in the build context as features/<index>.
+func featuresDockerfile(baseImage string, baseUser string, features []ResolvedFeature, config Config) string {
+	containerUser := config.ContainerUser
+	if containerUser == "" {
+		containerUser = baseUser
+	}
+	if containerUser == "" {
+		containerUser = "root"
+	}
+
+	remoteUser := config.RemoteUser
+	if remoteUser == "" {
+		remoteUser = containerUser
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "FROM %s\n", baseImage)
+	b.WriteString("USER root\n")
+	fmt.Fprintf(&b, "ENV _CONTAINER_USER=%s _REMOTE_USER=%s\n", containerUser, remoteUser)
+
+	for i, feature := range features {
+		dir := fmt.Sprintf("%s/%d", featuresDir, i)
+		fmt.Fprintf(&b, "\n# %s\n", feature.Ref)
+		fmt.Fprintf(&b, "COPY features/%d %s\n", i, dir)
+		fmt.Fprintf(&b, "RUN cd %s && chmod +x install.sh && set -a && . ./devcontainer-features.env && set +a && ./install.sh\n", dir)
+
+		if len(feature.ContainerEnv) > 0 {
+			names := make([]string, 0, len(feature.ContainerEnv))
+			for name := range feature.ContainerEnv {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				fmt.Fprintf(&b, "ENV %s=%q\n", name, feature.ContainerEnv[name])
+			}
+		}
+	}
+
+	fmt.Fprintf(&b, "\nRUN rm -rf %s\n", featuresDir)
+	if baseUser != "" {
+		fmt.Fprintf(&b, "USER %s\n", baseUser)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,343 @@
+package devcontainer_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
+	"github.com/hide-org/hide/pkg/devcontainer/v2/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeatureOptions_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    devcontainer.FeatureOptions
+		wantErr bool
+	}{
+		{name: "Version shorthand", input: `"1.22"`, want: devcontainer.FeatureOptions{"version": "1.22"}},
+		{name: "Default options", input: `true`, want: devcontainer.FeatureOptions{}},
+		{name: "Options", input: `{"version": "latest", "installTools": false}`, want: devcontainer.FeatureOptions{"version": "latest", "installTools": false}},
+		{name: "Invalid", input: `1`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var options devcontainer.FeatureOptions
+			err := json.Unmarshal([]byte(tt.input), &options)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, options)
+			}
+		})
+	}
+}
+
+func writeFeature(t *testing.T, dir string, metadata string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "devcontainer-feature.json"), []byte(metadata), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "install.sh"), []byte("#!/bin/sh\necho installing\n"), 0o755))
+}
+
+func refs(features []devcontainer.ResolvedFeature) []string {
+	refs := make([]string, 0, len(features))
+	for _, f := range features {
+		refs = append(refs, f.Ref)
+	}
+	return refs
+}
+
+func TestOCIFeatureResolver_ResolveLocal(t *testing.T) {
+	projectPath := t.TempDir()
+	configDir := filepath.Join(projectPath, ".devcontainer")
+
+	writeFeature(t, filepath.Join(configDir, "a"), `{"id": "a", "installsAfter": ["./c"]}`)
+	writeFeature(t, filepath.Join(configDir, "b"), `{"id": "b", "options": {"version": {"type": "string", "default": "1.0"}}}`)
+	writeFeature(t, filepath.Join(configDir, "c"), `{"id": "c", "installsAfter": ["b"]}`)
+	writeFeature(t, filepath.Join(configDir, "x"), `{"id": "x", "installsAfter": ["./y"]}`)
+	writeFeature(t, filepath.Join(configDir, "y"), `{"id": "y", "installsAfter": ["x"]}`)
+	require.NoError(t, os.MkdirAll(filepath.Join(configDir, "no-install"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "no-install", "devcontainer-feature.json"), []byte(`{"id": "no-install"}`), 0o644))
+
+	tests := []struct {
+		name          string
+		features      map[string]devcontainer.FeatureOptions
+		overrideOrder []string
+		want          []string
+		wantError     string
+	}{
+		{
+			name:     "Orders by installsAfter",
+			features: map[string]devcontainer.FeatureOptions{"./a": {}, "./b": {}, "./c": {}},
+			want:     []string{"./b", "./c", "./a"},
+		},
+		{
+			name:          "Override order comes first",
+			features:      map[string]devcontainer.FeatureOptions{"./a": {}, "./b": {}},
+			overrideOrder: []string{"./a"},
+			want:          []string{"./a", "./b"},
+		},
+		{
+			name:          "installsAfter wins over override order",
+			features:      map[string]devcontainer.FeatureOptions{"./b": {}, "./c": {}},
+			overrideOrder: []string{"./c", "./b"},
+			want:          []string{"./b", "./c"},
+		},
+		{
+			name:      "Cycle",
+			features:  map[string]devcontainer.FeatureOptions{"./b": {}, "./x": {}, "./y": {}},
+			wantError: "installsAfter has a cycle between ./x, ./y",
+		},
+		{
+			name:      "Missing install.sh",
+			features:  map[string]devcontainer.FeatureOptions{"./no-install": {}},
+			wantError: "Feature ./no-install has no install.sh",
+		},
+		{
+			name:      "Missing feature",
+			features:  map[string]devcontainer.FeatureOptions{"./missing": {}},
+			wantError: "Failed to read devcontainer-feature.json of feature ./missing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := devcontainer.NewOCIFeatureResolver(http.DefaultClient, t.TempDir())
+			config := devcontainer.Config{
+				Path: ".devcontainer",
+				GeneralProperties: devcontainer.GeneralProperties{
+					Features:                    tt.features,
+					OverrideFeatureInstallOrder: tt.overrideOrder,
+				},
+			}
+
+			features, err := resolver.Resolve(context.Background(), projectPath, config)
+
+			if tt.wantError != "" {
+				assert.ErrorContains(t, err, tt.wantError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, refs(features))
+			}
+		})
+	}
+}
+
+func featureLayer(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: "./" + name, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+
+	return buf.Bytes()
+}
+
+func sha256Digest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func TestOCIFeatureResolver_ResolveOCI(t *testing.T) {
+	layer := featureLayer(t, map[string]string{
+		"devcontainer-feature.json": `{"id": "go", "version": "1.3.0"}`,
+		"install.sh":                "#!/bin/sh\n",
+	})
+	manifest := fmt.Sprintf(`{"schemaVersion": 2, "layers": [{"mediaType": "application/vnd.devcontainers.layer.v1+tar", "digest": %q, "size": %d}]}`, sha256Digest(layer), len(layer))
+
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			assert.Equal(t, "repository:features/go:pull", r.URL.Query().Get("scope"))
+			w.Write([]byte(`{"token": "secret"}`))
+		case r.Header.Get("Authorization") != "Bearer secret":
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:features/go:pull"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/v2/features/go/manifests/1":
+			w.Write([]byte(manifest))
+		case r.URL.Path == "/v2/features/go/blobs/"+sha256Digest(layer):
+			w.Write(layer)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ref := strings.TrimPrefix(server.URL, "https://") + "/features/go:1"
+	config := devcontainer.Config{
+		GeneralProperties: devcontainer.GeneralProperties{
+			Features: map[string]devcontainer.FeatureOptions{ref: {"version": "1.22"}},
+		},
+	}
+	layoutDir := t.TempDir()
+
+	features, err := devcontainer.NewOCIFeatureResolver(server.Client(), layoutDir).Resolve(context.Background(), t.TempDir(), config)
+	require.NoError(t, err)
+	require.Len(t, features, 1)
+	assert.Equal(t, "go", features[0].ID)
+	assert.Equal(t, "1.3.0", features[0].Version)
+	assert.Equal(t, devcontainer.FeatureOptions{"version": "1.22"}, features[0].Options)
+	assert.FileExists(t, filepath.Join(features[0].Dir, "install.sh"))
+	assert.FileExists(t, filepath.Join(layoutDir, "index.json"))
+
+	// the layout is used when the registry is not available
+	server.Close()
+
+	features, err = devcontainer.NewOCIFeatureResolver(server.Client(), layoutDir).Resolve(context.Background(), t.TempDir(), config)
+	require.NoError(t, err)
+	require.Len(t, features, 1)
+	assert.Equal(t, "go", features[0].ID)
+
+	// features not in the layout fail
+	config.Features = map[string]devcontainer.FeatureOptions{strings.Replace(ref, ":1", ":2", 1): {}}
+	_, err = devcontainer.NewOCIFeatureResolver(server.Client(), layoutDir).Resolve(context.Background(), t.TempDir(), config)
+	assert.ErrorContains(t, err, "Failed to resolve feature")
+}
+
+func TestDockerRunner_RunFeatures(t *testing.T) {
+	config := devcontainer.Config{
+		DockerImageProps: devcontainer.DockerImageProps{Image: "base-image"},
+		GeneralProperties: devcontainer.GeneralProperties{
+			Features: map[string]devcontainer.FeatureOptions{"./feature": {}},
+		},
+	}
+	features := []devcontainer.ResolvedFeature{{Ref: "./feature", Dir: "/test/project/.devcontainer/feature"}}
+
+	tests := []struct {
+		name       string
+		setupMocks func(*mocks.MockFeatureResolver, *mocks.MockImageManager, *mocks.MockContainerManager)
+		wantError  string
+	}{
+		{
+			name: "Creates container from image with features",
+			setupMocks: func(mfr *mocks.MockFeatureResolver, mim *mocks.MockImageManager, mcm *mocks.MockContainerManager) {
+				mim.On("LocalImageExists", mock.Anything, "base-image").Return(true, nil)
+				mfr.On("Resolve", mock.Anything, "/test/project", config).Return(features, nil)
+				mim.On("BuildFeaturesImage", mock.Anything, "base-image", features, config).Return("features-image", nil)
+				mcm.On("CreateContainer", mock.Anything, "features-image", "/test/project", config).Return("container-id", nil)
+				mcm.On("StartContainer", mock.Anything, "container-id").Return(nil)
+			},
+		},
+		{
+			name: "Failed to resolve features",
+			setupMocks: func(mfr *mocks.MockFeatureResolver, mim *mocks.MockImageManager, mcm *mocks.MockContainerManager) {
+				mim.On("LocalImageExists", mock.Anything, "base-image").Return(true, nil)
+				mfr.On("Resolve", mock.Anything, "/test/project", config).Return([]devcontainer.ResolvedFeature(nil), fmt.Errorf("registry unavailable"))
+			},
+			wantError: "Failed to install features: registry unavailable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockResolver := &mocks.MockFeatureResolver{}
+			mockImageManager := &mocks.MockImageManager{}
+			mockContainerManager := &mocks.MockContainerManager{}
+			probedUserEnv(mockContainerManager, "").Maybe()
+			noImageMetadata(mockImageManager)
+			tt.setupMocks(mockResolver, mockImageManager, mockContainerManager)
+
+			runner := devcontainer.NewDockerRunner(&mocks.MockExecutor{}, mockImageManager, mockContainerManager, devcontainer.RunnerWithFeatureResolver(mockResolver))
+			_, err := runner.Run(context.Background(), "/test/project", config)
+
+			if tt.wantError != "" {
+				assert.ErrorContains(t, err, tt.wantError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockResolver.AssertExpectations(t)
+			mockImageManager.AssertExpectations(t)
+			mockContainerManager.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDockerImageManager_BuildFeaturesImage(t *testing.T) {
+	dir := t.TempDir()
+	writeFeature(t, dir, `{"id": "go"}`)
+
+	features := []devcontainer.ResolvedFeature{{
+		Ref: "ghcr.io/devcontainers/features/go:1",
+		Feature: devcontainer.Feature{
+			ID:           "go",
+			Options:      map[string]devcontainer.FeatureOption{"version": {Default: "latest"}, "golangci-lint-version": {Default: "none"}},
+			ContainerEnv: map[string]string{"PATH": "/usr/local/go/bin:${PATH}"},
+		},
+		Dir:     dir,
+		Options: devcontainer.FeatureOptions{"version": "1.22"},
+	}}
+	config := devcontainer.Config{GeneralProperties: devcontainer.GeneralProperties{Name: "My Project", RemoteUser: "dev"}}
+
+	mockClient := &mocks.MockDockerImageClient{}
+	mockClient.On("ImageInspectWithRaw", mock.Anything, "base-image").Return(types.ImageInspect{ID: "sha256:base", Config: &container.Config{User: "vscode"}}, []byte{}, nil)
+	mockClient.On("ImageList", mock.Anything, mock.Anything).Return([]image.Summary{}, nil)
+
+	var options types.ImageBuildOptions
+	files := make(map[string]string)
+	mockClient.On("ImageBuild", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			options = args.Get(2).(types.ImageBuildOptions)
+			tr := tar.NewReader(args.Get(1).(io.Reader))
+			for {
+				hdr, err := tr.Next()
+				if err != nil {
+					break
+				}
+				content, _ := io.ReadAll(tr)
+				files[hdr.Name] = string(content)
+			}
+		}).
+		Return(types.ImageBuildResponse{Body: io.NopCloser(strings.NewReader(""))}, nil)
+
+	imageManager := devcontainer.NewImageManager(mockClient, &mocks.MockRegistryCredentials{})
+	tag, err := imageManager.BuildFeaturesImage(context.Background(), "base-image", features, config)
+
+	require.NoError(t, err)
+	assert.Regexp(t, `^my-project-features:[0-9a-f]{12}$`, tag)
+	assert.Equal(t, []string{tag}, options.Tags)
+	assert.Equal(t, "Dockerfile", options.Dockerfile)
+	assert.Equal(t, strings.Split(tag, ":")[1], options.Labels[devcontainer.ImageBuildHashLabel])
+
+	assert.Equal(t, "GOLANGCI_LINT_VERSION='none'\nVERSION='1.22'\n", files["features/0/devcontainer-features.env"])
+	assert.Contains(t, files, "features/0/install.sh")
+	assert.Contains(t, files, "features/0/devcontainer-feature.json")
+
+	dockerfile := files["Dockerfile"]
+	assert.Contains(t, dockerfile, "FROM base-image\nUSER root\n")
+	assert.Contains(t, dockerfile, "ENV _CONTAINER_USER=vscode _REMOTE_USER=dev\n")
+	assert.Contains(t, dockerfile, "COPY features/0 /tmp/devcontainer-features/0\n")
+	assert.Contains(t, dockerfile, "./install.sh\n")
+	assert.Contains(t, dockerfile, `ENV PATH="/usr/local/go/bin:${PATH}"`)
+	assert.True(t, strings.HasSuffix(dockerfile, "USER vscode\n"))
+
+	mockClient.AssertExpectations(t)
+}
@@ -1,10 +1,18 @@
 package devcontainer
 
 import (
+	"archive/tar"
+	"bytes"
 	"context"
 	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
@@ -18,22 +26,43 @@ type ImageManager interface {
 	PullImage(ctx context.Context, name string) error
 	BuildImage(ctx context.Context, workingDir string, config Config) (string, error)
 	LocalImageExists(ctx context.Context, name string) (bool, error)
+	// BuildFeaturesImage builds an image from baseImage with the features installed, in the given order.
+	BuildFeaturesImage(ctx context.Context, baseImage string, features []ResolvedFeature, config Config) (string, error)
+	// BuildUpdateUIDImage builds an image from baseImage with the UID/GID of the user changed to uid/gid.
+	BuildUpdateUIDImage(ctx context.Context, baseImage string, user string, uid, gid int) (string, error)
+	// PruneImages removes stale images built by hide, see DockerImageManager.PruneImages.
+	PruneImages(ctx context.Context, olderThan time.Duration) ([]string, uint64, error)
+	// ImageMetadata returns the entries of the devcontainer.metadata label of the image, if any.
+	ImageMetadata(ctx context.Context, image string) ([]ImageMetadata, error)
 }
 
 type DockerImageManager struct {
 	client.ImageAPIClient
-	randomString func(int) string
-	credentials  RegistryCredentials
+	credentials RegistryCredentials
+	output      io.Writer
 }
 
-func NewImageManager(dockerImageCli client.ImageAPIClient, randomString func(int) string, credentials RegistryCredentials) ImageManager {
-	return &DockerImageManager{ImageAPIClient: dockerImageCli, randomString: randomString, credentials: credentials}
+type ImageManagerOption func(im *DockerImageManager)
+
+// ImageManagerWithOutput streams the logs and progress of builds and pulls to w, e.g. os.Stderr.
+func ImageManagerWithOutput(w io.Writer) ImageManagerOption {
+	return func(im *DockerImageManager) {
+		im.output = w
+	}
+}
+
+func NewImageManager(dockerImageCli client.ImageAPIClient, credentials RegistryCredentials, opts ...ImageManagerOption) ImageManager {
+	im := &DockerImageManager{ImageAPIClient: dockerImageCli, credentials: credentials}
+	for _, opt := range opts {
+		opt(im)
+	}
+	return im
 }
 
 func (im *DockerImageManager) PullImage(ctx context.Context, name string) error {
 	log.Debug().Str("image", name).Msg("Pulling image")
 
-	authStr, err := im.credentials.GetCredentials()
+	authStr, err := im.credentials.GetCredentials(name)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to encode registry auth")
 		return fmt.Errorf("Failed to encode registry auth: %w", err)
@@ -45,8 +74,8 @@ func (im *DockerImageManager) PullImage(ctx context.Context, name string) error
 	}
 	defer output.Close()
 
-	if err := logResponse(output); err != nil {
-		log.Error().Err(err)
+	if err := im.streamResponse(output); err != nil {
+		return fmt.Errorf("Failed to pull image %s: %w", name, err)
 	}
 
 	log.Debug().Str("image", name).Msg("Pulled image")
@@ -87,25 +116,7 @@ func (im *DockerImageManager) BuildImage(ctx context.Context, workingDir string,
 		return "", fmt.Errorf("Failed to get relative path of Dockerfile: %w", err)
 	}
 
-	log.Debug().Str("buildContextPath", contextPath).Msg("Building image")
-
-	buildContext, err := archive.TarWithOptions(contextPath, &archive.TarOptions{})
-	if err != nil {
-		return "", fmt.Errorf("Failed to create tar archive from %s for Docker build context: %w", contextPath, err)
-	}
-	defer buildContext.Close()
-
-	var tag string
-	if config.Name != "" {
-		containerName := sanitizeContainerName(config.Name)
-		tag = fmt.Sprintf("%s-%s:%s", containerName, im.randomString(6), "latest")
-	} else {
-		tag = fmt.Sprintf("%s:%s", im.randomString(6), "latest")
-	}
-	tag = strings.ToLower(tag)
-
 	options := types.ImageBuildOptions{
-		Tags:       []string{tag},
 		Dockerfile: dockerFileRelativePath,
 	}
 
@@ -113,17 +124,47 @@ func (im *DockerImageManager) BuildImage(ctx context.Context, workingDir string,
 		options.BuildArgs = config.Build.Args
 		options.CacheFrom = config.Build.CacheFrom
 		options.Target = config.Build.Target
-		// NOTE: config.Build.RunArgs are ignored because they are defined as []string and it's not obvious how to map them into types.ImageBuildOptions{}
+		// NOTE: config.Build.Options are ignored, runArgs apply to the container and not to the build
 	}
 
-	imageBuildResponse, err := im.ImageBuild(ctx, buildContext, options)
+	// the base images may be on private registries
+	authConfigs, err := im.credentials.GetAllCredentials()
 	if err != nil {
-		return "", fmt.Errorf("Failed to build Docker image: %w", err)
+		return "", fmt.Errorf("Failed to get registry credentials: %w", err)
 	}
-	defer imageBuildResponse.Body.Close()
+	options.AuthConfigs = authConfigs
+
+	excludes, err := readDockerignore(contextPath, dockerFileRelativePath)
+	if err != nil {
+		return "", fmt.Errorf("Failed to read .dockerignore of %s: %w", contextPath, err)
+	}
+
+	digest, err := buildHash(func(h hash.Hash) error {
+		writeBuildOptionsHash(h, options)
+		return writeBuildContextHash(h, contextPath, excludes)
+	})
+	if err != nil {
+		return "", fmt.Errorf("Failed to hash Docker build context %s: %w", contextPath, err)
+	}
+
+	tag := imageTag(config.Name, "", digest)
+	if exists, err := im.LocalImageExists(ctx, tag); err != nil {
+		return "", err
+	} else if exists {
+		log.Info().Str("tag", tag).Msg("Reusing image built from the same Dockerfile and build context")
+		return tag, nil
+	}
+
+	log.Debug().Str("buildContextPath", contextPath).Msg("Building image")
+
+	buildContext, err := archive.TarWithOptions(contextPath, &archive.TarOptions{ExcludePatterns: excludes})
+	if err != nil {
+		return "", fmt.Errorf("Failed to create tar archive from %s for Docker build context: %w", contextPath, err)
+	}
+	defer buildContext.Close()
 
-	if err := logResponse(imageBuildResponse.Body); err != nil {
-		log.Error().Err(err)
+	if err := im.build(ctx, buildContext, options, tag, digest); err != nil {
+		return "", fmt.Errorf("Failed to build Docker image: %w", err)
 	}
 
 	log.Debug().Str("tag", tag).Msg("Built image")
@@ -144,6 +185,220 @@ func (im *DockerImageManager) LocalImageExists(ctx context.Context, name string)
     return exists, nil
 }
 
+func (im *DockerImageManager) ImageMetadata(ctx context.Context, name string) ([]ImageMetadata, error) {
+	inspect, _, err := im.ImageInspectWithRaw(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to inspect image %s: %w", name, err)
+	}
+
+	if inspect.Config == nil || inspect.Config.Labels[ImageMetadataLabel] == "" {
+		return nil, nil
+	}
+
+	metadata, err := parseImageMetadata(inspect.Config.Labels[ImageMetadataLabel])
+	if err != nil {
+		return nil, fmt.Errorf("Invalid %s label of image %s: %w", ImageMetadataLabel, name, err)
+	}
+
+	return metadata, nil
+}
+
+func (im *DockerImageManager) BuildFeaturesImage(ctx context.Context, baseImage string, features []ResolvedFeature, config Config) (string, error) {
+	inspect, _, err := im.ImageInspectWithRaw(ctx, baseImage)
+	if err != nil {
+		return "", fmt.Errorf("Failed to inspect image %s: %w", baseImage, err)
+	}
+
+	var baseUser string
+	if inspect.Config != nil {
+		baseUser = inspect.Config.User
+	}
+
+	buildContext, err := featuresBuildContext(featuresDockerfile(baseImage, baseUser, features, config), features)
+	if err != nil {
+		return "", fmt.Errorf("Failed to create Docker build context for features: %w", err)
+	}
+
+	options := types.ImageBuildOptions{Dockerfile: "Dockerfile"}
+	digest, _ := buildHash(func(h hash.Hash) error {
+		// the build context is deterministic, it has no modification times
+		writeHashPart(h, inspect.ID, buildContext.String())
+		return nil
+	})
+
+	tag := imageTag(config.Name, "features", digest)
+	if exists, err := im.LocalImageExists(ctx, tag); err != nil {
+		return "", err
+	} else if exists {
+		log.Info().Str("tag", tag).Msg("Reusing image with the same features")
+		return tag, nil
+	}
+
+	log.Debug().Str("baseImage", baseImage).Int("features", len(features)).Msg("Building image with features")
+
+	if err := im.build(ctx, buildContext, options, tag, digest); err != nil {
+		return "", fmt.Errorf("Failed to build Docker image with features: %w", err)
+	}
+
+	log.Debug().Str("tag", tag).Msg("Built image with features")
+	return tag, nil
+}
+
+func (im *DockerImageManager) BuildUpdateUIDImage(ctx context.Context, baseImage string, user string, uid, gid int) (string, error) {
+	inspect, _, err := im.ImageInspectWithRaw(ctx, baseImage)
+	if err != nil {
+		return "", fmt.Errorf("Failed to inspect image %s: %w", baseImage, err)
+	}
+
+	var baseUser string
+	if inspect.Config != nil {
+		baseUser = inspect.Config.User
+	}
+
+	dockerfile := updateUIDDockerfile(baseImage, baseUser)
+	buildContext, err := featuresBuildContext(dockerfile, nil)
+	if err != nil {
+		return "", fmt.Errorf("Failed to create Docker build context for updating UID: %w", err)
+	}
+
+	newUID, newGID := strconv.Itoa(uid), strconv.Itoa(gid)
+	options := types.ImageBuildOptions{
+		Dockerfile: "Dockerfile",
+		BuildArgs:  map[string]*string{"REMOTE_USER": &user, "NEW_UID": &newUID, "NEW_GID": &newGID},
+	}
+	digest, _ := buildHash(func(h hash.Hash) error {
+		writeHashPart(h, inspect.ID, dockerfile)
+		writeBuildOptionsHash(h, options)
+		return nil
+	})
+
+	tag := imageTag("", "uid", digest)
+	if exists, err := im.LocalImageExists(ctx, tag); err != nil {
+		return "", err
+	} else if exists {
+		log.Info().Str("tag", tag).Msg("Reusing image with updated UID")
+		return tag, nil
+	}
+
+	log.Debug().Str("baseImage", baseImage).Str("user", user).Int("uid", uid).Int("gid", gid).Msg("Building image with updated UID")
+
+	if err := im.build(ctx, buildContext, options, tag, digest); err != nil {
+		return "", fmt.Errorf("Failed to build Docker image with updated UID: %w", err)
+	}
+
+	log.Debug().Str("tag", tag).Msg("Built image with updated UID")
+	return tag, nil
+}
+
+// build builds the image with the tag and the digest of its build inputs as label, streaming the build output.
+func (im *DockerImageManager) build(ctx context.Context, buildContext io.Reader, options types.ImageBuildOptions, tag, digest string) error {
+	options.Tags = []string{tag}
+	options.Labels = map[string]string{ImageBuildHashLabel: digest}
+
+	imageBuildResponse, err := im.ImageBuild(ctx, buildContext, options)
+	if err != nil {
+		return err
+	}
+	defer imageBuildResponse.Body.Close()
+
+	return im.streamResponse(imageBuildResponse.Body)
+}
+
+// PruneImages removes the images built by hide that no container uses and that were created more than olderThan ago.
+// It returns the IDs of the removed images and the reclaimed space in bytes.
+func (im *DockerImageManager) PruneImages(ctx context.Context, olderThan time.Duration) ([]string, uint64, error) {
+	args := filters.NewArgs(filters.Arg("dangling", "false"), filters.Arg("label", ImageBuildHashLabel))
+	if olderThan > 0 {
+		args.Add("until", olderThan.String())
+	}
+
+	report, err := im.ImagesPrune(ctx, args)
+	if err != nil {
+		return nil, 0, fmt.Errorf("Failed to prune images: %w", err)
+	}
+
+	removed := []string{}
+	for _, deleted := range report.ImagesDeleted {
+		if deleted.Deleted != "" {
+			removed = append(removed, deleted.Deleted)
+		}
+	}
+
+	log.Debug().Int("images", len(removed)).Uint64("spaceReclaimed", report.SpaceReclaimed).Msg("Pruned images")
+	return removed, report.SpaceReclaimed, nil
+}
+
+// imageTag returns the tag of a built image, <name>[-<kind>]:<digest>. The name defaults to devcontainer.
+func imageTag(name, kind, digest string) string {
+	if name == "" {
+		name = "devcontainer"
+	}
+	name = sanitizeContainerName(name)
+	if kind != "" {
+		name = name + "-" + kind
+	}
+	return strings.ToLower(fmt.Sprintf("%s:%s", name, digest))
+}
+
+// featuresBuildContext returns a tar archive with the Dockerfile and the files of each feature in features/<index>,
+// along with the options of the feature in devcontainer-features.env.
+func featuresBuildContext(dockerfile string, features []ResolvedFeature) (*bytes.Buffer, error) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+
+	writeFile := func(name string, mode int64, content []byte) error {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: mode, Size: int64(len(content))}); err != nil {
+			return err
+		}
+		_, err := tw.Write(content)
+		return err
+	}
+
+	if err := writeFile("Dockerfile", 0o644, []byte(dockerfile)); err != nil {
+		return nil, err
+	}
+
+	for i, feature := range features {
+		prefix := fmt.Sprintf("features/%d", i)
+
+		err := filepath.WalkDir(feature.Dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+
+			rel, err := filepath.Rel(feature.Dir, path)
+			if err != nil {
+				return err
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+
+			return writeFile(prefix+"/"+filepath.ToSlash(rel), int64(info.Mode().Perm()), content)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Failed to add feature %s: %w", feature.Ref, err)
+		}
+
+		if err := writeFile(prefix+"/devcontainer-features.env", 0o644, []byte(feature.envFile())); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+
+	return &buf, nil
+}
+
 func sanitizeContainerName(containerName string) string {
 	containerName = strings.ReplaceAll(containerName, " ", "-")
 	return containerName
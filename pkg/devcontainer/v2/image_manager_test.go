@@ -5,15 +5,21 @@ import (
 	"context"
 	"errors"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
-	"github.com/hide-org/hide/pkg/devcontainer"
-	"github.com/hide-org/hide/pkg/devcontainer/mocks"
-	"github.com/hide-org/hide/pkg/random"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
+	"github.com/hide-org/hide/pkg/devcontainer/v2/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDockerImageManager_PullImage(t *testing.T) {
@@ -46,7 +52,7 @@ func TestDockerImageManager_PullImage(t *testing.T) {
 			imageName: "test-image",
 			mockSetup: func(m *mocks.MockDockerImageClient) {},
 			credentials: &mocks.MockRegistryCredentials{
-				GetCredentialsFunc: func() (string, error) {
+				GetCredentialsFunc: func(string) (string, error) {
 					return "", errors.New("error getting credentials")
 				},
 			},
@@ -64,7 +70,7 @@ func TestDockerImageManager_PullImage(t *testing.T) {
 				credentials = tt.credentials
 			}
 
-			imageManager := devcontainer.NewImageManager(mockClient, random.FixedString, credentials)
+			imageManager := devcontainer.NewImageManager(mockClient, credentials)
 
 			err := imageManager.PullImage(context.Background(), tt.imageName)
 
@@ -97,11 +103,11 @@ func TestDockerImageManager_BuildImage(t *testing.T) {
 				},
 			},
 			mockSetup: func(m *mocks.MockDockerImageClient) {
-				m.On("ImageBuild", mock.Anything, mock.AnythingOfType("*io.PipeReader"), mock.MatchedBy(func(options types.ImageBuildOptions) bool {
-					return options.Dockerfile == "Dockerfile" && len(options.Tags) > 0 && options.Tags[0] == "test:latest"
+				m.On("ImageBuild", mock.Anything, mock.Anything, mock.MatchedBy(func(options types.ImageBuildOptions) bool {
+					return options.Dockerfile == "Dockerfile" && len(options.Tags) > 0 && strings.HasPrefix(options.Tags[0], "devcontainer:")
 				})).Return(types.ImageBuildResponse{Body: io.NopCloser(bytes.NewReader([]byte{}))}, nil)
 			},
-			expectedResult: "test:latest",
+			expectedResult: "devcontainer:",
 		},
 		{
 			name: "Build image with name",
@@ -115,11 +121,11 @@ func TestDockerImageManager_BuildImage(t *testing.T) {
 				},
 			},
 			mockSetup: func(m *mocks.MockDockerImageClient) {
-				m.On("ImageBuild", mock.Anything, mock.AnythingOfType("*io.PipeReader"), mock.MatchedBy(func(options types.ImageBuildOptions) bool {
-					return options.Dockerfile == "Dockerfile" && len(options.Tags) > 0 && options.Tags[0] == "test-container-test:latest"
+				m.On("ImageBuild", mock.Anything, mock.Anything, mock.MatchedBy(func(options types.ImageBuildOptions) bool {
+					return options.Dockerfile == "Dockerfile" && len(options.Tags) > 0 && strings.HasPrefix(options.Tags[0], "test-container:")
 				})).Return(types.ImageBuildResponse{Body: io.NopCloser(bytes.NewReader([]byte{}))}, nil)
 			},
-			expectedResult: "test-container-test:latest",
+			expectedResult: "test-container:",
 		},
 		{
 			name: "Build image from build props",
@@ -132,11 +138,11 @@ func TestDockerImageManager_BuildImage(t *testing.T) {
 				},
 			},
 			mockSetup: func(m *mocks.MockDockerImageClient) {
-				m.On("ImageBuild", mock.Anything, mock.AnythingOfType("*io.PipeReader"), mock.MatchedBy(func(options types.ImageBuildOptions) bool {
-					return options.Dockerfile == "Dockerfile" && len(options.Tags) > 0 && options.Tags[0] == "test:latest"
+				m.On("ImageBuild", mock.Anything, mock.Anything, mock.MatchedBy(func(options types.ImageBuildOptions) bool {
+					return options.Dockerfile == "Dockerfile" && len(options.Tags) > 0 && strings.HasPrefix(options.Tags[0], "devcontainer:")
 				})).Return(types.ImageBuildResponse{Body: io.NopCloser(bytes.NewReader([]byte{}))}, nil)
 			},
-			expectedResult: "test:latest",
+			expectedResult: "devcontainer:",
 		},
 		{
 			name:          "Error when Dockerfile not found",
@@ -153,7 +159,7 @@ func TestDockerImageManager_BuildImage(t *testing.T) {
 				},
 			},
 			mockSetup: func(m *mocks.MockDockerImageClient) {
-				m.On("ImageBuild", mock.Anything, mock.AnythingOfType("*io.PipeReader"), mock.AnythingOfType("types.ImageBuildOptions")).
+				m.On("ImageBuild", mock.Anything, mock.Anything, mock.AnythingOfType("types.ImageBuildOptions")).
 					Return(types.ImageBuildResponse{}, errors.New("error building image"))
 			},
 			expectedError: "Failed to build Docker image",
@@ -173,13 +179,13 @@ func TestDockerImageManager_BuildImage(t *testing.T) {
 				},
 			},
 			mockSetup: func(m *mocks.MockDockerImageClient) {
-				m.On("ImageBuild", mock.Anything, mock.AnythingOfType("*io.PipeReader"), mock.MatchedBy(func(options types.ImageBuildOptions) bool {
+				m.On("ImageBuild", mock.Anything, mock.Anything, mock.MatchedBy(func(options types.ImageBuildOptions) bool {
 					return options.Dockerfile == "Dockerfile" &&
 						*options.BuildArgs["ARG1"] == "value1" &&
 						*options.BuildArgs["ARG2"] == "value2"
 				})).Return(types.ImageBuildResponse{Body: io.NopCloser(bytes.NewReader([]byte{}))}, nil)
 			},
-			expectedResult: "test:latest",
+			expectedResult: "devcontainer:",
 		},
 		{
 			name: "Build image with custom cacheFrom",
@@ -193,14 +199,14 @@ func TestDockerImageManager_BuildImage(t *testing.T) {
 				},
 			},
 			mockSetup: func(m *mocks.MockDockerImageClient) {
-				m.On("ImageBuild", mock.Anything, mock.AnythingOfType("*io.PipeReader"), mock.MatchedBy(func(options types.ImageBuildOptions) bool {
+				m.On("ImageBuild", mock.Anything, mock.Anything, mock.MatchedBy(func(options types.ImageBuildOptions) bool {
 					return options.Dockerfile == "Dockerfile" &&
 						len(options.CacheFrom) == 2 &&
 						options.CacheFrom[0] == "cache1" &&
 						options.CacheFrom[1] == "cache2"
 				})).Return(types.ImageBuildResponse{Body: io.NopCloser(bytes.NewReader([]byte{}))}, nil)
 			},
-			expectedResult: "test:latest",
+			expectedResult: "devcontainer:",
 		},
 		{
 			name: "Build image with custom target",
@@ -214,12 +220,25 @@ func TestDockerImageManager_BuildImage(t *testing.T) {
 				},
 			},
 			mockSetup: func(m *mocks.MockDockerImageClient) {
-				m.On("ImageBuild", mock.Anything, mock.AnythingOfType("*io.PipeReader"), mock.MatchedBy(func(options types.ImageBuildOptions) bool {
+				m.On("ImageBuild", mock.Anything, mock.Anything, mock.MatchedBy(func(options types.ImageBuildOptions) bool {
 					return options.Dockerfile == "Dockerfile" &&
 						options.Target == "custom-target"
 				})).Return(types.ImageBuildResponse{Body: io.NopCloser(bytes.NewReader([]byte{}))}, nil)
 			},
-			expectedResult: "test:latest",
+			expectedResult: "devcontainer:",
+		},
+		{
+			name: "Error reported in the build output",
+			config: devcontainer.Config{
+				DockerImageProps: devcontainer.DockerImageProps{
+					Dockerfile: "Dockerfile",
+				},
+			},
+			mockSetup: func(m *mocks.MockDockerImageClient) {
+				body := `{"stream":"Step 1/1 : FROM ubuntu\n"}` + "\n" + `{"errorDetail":{"message":"pull access denied"},"error":"pull access denied"}`
+				m.On("ImageBuild", mock.Anything, mock.Anything, mock.Anything).Return(types.ImageBuildResponse{Body: io.NopCloser(strings.NewReader(body))}, nil)
+			},
+			expectedError: "Failed to build Docker image: pull access denied",
 		},
 		{
 			name: "Build image with non-default docker context",
@@ -230,22 +249,27 @@ func TestDockerImageManager_BuildImage(t *testing.T) {
 				},
 			},
 			mockSetup: func(m *mocks.MockDockerImageClient) {
-				m.On("ImageBuild", mock.Anything, mock.AnythingOfType("*io.PipeReader"), mock.MatchedBy(func(options types.ImageBuildOptions) bool {
+				m.On("ImageBuild", mock.Anything, mock.Anything, mock.MatchedBy(func(options types.ImageBuildOptions) bool {
 					return options.Dockerfile == "workdir/Dockerfile"
 				})).Return(types.ImageBuildResponse{Body: io.NopCloser(bytes.NewReader([]byte{}))}, nil)
 			},
-			expectedResult: "test:latest",
+			expectedResult: "devcontainer:",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			workdir := filepath.Join(t.TempDir(), "workdir")
+			require.NoError(t, os.MkdirAll(workdir, 0o755))
+			require.NoError(t, os.WriteFile(filepath.Join(workdir, "Dockerfile"), []byte("FROM ubuntu\n"), 0o644))
+
 			mockClient := &mocks.MockDockerImageClient{}
+			mockClient.On("ImageList", mock.Anything, mock.Anything).Return([]image.Summary{}, nil).Maybe()
 			tt.mockSetup(mockClient)
 
-			imageManager := devcontainer.NewImageManager(mockClient, random.FixedString, nil)
+			imageManager := devcontainer.NewImageManager(mockClient, &mocks.MockRegistryCredentials{})
 
-			result, err := imageManager.BuildImage(context.Background(), "/workdir", tt.config)
+			result, err := imageManager.BuildImage(context.Background(), workdir, tt.config)
 
 			if tt.expectedError != "" {
 				assert.Error(t, err)
@@ -259,6 +283,100 @@ func TestDockerImageManager_BuildImage(t *testing.T) {
 		})
 	}
 }
+func TestDockerImageManager_BuildImageCache(t *testing.T) {
+	workdir := t.TempDir()
+	writeFile := func(name, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(workdir, name)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(workdir, name), []byte(content), 0o644))
+	}
+	writeFile("Dockerfile", "FROM ubuntu\nCOPY . /src\n")
+	writeFile(".dockerignore", "node_modules\n# comment\n*.log\n")
+	writeFile("main.go", "package main\n")
+
+	config := devcontainer.Config{
+		DockerImageProps:  devcontainer.DockerImageProps{Dockerfile: "Dockerfile"},
+		GeneralProperties: devcontainer.GeneralProperties{Name: "My Project"},
+	}
+
+	// builds reuse the image with the tag of the build inputs
+	var built []string
+	mockClient := &mocks.MockDockerImageClient{}
+	mockClient.On("ImageList", mock.Anything, mock.MatchedBy(func(options image.ListOptions) bool {
+		for _, tag := range built {
+			if options.Filters.ExactMatch("reference", tag) {
+				return true
+			}
+		}
+		return false
+	})).Return([]image.Summary{{ID: "sha256:built"}}, nil)
+	mockClient.On("ImageList", mock.Anything, mock.Anything).Return([]image.Summary{}, nil)
+
+	authConfigs := map[string]registry.AuthConfig{"ghcr.io": {Username: "user", Password: "token", ServerAddress: "ghcr.io"}}
+	credentials := &mocks.MockRegistryCredentials{
+		GetAllCredentialsFunc: func() (map[string]registry.AuthConfig, error) { return authConfigs, nil },
+	}
+
+	recordBuild := func(args mock.Arguments) {
+		options := args.Get(2).(types.ImageBuildOptions)
+		assert.Equal(t, strings.Split(options.Tags[0], ":")[1], options.Labels[devcontainer.ImageBuildHashLabel])
+		assert.Equal(t, authConfigs, options.AuthConfigs)
+		built = append(built, options.Tags[0])
+	}
+	body := `{"stream":"Step 1/2 : FROM ubuntu\n"}` + "\n" + `{"status":"Downloading","progress":"[==>   ]","id":"abc"}`
+	mockClient.On("ImageBuild", mock.Anything, mock.Anything, mock.Anything).Run(recordBuild).
+		Return(types.ImageBuildResponse{Body: io.NopCloser(strings.NewReader(body))}, nil).Once()
+	mockClient.On("ImageBuild", mock.Anything, mock.Anything, mock.Anything).Run(recordBuild).
+		Return(types.ImageBuildResponse{Body: io.NopCloser(strings.NewReader(""))}, nil)
+
+	var output bytes.Buffer
+	imageManager := devcontainer.NewImageManager(mockClient, credentials, devcontainer.ImageManagerWithOutput(&output))
+	build := func() string {
+		tag, err := imageManager.BuildImage(context.Background(), workdir, config)
+		require.NoError(t, err)
+		return tag
+	}
+
+	tag := build()
+	assert.Regexp(t, `^my-project:[0-9a-f]{12}$`, tag)
+	assert.Equal(t, "Step 1/2 : FROM ubuntu\nabc: Downloading [==>   ]\n", output.String())
+
+	// ignored files do not change the image
+	writeFile("node_modules/left-pad/index.js", "module.exports = {}\n")
+	writeFile("build.log", "ok\n")
+	assert.Equal(t, tag, build())
+	assert.Len(t, built, 1)
+
+	// changed files do
+	writeFile("main.go", "package main\n\nfunc main() {}\n")
+	changed := build()
+	assert.NotEqual(t, tag, changed)
+	assert.Equal(t, []string{tag, changed}, built)
+
+	// and so do build args
+	value := "1"
+	config.Build = &devcontainer.BuildProps{Args: map[string]*string{"VERSION": &value}}
+	assert.NotEqual(t, changed, build())
+	assert.Len(t, built, 3)
+}
+
+func TestDockerImageManager_PruneImages(t *testing.T) {
+	mockClient := &mocks.MockDockerImageClient{}
+	mockClient.On("ImagesPrune", mock.Anything, filters.NewArgs(
+		filters.Arg("dangling", "false"),
+		filters.Arg("label", devcontainer.ImageBuildHashLabel),
+		filters.Arg("until", "168h0m0s"),
+	)).Return(types.ImagesPruneReport{
+		ImagesDeleted:  []image.DeleteResponse{{Untagged: "my-project:0123456789ab"}, {Deleted: "sha256:old"}},
+		SpaceReclaimed: 1024,
+	}, nil)
+
+	removed, reclaimed, err := devcontainer.NewImageManager(mockClient, nil).PruneImages(context.Background(), 7*24*time.Hour)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sha256:old"}, removed)
+	assert.Equal(t, uint64(1024), reclaimed)
+	mockClient.AssertExpectations(t)
+}
 
 func TestDockerImageManager_LocalImageExists(t *testing.T) {
 	tests := []struct {
@@ -303,7 +421,7 @@ func TestDockerImageManager_LocalImageExists(t *testing.T) {
 			mockClient := &mocks.MockDockerImageClient{}
 			tt.mockSetup(mockClient)
 
-			imageManager := devcontainer.NewImageManager(mockClient, random.String, nil)
+			imageManager := devcontainer.NewImageManager(mockClient, nil)
 
 			result, err := imageManager.LocalImageExists(context.Background(), tt.image)
 
@@ -0,0 +1,246 @@
+package devcontainer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// LifecycleStage is a lifecycle command of devcontainer.json, named as the property, e.g. "postCreateCommand".
+type LifecycleStage string
+
+const (
+	LifecycleInitialize    LifecycleStage = "initializeCommand"
+	LifecycleOnCreate      LifecycleStage = "onCreateCommand"
+	LifecycleUpdateContent LifecycleStage = "updateContentCommand"
+	LifecyclePostCreate    LifecycleStage = "postCreateCommand"
+	LifecyclePostStart     LifecycleStage = "postStartCommand"
+	LifecyclePostAttach    LifecycleStage = "postAttachCommand"
+)
+
+// lifecycleStages are the stages in the order they run. All but initializeCommand run in the container.
+var lifecycleStages = []LifecycleStage{
+	LifecycleInitialize,
+	LifecycleOnCreate,
+	LifecycleUpdateContent,
+	LifecyclePostCreate,
+	LifecyclePostStart,
+	LifecyclePostAttach,
+}
+
+func (l *LifecycleProps) command(stage LifecycleStage) LifecycleCommand {
+	switch stage {
+	case LifecycleInitialize:
+		return l.InitializeCommand
+	case LifecycleOnCreate:
+		return l.OnCreateCommand
+	case LifecycleUpdateContent:
+		return l.UpdateContentCommand
+	case LifecyclePostCreate:
+		return l.PostCreateCommand
+	case LifecyclePostStart:
+		return l.PostStartCommand
+	case LifecyclePostAttach:
+		return l.PostAttachCommand
+	default:
+		return nil
+	}
+}
+
+// waitForStage returns the stage the devcontainer is ready after, updateContentCommand by default.
+func (l *LifecycleProps) waitForStage() (LifecycleStage, error) {
+	if l.WaitFor == "" {
+		return LifecycleUpdateContent, nil
+	}
+
+	stage := LifecycleStage(l.WaitFor)
+	if !slices.Contains(lifecycleStages, stage) {
+		return "", fmt.Errorf("Invalid waitFor %q, expected one of %v", l.WaitFor, lifecycleStages)
+	}
+
+	return stage, nil
+}
+
+type LifecycleCommandError struct {
+	Stage    LifecycleStage
+	Name     string
+	Command  []string
+	ExitCode int
+	Err      error
+}
+
+func (e LifecycleCommandError) Error() string {
+	name := string(e.Stage)
+	if e.Name != "" {
+		name = fmt.Sprintf("%s %s", e.Stage, e.Name)
+	}
+
+	if e.Err != nil {
+		return fmt.Sprintf("%s %s failed: %s", name, e.Command, e.Err)
+	}
+
+	return fmt.Sprintf("%s %s failed with exit code %d", name, e.Command, e.ExitCode)
+}
+
+func (e LifecycleCommandError) Unwrap() error {
+	return e.Err
+}
+
+func NewLifecycleCommandError(stage LifecycleStage, name string, command []string, exitCode int, err error) *LifecycleCommandError {
+	return &LifecycleCommandError{Stage: stage, Name: name, Command: command, ExitCode: exitCode, Err: err}
+}
+
+// LifecycleLog is the output of a lifecycle command. FinishedAt is zero while the command is running.
+type LifecycleLog struct {
+	Stage      LifecycleStage `json:"stage"`
+	Name       string         `json:"name,omitempty"`
+	Command    []string       `json:"command"`
+	Output     string         `json:"output"`
+	ExitCode   int            `json:"exitCode"`
+	Error      string         `json:"error,omitempty"`
+	StartedAt  time.Time      `json:"startedAt"`
+	FinishedAt time.Time      `json:"finishedAt,omitempty"`
+}
+
+// lifecycleLogs are the logs of the lifecycle commands of a container, in the order the commands started.
+type lifecycleLogs struct {
+	mu   sync.Mutex
+	logs []*LifecycleLog
+}
+
+func (l *lifecycleLogs) start(stage LifecycleStage, name string, command []string) *LifecycleLog {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry := &LifecycleLog{Stage: stage, Name: name, Command: command, StartedAt: time.Now()}
+	l.logs = append(l.logs, entry)
+	return entry
+}
+
+func (l *lifecycleLogs) finish(entry *LifecycleLog, exitCode int, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry.ExitCode = exitCode
+	entry.FinishedAt = time.Now()
+	if err != nil {
+		entry.Error = err.Error()
+	}
+}
+
+func (l *lifecycleLogs) list() []LifecycleLog {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	logs := make([]LifecycleLog, 0, len(l.logs))
+	for _, entry := range l.logs {
+		logs = append(logs, *entry)
+	}
+	return logs
+}
+
+// writer returns a writer that stores the output in the log entry and streams it to the logger line by line. The
+// writer must be flushed once the command has finished.
+func (l *lifecycleLogs) writer(entry *LifecycleLog) *lifecycleLogWriter {
+	return &lifecycleLogWriter{logs: l, entry: entry}
+}
+
+type lifecycleLogWriter struct {
+	logs  *lifecycleLogs
+	entry *LifecycleLog
+	line  bytes.Buffer
+}
+
+func (w *lifecycleLogWriter) Write(p []byte) (int, error) {
+	w.logs.mu.Lock()
+	w.entry.Output += string(p)
+	w.logs.mu.Unlock()
+
+	w.line.Write(p)
+	for {
+		line, err := w.line.ReadString('\n')
+		if err != nil {
+			// keep the incomplete line for the next write
+			w.line.Reset()
+			w.line.WriteString(line)
+			break
+		}
+
+		w.logLine(strings.TrimSuffix(line, "\n"))
+	}
+
+	return len(p), nil
+}
+
+// flush logs the last line of the output if it does not end with a newline.
+func (w *lifecycleLogWriter) flush() {
+	if w.line.Len() > 0 {
+		w.logLine(w.line.String())
+		w.line.Reset()
+	}
+}
+
+func (w *lifecycleLogWriter) logLine(line string) {
+	log.Info().Str("stage", string(w.entry.Stage)).Str("name", w.entry.Name).Msg(line)
+}
+
+// runLifecycleStage runs the commands of the stage on the host, or in the container if containerId is set. Named
+// commands run in parallel, the stage fails if any of them fails.
+func (r *DockerRunner) runLifecycleStage(ctx context.Context, stage LifecycleStage, command LifecycleCommand, projectPath, containerId string, logs *lifecycleLogs) error {
+	names := make([]string, 0, len(command))
+	for name := range command {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = r.runLifecycleCommand(ctx, stage, name, command[name], projectPath, containerId, logs)
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+func (r *DockerRunner) runLifecycleCommand(ctx context.Context, stage LifecycleStage, name string, command []string, projectPath, containerId string, logs *lifecycleLogs) error {
+	log.Debug().Str("stage", string(stage)).Str("name", name).Strs("command", command).Msg("Running lifecycle command")
+
+	entry := logs.start(stage, name, command)
+	output := logs.writer(entry)
+
+	var exitCode int
+	var err error
+	if containerId == "" {
+		err = r.commandExecutor.Run(command, projectPath, output, output)
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitCode, err = exitErr.ExitCode(), nil
+		}
+	} else {
+		exitCode, err = r.containerManager.ExecStream(ctx, containerId, command, output, output, r.execOptions(containerId)...)
+	}
+
+	output.flush()
+	logs.finish(entry, exitCode, err)
+
+	if err != nil || exitCode != 0 {
+		return NewLifecycleCommandError(stage, name, command, exitCode, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package devcontainer_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
+	"github.com/hide-org/hide/pkg/devcontainer/v2/mocks"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func lifecycleConfig(lifecycle devcontainer.LifecycleProps) devcontainer.Config {
+	return devcontainer.Config{
+		DockerImageProps: devcontainer.DockerImageProps{Image: "test-image"},
+		LifecycleProps:   lifecycle,
+	}
+}
+
+func startedContainer(mim *mocks.MockImageManager, mcm *mocks.MockContainerManager) {
+	mim.On("LocalImageExists", mock.Anything, "test-image").Return(true, nil)
+	mcm.On("CreateContainer", mock.Anything, "test-image", mock.Anything, mock.Anything).Return("container-id", nil)
+	mcm.On("StartContainer", mock.Anything, "container-id").Return(nil)
+	probedUserEnv(mcm, "").Maybe()
+	noImageMetadata(mim)
+}
+
+func writeOutput(output string) func(mock.Arguments) {
+	return func(args mock.Arguments) {
+		args.Get(3).(io.Writer).Write([]byte(output))
+	}
+}
+
+func stages(logs []devcontainer.LifecycleLog) []devcontainer.LifecycleStage {
+	stages := make([]devcontainer.LifecycleStage, 0, len(logs))
+	for _, l := range logs {
+		stages = append(stages, l.Stage)
+	}
+	return stages
+}
+
+func TestDockerRunner_RunLifecycle(t *testing.T) {
+	t.Run("Runs commands after initializeCommand in the container", func(t *testing.T) {
+		mockExecutor := &mocks.MockExecutor{}
+		mockImageManager := &mocks.MockImageManager{}
+		mockContainerManager := &mocks.MockContainerManager{}
+		startedContainer(mockImageManager, mockContainerManager)
+
+		mockExecutor.On("Run", []string{"initialize"}, "/test/project", mock.Anything, mock.Anything).Return(nil)
+		for _, command := range []string{"onCreate", "updateContent", "postCreate", "postStart", "postAttach"} {
+			mockContainerManager.On("ExecStream", mock.Anything, "container-id", []string{command}, mock.Anything, mock.Anything).Run(writeOutput(command+" done\n")).Return(0, nil).Once()
+		}
+
+		runner := devcontainer.NewDockerRunner(mockExecutor, mockImageManager, mockContainerManager)
+		containerId, err := runner.Run(context.Background(), "/test/project", lifecycleConfig(devcontainer.LifecycleProps{
+			InitializeCommand:    devcontainer.LifecycleCommand{"": []string{"initialize"}},
+			OnCreateCommand:      devcontainer.LifecycleCommand{"": []string{"onCreate"}},
+			UpdateContentCommand: devcontainer.LifecycleCommand{"": []string{"updateContent"}},
+			PostCreateCommand:    devcontainer.LifecycleCommand{"": []string{"postCreate"}},
+			PostStartCommand:     devcontainer.LifecycleCommand{"": []string{"postStart"}},
+			PostAttachCommand:    devcontainer.LifecycleCommand{"": []string{"postAttach"}},
+			WaitFor:              "postAttachCommand",
+		}))
+		require.NoError(t, err)
+
+		logs := runner.LifecycleLogs(containerId)
+		assert.Equal(t, []devcontainer.LifecycleStage{
+			devcontainer.LifecycleInitialize,
+			devcontainer.LifecycleOnCreate,
+			devcontainer.LifecycleUpdateContent,
+			devcontainer.LifecyclePostCreate,
+			devcontainer.LifecyclePostStart,
+			devcontainer.LifecyclePostAttach,
+		}, stages(logs))
+		assert.Equal(t, "postAttach done\n", logs[5].Output)
+		assert.False(t, logs[5].FinishedAt.IsZero())
+
+		mockExecutor.AssertExpectations(t)
+		mockContainerManager.AssertExpectations(t)
+	})
+
+	t.Run("Returns after waitFor and runs the rest in the background", func(t *testing.T) {
+		mockImageManager := &mocks.MockImageManager{}
+		mockContainerManager := &mocks.MockContainerManager{}
+		startedContainer(mockImageManager, mockContainerManager)
+
+		release := make(chan struct{})
+		mockContainerManager.On("ExecStream", mock.Anything, "container-id", []string{"updateContent"}, mock.Anything, mock.Anything).Return(0, nil)
+		mockContainerManager.On("ExecStream", mock.Anything, "container-id", []string{"postCreate"}, mock.Anything, mock.Anything).
+			Run(func(mock.Arguments) { <-release }).Return(0, nil)
+
+		runner := devcontainer.NewDockerRunner(&mocks.MockExecutor{}, mockImageManager, mockContainerManager)
+		containerId, err := runner.Run(context.Background(), "/test/project", lifecycleConfig(devcontainer.LifecycleProps{
+			UpdateContentCommand: devcontainer.LifecycleCommand{"": []string{"updateContent"}},
+			PostCreateCommand:    devcontainer.LifecycleCommand{"": []string{"postCreate"}},
+		}))
+		require.NoError(t, err)
+
+		// postCreateCommand is still running
+		assert.Eventually(t, func() bool { return len(runner.LifecycleLogs(containerId)) == 2 }, time.Second, 10*time.Millisecond)
+		assert.True(t, runner.LifecycleLogs(containerId)[1].FinishedAt.IsZero())
+
+		close(release)
+		assert.Eventually(t, func() bool { return !runner.LifecycleLogs(containerId)[1].FinishedAt.IsZero() }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Runs named commands in parallel", func(t *testing.T) {
+		mockImageManager := &mocks.MockImageManager{}
+		mockContainerManager := &mocks.MockContainerManager{}
+		startedContainer(mockImageManager, mockContainerManager)
+
+		// each command waits until both have started
+		var started sync.WaitGroup
+		started.Add(2)
+		barrier := func(mock.Arguments) {
+			started.Done()
+			started.Wait()
+		}
+		mockContainerManager.On("ExecStream", mock.Anything, "container-id", []string{"npm", "install"}, mock.Anything, mock.Anything).Run(barrier).Return(0, nil)
+		mockContainerManager.On("ExecStream", mock.Anything, "container-id", []string{"go", "mod", "download"}, mock.Anything, mock.Anything).Run(barrier).Return(0, nil)
+
+		runner := devcontainer.NewDockerRunner(&mocks.MockExecutor{}, mockImageManager, mockContainerManager)
+
+		done := make(chan error)
+		go func() {
+			_, err := runner.Run(context.Background(), "/test/project", lifecycleConfig(devcontainer.LifecycleProps{
+				OnCreateCommand: devcontainer.LifecycleCommand{"npm": []string{"npm", "install"}, "go": []string{"go", "mod", "download"}},
+			}))
+			done <- err
+		}()
+
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("named commands did not run in parallel")
+		}
+	})
+
+	t.Run("Reports each failed command", func(t *testing.T) {
+		mockImageManager := &mocks.MockImageManager{}
+		mockContainerManager := &mocks.MockContainerManager{}
+		startedContainer(mockImageManager, mockContainerManager)
+
+		mockContainerManager.On("ExecStream", mock.Anything, "container-id", []string{"ok"}, mock.Anything, mock.Anything).Return(0, nil)
+		mockContainerManager.On("ExecStream", mock.Anything, "container-id", []string{"lint"}, mock.Anything, mock.Anything).Run(writeOutput("lint failed\n")).Return(2, nil)
+		mockContainerManager.On("ExecStream", mock.Anything, "container-id", []string{"test"}, mock.Anything, mock.Anything).Return(0, errors.New("connection lost"))
+		mockContainerManager.On("StopContainer", mock.Anything, "container-id").Return(nil)
+
+		runner := devcontainer.NewDockerRunner(&mocks.MockExecutor{}, mockImageManager, mockContainerManager)
+		_, err := runner.Run(context.Background(), "/test/project", lifecycleConfig(devcontainer.LifecycleProps{
+			PostCreateCommand: devcontainer.LifecycleCommand{"ok": []string{"ok"}, "lint": []string{"lint"}, "test": []string{"test"}},
+			WaitFor:           "postCreateCommand",
+		}))
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "Failed to run postCreateCommand")
+		assert.ErrorContains(t, err, "postCreateCommand lint [lint] failed with exit code 2")
+		assert.ErrorContains(t, err, "postCreateCommand test [test] failed: connection lost")
+		assert.NotContains(t, err.Error(), "ok [ok]")
+
+		var commandErr *devcontainer.LifecycleCommandError
+		require.ErrorAs(t, err, &commandErr)
+		assert.Equal(t, "lint", commandErr.Name)
+		assert.Equal(t, 2, commandErr.ExitCode)
+
+		// the container is stopped and forgotten
+		assert.Nil(t, runner.LifecycleLogs("container-id"))
+		mockContainerManager.AssertExpectations(t)
+	})
+
+	t.Run("Logs the last line without a newline", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.Logger
+		log.Logger = zerolog.New(&buf)
+		defer func() { log.Logger = logger }()
+
+		mockImageManager := &mocks.MockImageManager{}
+		mockContainerManager := &mocks.MockContainerManager{}
+		startedContainer(mockImageManager, mockContainerManager)
+		mockContainerManager.On("ExecStream", mock.Anything, "container-id", []string{"setup"}, mock.Anything, mock.Anything).Run(writeOutput("installing\ndone")).Return(0, nil)
+
+		runner := devcontainer.NewDockerRunner(&mocks.MockExecutor{}, mockImageManager, mockContainerManager)
+		_, err := runner.Run(context.Background(), "/test/project", lifecycleConfig(devcontainer.LifecycleProps{
+			OnCreateCommand: devcontainer.LifecycleCommand{"": []string{"setup"}},
+		}))
+		require.NoError(t, err)
+
+		assert.Contains(t, buf.String(), `"message":"installing"`)
+		assert.Contains(t, buf.String(), `"message":"done"`)
+	})
+
+	t.Run("Invalid waitFor", func(t *testing.T) {
+		runner := devcontainer.NewDockerRunner(&mocks.MockExecutor{}, &mocks.MockImageManager{}, &mocks.MockContainerManager{})
+		_, err := runner.Run(context.Background(), "/test/project", lifecycleConfig(devcontainer.LifecycleProps{WaitFor: "postStop"}))
+		assert.ErrorContains(t, err, `Invalid waitFor "postStop"`)
+	})
+}
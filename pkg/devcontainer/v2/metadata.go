@@ -0,0 +1,199 @@
+package devcontainer
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/docker/go-units"
+)
+
+// ImageMetadataLabel is the image label with the devcontainer.json properties of the image and its features, set by
+// devcontainer tools when building images, e.g. the ones of mcr.microsoft.com/devcontainers.
+const ImageMetadataLabel = "devcontainer.metadata"
+
+// ImageMetadata is an entry of the devcontainer.metadata label, the subset of devcontainer.json properties that apply
+// to containers of the image.
+// NOTE: entrypoints of features are not supported, the container runs DefaultContainerCommand
+type ImageMetadata struct {
+	// The feature the entry belongs to, empty for entries of devcontainer.json.
+	ID string `json:"id,omitempty"`
+
+	Init                 bool                      `json:"init,omitempty"`
+	Privileged           bool                      `json:"privileged,omitempty"`
+	CapAdd               []string                  `json:"capAdd,omitempty"`
+	SecurityOpt          []string                  `json:"securityOpt,omitempty"`
+	Mounts               []Mount                   `json:"mounts,omitempty"`
+	ContainerEnv         map[string]string         `json:"containerEnv,omitempty"`
+	RemoteEnv            map[string]string         `json:"remoteEnv,omitempty"`
+	RemoteUser           string                    `json:"remoteUser,omitempty"`
+	ContainerUser        string                    `json:"containerUser,omitempty"`
+	UpdateRemoteUserUID  *bool                     `json:"updateRemoteUserUID,omitempty"`
+	UserEnvProbe         string                    `json:"userEnvProbe,omitempty"`
+	ShutdownAction       string                    `json:"shutdownAction,omitempty"`
+	ForwardPorts         ForwardPorts              `json:"forwardPorts,omitempty"`
+	PortsAttributes      map[string]PortAttributes `json:"portsAttributes,omitempty"`
+	OtherPortsAttributes PortAttributes            `json:"otherPortsAttributes,omitempty"`
+	HostRequirements     HostRequirements          `json:"hostRequirements,omitempty"`
+	Customizations       Customizations            `json:"customizations,omitempty"`
+
+	LifecycleProps
+}
+
+// parseImageMetadata parses the devcontainer.metadata label, an array of entries or a single one.
+func parseImageMetadata(label string) ([]ImageMetadata, error) {
+	if strings.HasPrefix(strings.TrimSpace(label), "{") {
+		var entry ImageMetadata
+		if err := json.Unmarshal([]byte(label), &entry); err != nil {
+			return nil, err
+		}
+		return []ImageMetadata{entry}, nil
+	}
+
+	var metadata []ImageMetadata
+	if err := json.Unmarshal([]byte(label), &metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// imageMetadata returns the properties of the config that are merged with the metadata of the image.
+func (c *Config) imageMetadata() ImageMetadata {
+	return ImageMetadata{
+		Init:                 c.Init,
+		Privileged:           c.Privileged,
+		CapAdd:               c.CapAdd,
+		SecurityOpt:          c.SecurityOpt,
+		Mounts:               c.Mounts,
+		ContainerEnv:         c.ContainerEnv,
+		RemoteEnv:            c.RemoteEnv,
+		RemoteUser:           c.RemoteUser,
+		ContainerUser:        c.ContainerUser,
+		UpdateRemoteUserUID:  c.UpdateRemoteUserUID,
+		UserEnvProbe:         c.UserEnvProbe,
+		ShutdownAction:       c.ShutdownAction,
+		ForwardPorts:         c.ForwardPorts,
+		PortsAttributes:      c.PortsAttributes,
+		OtherPortsAttributes: c.OtherPortsAttributes,
+		HostRequirements:     c.HostRequirements,
+		Customizations:       c.Customizations,
+		LifecycleProps:       c.LifecycleProps,
+	}
+}
+
+// mergeImageMetadata returns the config merged with the metadata of its image by the merge rules of the spec. The
+// config is merged last, so that its values win: booleans are true if any entry sets them, lists are combined, maps
+// are combined per key, mounts per target, host requirements take the maximum and other values the last one set.
+// The metadata is kept in the config for its lifecycle commands, which run before the ones of the config.
+func (c *Config) mergeImageMetadata(metadata []ImageMetadata) Config {
+	merged := *c
+	if len(metadata) == 0 {
+		return merged
+	}
+
+	merged.ImageMetadata = metadata
+	merged.Init, merged.Privileged = false, false
+	merged.CapAdd, merged.SecurityOpt, merged.Mounts, merged.ForwardPorts = nil, nil, nil, nil
+	merged.ContainerEnv, merged.RemoteEnv, merged.PortsAttributes = nil, nil, nil
+	merged.HostRequirements = HostRequirements{}
+	merged.Customizations = Customizations{}
+
+	for _, entry := range append(slices.Clone(metadata), c.imageMetadata()) {
+		merged.Init = merged.Init || entry.Init
+		merged.Privileged = merged.Privileged || entry.Privileged
+		merged.CapAdd = appendUnique(merged.CapAdd, entry.CapAdd...)
+		merged.SecurityOpt = appendUnique(merged.SecurityOpt, entry.SecurityOpt...)
+		merged.ForwardPorts = appendUnique(merged.ForwardPorts, entry.ForwardPorts...)
+
+		for _, mount := range entry.Mounts {
+			merged.Mounts = slices.DeleteFunc(merged.Mounts, func(m Mount) bool { return m.Destination == mount.Destination })
+			merged.Mounts = append(merged.Mounts, mount)
+		}
+
+		merged.ContainerEnv = mergeMaps(merged.ContainerEnv, entry.ContainerEnv)
+		merged.RemoteEnv = mergeMaps(merged.RemoteEnv, entry.RemoteEnv)
+		merged.PortsAttributes = mergeMaps(merged.PortsAttributes, entry.PortsAttributes)
+
+		merged.RemoteUser = lastSet(merged.RemoteUser, entry.RemoteUser)
+		merged.ContainerUser = lastSet(merged.ContainerUser, entry.ContainerUser)
+		merged.UpdateRemoteUserUID = lastSet(merged.UpdateRemoteUserUID, entry.UpdateRemoteUserUID)
+		merged.UserEnvProbe = lastSet(merged.UserEnvProbe, entry.UserEnvProbe)
+		merged.ShutdownAction = lastSet(merged.ShutdownAction, entry.ShutdownAction)
+		merged.OtherPortsAttributes = lastSet(merged.OtherPortsAttributes, entry.OtherPortsAttributes)
+		merged.WaitFor = lastSet(merged.WaitFor, entry.WaitFor)
+
+		merged.HostRequirements.Cpus = max(merged.HostRequirements.Cpus, entry.HostRequirements.Cpus)
+		merged.HostRequirements.Memory = maxSize(merged.HostRequirements.Memory, entry.HostRequirements.Memory)
+		merged.HostRequirements.Storage = maxSize(merged.HostRequirements.Storage, entry.HostRequirements.Storage)
+
+		if hide := entry.Customizations.Hide; hide != nil {
+			if merged.Customizations.Hide == nil {
+				merged.Customizations.Hide = &HideCustomization{}
+			}
+			merged.Customizations.Hide.LanguageServers = mergeMaps(merged.Customizations.Hide.LanguageServers, hide.LanguageServers)
+		}
+	}
+
+	return merged
+}
+
+// lifecycleCommands returns the commands of the stage, the ones of the image metadata first. initializeCommand runs on
+// the host and only comes from the config.
+func (c *Config) lifecycleCommands(stage LifecycleStage) []LifecycleCommand {
+	var commands []LifecycleCommand
+	if stage != LifecycleInitialize {
+		for _, entry := range c.ImageMetadata {
+			if command := entry.command(stage); command != nil {
+				commands = append(commands, command)
+			}
+		}
+	}
+
+	if command := c.LifecycleProps.command(stage); command != nil {
+		commands = append(commands, command)
+	}
+
+	return commands
+}
+
+func appendUnique[S ~[]E, E comparable](s S, values ...E) S {
+	for _, value := range values {
+		if !slices.Contains(s, value) {
+			s = append(s, value)
+		}
+	}
+	return s
+}
+
+func mergeMaps[M ~map[K]V, K comparable, V any](dst, src M) M {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(M, len(src))
+	}
+	maps.Copy(dst, src)
+	return dst
+}
+
+// lastSet returns value if it is set, current otherwise.
+func lastSet[T comparable](current, value T) T {
+	var zero T
+	if value != zero {
+		return value
+	}
+	return current
+}
+
+// maxSize returns the larger of two sizes like 4gb, a if b is not a valid size.
+func maxSize(a, b string) string {
+	bBytes, err := units.RAMInBytes(b)
+	if err != nil {
+		return a
+	}
+	if aBytes, err := units.RAMInBytes(a); err == nil && aBytes >= bBytes {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,143 @@
+package devcontainer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
+	"github.com/hide-org/hide/pkg/devcontainer/v2/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// noImageMetadata makes the images of the image manager have no devcontainer.metadata label.
+func noImageMetadata(mim *mocks.MockImageManager) *mock.Call {
+	return mim.On("ImageMetadata", mock.Anything, mock.Anything).Return([]devcontainer.ImageMetadata(nil), nil).Maybe()
+}
+
+func TestDockerImageManager_ImageMetadata(t *testing.T) {
+	tests := []struct {
+		name          string
+		labels        map[string]string
+		expected      []devcontainer.ImageMetadata
+		expectedError string
+	}{
+		{
+			name: "Array of entries",
+			labels: map[string]string{devcontainer.ImageMetadataLabel: `[
+				{"id": "ghcr.io/devcontainers/features/common-utils:2", "remoteEnv": {"EDITOR": "vi"}},
+				{"remoteUser": "vscode", "postCreateCommand": "echo created"}
+			]`},
+			expected: []devcontainer.ImageMetadata{
+				{ID: "ghcr.io/devcontainers/features/common-utils:2", RemoteEnv: map[string]string{"EDITOR": "vi"}},
+				{RemoteUser: "vscode", LifecycleProps: devcontainer.LifecycleProps{
+					PostCreateCommand: devcontainer.LifecycleCommand{"": []string{"/bin/sh", "-c", "echo created"}},
+				}},
+			},
+		},
+		{
+			name:     "Single entry",
+			labels:   map[string]string{devcontainer.ImageMetadataLabel: `{"capAdd": ["SYS_PTRACE"]}`},
+			expected: []devcontainer.ImageMetadata{{CapAdd: []string{"SYS_PTRACE"}}},
+		},
+		{
+			name:   "No label",
+			labels: map[string]string{"maintainer": "hide"},
+		},
+		{
+			name:          "Invalid label",
+			labels:        map[string]string{devcontainer.ImageMetadataLabel: `[{"remoteUser": 1}]`},
+			expectedError: "Invalid devcontainer.metadata label of image test-image",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mocks.MockDockerImageClient{}
+			mockClient.On("ImageInspectWithRaw", mock.Anything, "test-image").Return(types.ImageInspect{Config: &container.Config{Labels: tt.labels}}, []byte{}, nil)
+
+			metadata, err := devcontainer.NewImageManager(mockClient, nil).ImageMetadata(context.Background(), "test-image")
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, metadata)
+		})
+	}
+}
+
+func TestDockerRunner_ImageMetadata(t *testing.T) {
+	disabled := false
+	config := devcontainer.Config{
+		DockerImageProps: devcontainer.DockerImageProps{Image: "test-image"},
+		LifecycleProps: devcontainer.LifecycleProps{
+			PostCreateCommand: devcontainer.LifecycleCommand{"": []string{"make", "setup"}},
+		},
+		HostRequirements: devcontainer.HostRequirements{Cpus: 2, Memory: "4gb"},
+		GeneralProperties: devcontainer.GeneralProperties{
+			ContainerEnv:        map[string]string{"GOFLAGS": "-mod=mod", "LANG": "C.UTF-8"},
+			CapAdd:              []string{"NET_ADMIN"},
+			ForwardPorts:        devcontainer.ForwardPorts{"8080"},
+			Mounts:              []devcontainer.Mount{{Type: "volume", Source: "local-cache", Destination: "/cache"}},
+			UpdateRemoteUserUID: &disabled,
+			UserEnvProbe:        devcontainer.UserEnvProbeNone,
+		},
+	}
+
+	metadata := []devcontainer.ImageMetadata{
+		{
+			ID:           "ghcr.io/devcontainers/features/go:1",
+			CapAdd:       []string{"SYS_PTRACE"},
+			SecurityOpt:  []string{"seccomp=unconfined"},
+			ContainerEnv: map[string]string{"GOPATH": "/go", "LANG": "en_US.UTF-8"},
+			Mounts:       []devcontainer.Mount{{Type: "volume", Source: "image-cache", Destination: "/cache"}},
+		},
+		{
+			Init:             true,
+			RemoteUser:       "vscode",
+			ForwardPorts:     devcontainer.ForwardPorts{"3000", "8080"},
+			HostRequirements: devcontainer.HostRequirements{Cpus: 1, Memory: "8gb"},
+			LifecycleProps: devcontainer.LifecycleProps{
+				PostCreateCommand: devcontainer.LifecycleCommand{"": []string{"/bin/sh", "-c", "echo image"}},
+				WaitFor:           "postCreateCommand",
+			},
+		},
+	}
+
+	mockImageManager := &mocks.MockImageManager{}
+	mockImageManager.On("LocalImageExists", mock.Anything, "test-image").Return(true, nil)
+	mockImageManager.On("ImageMetadata", mock.Anything, "test-image").Return(metadata, nil)
+
+	var created devcontainer.Config
+	mockContainerManager := &mocks.MockContainerManager{}
+	mockContainerManager.On("CreateContainer", mock.Anything, "test-image", "/test/project", mock.Anything).
+		Run(func(args mock.Arguments) { created = args.Get(3).(devcontainer.Config) }).
+		Return("container-id", nil)
+	mockContainerManager.On("StartContainer", mock.Anything, "container-id").Return(nil)
+	options := devcontainer.ExecOptions{User: "vscode"}
+	imageCommand := mockContainerManager.On("ExecStream", mock.Anything, "container-id", []string{"/bin/sh", "-c", "echo image"}, mock.Anything, mock.Anything, options).Return(0, nil)
+	mockContainerManager.On("ExecStream", mock.Anything, "container-id", []string{"make", "setup"}, mock.Anything, mock.Anything, options).Return(0, nil).NotBefore(imageCommand)
+
+	runner := devcontainer.NewDockerRunner(&mocks.MockExecutor{}, mockImageManager, mockContainerManager)
+	containerId, err := runner.Run(context.Background(), "/test/project", config)
+	require.NoError(t, err)
+
+	assert.True(t, created.Init)
+	assert.Equal(t, "vscode", created.RemoteUser)
+	assert.Equal(t, []string{"SYS_PTRACE", "NET_ADMIN"}, created.CapAdd)
+	assert.Equal(t, []string{"seccomp=unconfined"}, created.SecurityOpt)
+	assert.Equal(t, devcontainer.ForwardPorts{"3000", "8080"}, created.ForwardPorts)
+	assert.Equal(t, map[string]string{"GOFLAGS": "-mod=mod", "GOPATH": "/go", "LANG": "C.UTF-8"}, created.ContainerEnv)
+	assert.Equal(t, []devcontainer.Mount{{Type: "volume", Source: "local-cache", Destination: "/cache"}}, created.Mounts)
+	assert.Equal(t, devcontainer.HostRequirements{Cpus: 2, Memory: "8gb"}, created.HostRequirements)
+	assert.Equal(t, "postCreateCommand", created.WaitFor)
+
+	// the metadata waits for postCreateCommand, which ran before Run returned
+	assert.Equal(t, []devcontainer.LifecycleStage{devcontainer.LifecyclePostCreate, devcontainer.LifecyclePostCreate}, stages(runner.LifecycleLogs(containerId)))
+	mockContainerManager.AssertExpectations(t)
+}
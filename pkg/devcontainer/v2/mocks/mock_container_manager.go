@@ -0,0 +1,74 @@
+package mocks
+
+import (
+	"context"
+	"io"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
+)
+
+var _ devcontainer.ContainerManager = (*MockContainerManager)(nil)
+
+type MockContainerManager struct {
+	mock.Mock
+}
+
+func (m *MockContainerManager) CreateContainer(ctx context.Context, image string, projectPath string, config devcontainer.Config) (string, error) {
+	args := m.Called(ctx, image, projectPath, config)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockContainerManager) StartContainer(ctx context.Context, containerId string) error {
+	args := m.Called(ctx, containerId)
+	return args.Error(0)
+}
+
+func (m *MockContainerManager) StopContainer(ctx context.Context, containerId string) error {
+	args := m.Called(ctx, containerId)
+	return args.Error(0)
+}
+
+// calledWith returns the arguments of a call, with the resolved ExecOptions appended if there are any.
+func calledWith(args []any, opts []devcontainer.ExecOption) []any {
+	if len(opts) > 0 {
+		args = append(args, devcontainer.NewExecOptions(opts...))
+	}
+	return args
+}
+
+func (m *MockContainerManager) Exec(ctx context.Context, containerId string, command []string, opts ...devcontainer.ExecOption) (devcontainer.ExecResult, error) {
+	args := m.Called(calledWith([]any{ctx, containerId, command}, opts)...)
+	return args.Get(0).(devcontainer.ExecResult), args.Error(1)
+}
+
+func (m *MockContainerManager) ExecDetached(ctx context.Context, containerId string, command []string, opts ...devcontainer.ExecOption) (string, error) {
+	args := m.Called(calledWith([]any{ctx, containerId, command}, opts)...)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockContainerManager) ExecStream(ctx context.Context, containerId string, command []string, stdout, stderr io.Writer, opts ...devcontainer.ExecOption) (int, error) {
+	args := m.Called(calledWith([]any{ctx, containerId, command, stdout, stderr}, opts)...)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockContainerManager) HostPort(ctx context.Context, containerId string, port int) (int, error) {
+	args := m.Called(ctx, containerId, port)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockContainerManager) PortMappings(ctx context.Context, containerId string, config devcontainer.Config) ([]devcontainer.PortMapping, error) {
+	args := m.Called(ctx, containerId, config)
+	return args.Get(0).([]devcontainer.PortMapping), args.Error(1)
+}
+
+func (m *MockContainerManager) ContainerEnv(ctx context.Context, containerId string) ([]string, error) {
+	args := m.Called(ctx, containerId)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockContainerManager) CopyServerBinary(ctx context.Context, containerId string) error {
+	args := m.Called(ctx, containerId)
+	return args.Error(0)
+}
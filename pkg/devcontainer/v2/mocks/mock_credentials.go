@@ -0,0 +1,23 @@
+package mocks
+
+import "github.com/docker/docker/api/types/registry"
+
+// MockRegistryCredentials is a mock of the RegistryCredentials interface for testing
+type MockRegistryCredentials struct {
+	GetCredentialsFunc    func(image string) (string, error)
+	GetAllCredentialsFunc func() (map[string]registry.AuthConfig, error)
+}
+
+func (m *MockRegistryCredentials) GetCredentials(image string) (string, error) {
+	if m.GetCredentialsFunc == nil {
+		return "", nil
+	}
+	return m.GetCredentialsFunc(image)
+}
+
+func (m *MockRegistryCredentials) GetAllCredentials() (map[string]registry.AuthConfig, error) {
+	if m.GetAllCredentialsFunc == nil {
+		return nil, nil
+	}
+	return m.GetAllCredentialsFunc()
+}
@@ -5,7 +5,7 @@ import (
 
 	"github.com/stretchr/testify/mock"
 
-	"github.com/hide-org/hide/pkg/devcontainer"
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
 )
 
 var _ devcontainer.Executor = (*MockExecutor)(nil)
@@ -0,0 +1,20 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
+)
+
+var _ devcontainer.FeatureResolver = (*MockFeatureResolver)(nil)
+
+type MockFeatureResolver struct {
+	mock.Mock
+}
+
+func (m *MockFeatureResolver) Resolve(ctx context.Context, projectPath string, config devcontainer.Config) ([]devcontainer.ResolvedFeature, error) {
+	args := m.Called(ctx, projectPath, config)
+	return args.Get(0).([]devcontainer.ResolvedFeature), args.Error(1)
+}
@@ -0,0 +1,51 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
+)
+
+var _ devcontainer.ImageManager = (*MockImageManager)(nil)
+
+type MockImageManager struct {
+	mock.Mock
+}
+
+func (m *MockImageManager) PullImage(ctx context.Context, name string) error {
+	args := m.Called(ctx, name)
+	return args.Error(0)
+}
+
+func (m *MockImageManager) BuildImage(ctx context.Context, workingDir string, config devcontainer.Config) (string, error) {
+	args := m.Called(ctx, workingDir, config)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockImageManager) LocalImageExists(ctx context.Context, name string) (bool, error) {
+	args := m.Called(ctx, name)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockImageManager) BuildFeaturesImage(ctx context.Context, baseImage string, features []devcontainer.ResolvedFeature, config devcontainer.Config) (string, error) {
+	args := m.Called(ctx, baseImage, features, config)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockImageManager) BuildUpdateUIDImage(ctx context.Context, baseImage string, user string, uid, gid int) (string, error) {
+	args := m.Called(ctx, baseImage, user, uid, gid)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockImageManager) PruneImages(ctx context.Context, olderThan time.Duration) ([]string, uint64, error) {
+	args := m.Called(ctx, olderThan)
+	return args.Get(0).([]string), args.Get(1).(uint64), args.Error(2)
+}
+
+func (m *MockImageManager) ImageMetadata(ctx context.Context, image string) ([]devcontainer.ImageMetadata, error) {
+	args := m.Called(ctx, image)
+	return args.Get(0).([]devcontainer.ImageMetadata), args.Error(1)
+}
@@ -0,0 +1,48 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
+)
+
+// MockDevContainerRunner is a mock of the devcontainer.Runner interface for testing
+type MockDevContainerRunner struct {
+	RunFunc  func(ctx context.Context, projectPath string, config devcontainer.Config) (string, error)
+	StopFunc func(ctx context.Context, containerId string) error
+	ExecFunc func(ctx context.Context, containerId string, command []string) (devcontainer.ExecResult, error)
+
+	ExecDetachedFunc func(ctx context.Context, containerId string, command []string) (string, error)
+
+	LifecycleLogsFunc func(containerId string) []devcontainer.LifecycleLog
+	HostPortFunc      func(ctx context.Context, containerId string, port int) (int, error)
+	PortMappingsFunc  func(ctx context.Context, containerId string, config devcontainer.Config) ([]devcontainer.PortMapping, error)
+}
+
+func (m *MockDevContainerRunner) Run(ctx context.Context, projectPath string, config devcontainer.Config) (string, error) {
+	return m.RunFunc(ctx, projectPath, config)
+}
+
+func (m *MockDevContainerRunner) Stop(ctx context.Context, containerId string) error {
+	return m.StopFunc(ctx, containerId)
+}
+
+func (m *MockDevContainerRunner) Exec(ctx context.Context, containerId string, command []string) (devcontainer.ExecResult, error) {
+	return m.ExecFunc(ctx, containerId, command)
+}
+
+func (m *MockDevContainerRunner) ExecDetached(ctx context.Context, containerId string, command []string) (string, error) {
+	return m.ExecDetachedFunc(ctx, containerId, command)
+}
+
+func (m *MockDevContainerRunner) LifecycleLogs(containerId string) []devcontainer.LifecycleLog {
+	return m.LifecycleLogsFunc(containerId)
+}
+
+func (m *MockDevContainerRunner) HostPort(ctx context.Context, containerId string, port int) (int, error) {
+	return m.HostPortFunc(ctx, containerId, port)
+}
+
+func (m *MockDevContainerRunner) PortMappings(ctx context.Context, containerId string, config devcontainer.Config) ([]devcontainer.PortMapping, error) {
+	return m.PortMappingsFunc(ctx, containerId, config)
+}
@@ -61,6 +61,8 @@ func FindConfig(fileSystem fs.FS) (File, error) {
 	return File{Path: matches[0], Content: content}, nil
 }
 
+// ParseConfig parses devcontainer.json. Variables like ${localEnv:HOME} are kept as they are, they are substituted by
+// DockerRunner.Run, which knows the project folder and the container.
 func ParseConfig(configFile File) (*Config, error) {
 	config := &Config{}
 	if err := json.Unmarshal(jsonc.ToJSON(configFile.Content), config); err != nil {
@@ -6,7 +6,7 @@ import (
 	"testing"
 	"testing/fstest"
 
-	"github.com/hide-org/hide/pkg/devcontainer"
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
 	"github.com/hide-org/hide/pkg/jsonc"
 )
 
@@ -236,6 +236,31 @@ func TestConfigWithCustomizations(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "hide with language servers",
+			content: devcontainer.File{Path: "config.json", Content: []byte(`{
+	"customizations": {
+		"hide": {
+			"languageServers": {
+				"gopls": {
+					"settings": {"staticcheck": true}
+				}
+			}
+		}
+	}
+}`)},
+			expected: &devcontainer.Config{
+				GeneralProperties: devcontainer.GeneralProperties{
+					Customizations: devcontainer.Customizations{
+						Hide: &devcontainer.HideCustomization{
+							LanguageServers: map[string]devcontainer.LanguageServerCustomization{
+								"gopls": {Settings: json.RawMessage(`{"staticcheck": true}`)},
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,207 @@
+package devcontainer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/rs/zerolog/log"
+)
+
+// PortMapping is a port of the container published on the host.
+type PortMapping struct {
+	ContainerPort int    `json:"containerPort"`
+	HostIP        string `json:"hostIP"`
+	HostPort      int    `json:"hostPort"`
+	Label         string `json:"label,omitempty"`
+	Protocol      string `json:"protocol,omitempty"`
+	// URL of the port on the host, e.g. http://127.0.0.1:49153
+	URL string `json:"url"`
+}
+
+// parseForwardPort parses a forwardPorts entry, a port number or "host:port".
+func parseForwardPort(value string) (string, int, error) {
+	host, portStr := "", value
+	if i := strings.LastIndex(value, ":"); i >= 0 {
+		host, portStr = value[:i], value[i+1:]
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port <= 0 || port > 65535 {
+		return "", 0, fmt.Errorf("Invalid forward port %q", value)
+	}
+
+	return host, port, nil
+}
+
+func isLocalHost(host string) bool {
+	return host == "" || host == "localhost" || host == "127.0.0.1"
+}
+
+// portAttributes returns the attributes of the first portsAttributes key matching the port, or otherPortsAttributes.
+// Keys are port numbers, "host:port" values, ranges like "40000-55000" or regular expressions.
+func (g *GeneralProperties) portAttributes(port int) PortAttributes {
+	keys := make([]string, 0, len(g.PortsAttributes))
+	for key := range g.PortsAttributes {
+		keys = append(keys, key)
+	}
+	// exact ports win over ranges and regular expressions
+	sort.SliceStable(keys, func(i, j int) bool {
+		_, errI := strconv.Atoi(keys[i])
+		_, errJ := strconv.Atoi(keys[j])
+		if (errI == nil) != (errJ == nil) {
+			return errI == nil
+		}
+		return keys[i] < keys[j]
+	})
+
+	for _, key := range keys {
+		if portMatches(key, port) {
+			return g.PortsAttributes[key]
+		}
+	}
+
+	return g.OtherPortsAttributes
+}
+
+func portMatches(key string, port int) bool {
+	if p, err := strconv.Atoi(key); err == nil {
+		return p == port
+	}
+
+	if host, p, err := parseForwardPort(key); err == nil && host != "" {
+		return p == port && isLocalHost(host)
+	}
+
+	if from, to, ok := strings.Cut(key, "-"); ok {
+		f, errFrom := strconv.Atoi(strings.TrimSpace(from))
+		t, errTo := strconv.Atoi(strings.TrimSpace(to))
+		if errFrom == nil && errTo == nil {
+			return f <= port && port <= t
+		}
+	}
+
+	re, err := regexp.Compile("^(?:" + key + ")$")
+	if err != nil {
+		return false
+	}
+	return re.MatchString(strconv.Itoa(port))
+}
+
+// publishedPorts returns the bindings of the ports in appPort and forwardPorts. A port is published on the same host
+// port if that is free, otherwise on a port chosen by Docker, unless requireLocalPort is set.
+func (cm *DockerContainerManager) publishedPorts(config Config) (nat.PortSet, nat.PortMap, error) {
+	ports := append([]int{}, config.AppPort...)
+	for _, value := range config.ForwardPorts {
+		host, port, err := parseForwardPort(value)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !isLocalHost(host) {
+			// ports of other services are published by their own containers, e.g. in docker-compose.yml
+			log.Warn().Str("port", value).Msg("Forwarding ports of other hosts is not supported, skipping")
+			continue
+		}
+
+		ports = append(ports, port)
+	}
+
+	exposed := make(nat.PortSet)
+	bindings := make(nat.PortMap)
+	for _, port := range ports {
+		key, err := nat.NewPort("tcp", strconv.Itoa(port))
+		if err != nil {
+			return nil, nil, fmt.Errorf("Failed to create new TCP port from port %d: %w", port, err)
+		}
+
+		if _, ok := bindings[key]; ok {
+			continue
+		}
+
+		hostPort := strconv.Itoa(port)
+		if !cm.portAvailable("127.0.0.1", port) {
+			if config.portAttributes(port).RequireLocalPort {
+				return nil, nil, fmt.Errorf("Port %d is required locally but is already in use", port)
+			}
+
+			log.Info().Int("port", port).Msg("Port is already in use, publishing on a free port")
+			hostPort = ""
+		}
+
+		exposed[key] = struct{}{}
+		bindings[key] = []nat.PortBinding{{HostIP: "127.0.0.1", HostPort: hostPort}}
+	}
+
+	return exposed, bindings, nil
+}
+
+func isPortAvailable(hostIP string, port int) bool {
+	listener, err := net.Listen("tcp", net.JoinHostPort(hostIP, strconv.Itoa(port)))
+	if err != nil {
+		return false
+	}
+
+	listener.Close()
+	return true
+}
+
+func (cm *DockerContainerManager) PortMappings(ctx context.Context, containerId string, config Config) ([]PortMapping, error) {
+	inspect, err := cm.ContainerInspect(ctx, containerId)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to inspect container %s: %w", containerId, err)
+	}
+
+	if inspect.NetworkSettings == nil {
+		return []PortMapping{}, nil
+	}
+
+	mappings := []PortMapping{}
+	for port, bindings := range inspect.NetworkSettings.Ports {
+		// the hide server is not part of the project
+		if port.Proto() != "tcp" || port.Int() == ServerPort {
+			continue
+		}
+
+		attributes := config.portAttributes(port.Int())
+		protocol := attributes.Protocol
+		if protocol == "" {
+			protocol = "http"
+		}
+
+		for _, binding := range bindings {
+			hostPort, err := strconv.Atoi(binding.HostPort)
+			if err != nil {
+				continue
+			}
+
+			host := binding.HostIP
+			if host == "" || host == "0.0.0.0" || host == "::" {
+				host = "127.0.0.1"
+			}
+
+			mappings = append(mappings, PortMapping{
+				ContainerPort: port.Int(),
+				HostIP:        binding.HostIP,
+				HostPort:      hostPort,
+				Label:         attributes.Label,
+				Protocol:      attributes.Protocol,
+				URL:           fmt.Sprintf("%s://%s", protocol, net.JoinHostPort(host, binding.HostPort)),
+			})
+		}
+	}
+
+	sort.Slice(mappings, func(i, j int) bool {
+		if mappings[i].ContainerPort != mappings[j].ContainerPort {
+			return mappings[i].ContainerPort < mappings[j].ContainerPort
+		}
+		return mappings[i].HostIP < mappings[j].HostIP
+	})
+
+	return mappings, nil
+}
@@ -0,0 +1,175 @@
+package devcontainer_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
+	"github.com/hide-org/hide/pkg/devcontainer/v2/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForwardPorts_UnmarshalJSON(t *testing.T) {
+	var config devcontainer.Config
+	err := json.Unmarshal([]byte(`{"forwardPorts": [3000, "db:5432", "8080"]}`), &config)
+
+	require.NoError(t, err)
+	assert.Equal(t, devcontainer.ForwardPorts{"3000", "db:5432", "8080"}, config.ForwardPorts)
+
+	err = json.Unmarshal([]byte(`{"forwardPorts": [true]}`), &config)
+	assert.ErrorContains(t, err, "Unsupported type for ForwardPorts")
+}
+
+// listen occupies a free local port until the test ends.
+func listen(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// freePort returns a local port that is free at the time of the call.
+func freePort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestDockerContainerManager_CreateContainerPorts(t *testing.T) {
+	free := freePort(t)
+	used := listen(t)
+
+	binding := func(hostPort string) []nat.PortBinding {
+		return []nat.PortBinding{{HostIP: "127.0.0.1", HostPort: hostPort}}
+	}
+	port := func(p int) nat.Port {
+		return nat.Port(strconv.Itoa(p) + "/tcp")
+	}
+
+	tests := []struct {
+		name          string
+		config        devcontainer.Config
+		expected      nat.PortMap
+		expectedError string
+	}{
+		{
+			name: "Publishes forwarded ports and app ports on the same host port",
+			config: devcontainer.Config{
+				DockerImageProps:  devcontainer.DockerImageProps{AppPort: devcontainer.AppPort{free}},
+				GeneralProperties: devcontainer.GeneralProperties{ForwardPorts: devcontainer.ForwardPorts{"localhost:" + strconv.Itoa(free), "db:5432"}},
+			},
+			expected: nat.PortMap{port(free): binding(strconv.Itoa(free)), "8081/tcp": binding("")},
+		},
+		{
+			name: "Publishes ports in use on a free port",
+			config: devcontainer.Config{
+				GeneralProperties: devcontainer.GeneralProperties{ForwardPorts: devcontainer.ForwardPorts{strconv.Itoa(used)}},
+			},
+			expected: nat.PortMap{port(used): binding(""), "8081/tcp": binding("")},
+		},
+		{
+			name: "Fails if a required local port is in use",
+			config: devcontainer.Config{
+				GeneralProperties: devcontainer.GeneralProperties{
+					ForwardPorts:    devcontainer.ForwardPorts{strconv.Itoa(used)},
+					PortsAttributes: map[string]devcontainer.PortAttributes{strconv.Itoa(used): {RequireLocalPort: true}},
+				},
+			},
+			expectedError: "is required locally but is already in use",
+		},
+		{
+			name: "Invalid forward port",
+			config: devcontainer.Config{
+				GeneralProperties: devcontainer.GeneralProperties{ForwardPorts: devcontainer.ForwardPorts{"db:http"}},
+			},
+			expectedError: `Invalid forward port "db:http"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mocks.MockDockerContainerClient{}
+			if tt.expectedError == "" {
+				mockClient.On("ContainerCreate", mock.Anything, mock.MatchedBy(func(config *container.Config) bool {
+					return len(config.ExposedPorts) == len(tt.expected)
+				}), mock.MatchedBy(func(hostConfig *container.HostConfig) bool {
+					return assert.Equal(t, tt.expected, hostConfig.PortBindings)
+				}), mock.Anything, mock.Anything, "").Return(container.CreateResponse{ID: "test-container-id"}, nil)
+			}
+
+			_, err := devcontainer.NewDockerContainerManager(mockClient).CreateContainer(context.Background(), "test-image", "/test/project", tt.config)
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDockerContainerManager_PortMappings(t *testing.T) {
+	mockClient := &mocks.MockDockerContainerClient{}
+	mockClient.On("ContainerInspect", mock.Anything, "test-container-id").Return(types.ContainerJSON{
+		NetworkSettings: &types.NetworkSettings{NetworkSettingsBase: types.NetworkSettingsBase{Ports: nat.PortMap{
+			"8081/tcp": []nat.PortBinding{{HostIP: "127.0.0.1", HostPort: "49100"}},
+			"3000/tcp": []nat.PortBinding{{HostIP: "127.0.0.1", HostPort: "3000"}},
+			"5432/tcp": []nat.PortBinding{{HostIP: "127.0.0.1", HostPort: "49101"}},
+			"9229/tcp": []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "49102"}},
+			"8443/tcp": []nat.PortBinding{{HostIP: "127.0.0.1", HostPort: "49103"}},
+			"6000/tcp": nil,
+		}}},
+	}, nil)
+
+	config := devcontainer.Config{
+		GeneralProperties: devcontainer.GeneralProperties{
+			PortsAttributes: map[string]devcontainer.PortAttributes{
+				"3000":      {Label: "Application"},
+				"5000-5999": {Label: "Database"},
+				"84[0-9]+":  {Label: "Secure", Protocol: "https"},
+			},
+			OtherPortsAttributes: devcontainer.PortAttributes{Label: "Other"},
+		},
+	}
+
+	mappings, err := devcontainer.NewDockerContainerManager(mockClient).PortMappings(context.Background(), "test-container-id", config)
+
+	require.NoError(t, err)
+	assert.Equal(t, []devcontainer.PortMapping{
+		{ContainerPort: 3000, HostIP: "127.0.0.1", HostPort: 3000, Label: "Application", URL: "http://127.0.0.1:3000"},
+		{ContainerPort: 5432, HostIP: "127.0.0.1", HostPort: 49101, Label: "Database", URL: "http://127.0.0.1:49101"},
+		{ContainerPort: 8443, HostIP: "127.0.0.1", HostPort: 49103, Label: "Secure", Protocol: "https", URL: "https://127.0.0.1:49103"},
+		{ContainerPort: 9229, HostIP: "0.0.0.0", HostPort: 49102, Label: "Other", URL: "http://127.0.0.1:49102"},
+	}, mappings)
+}
+
+func TestDockerRunner_PortMappings(t *testing.T) {
+	mockContainerManager := &mocks.MockContainerManager{}
+
+	config := devcontainer.Config{GeneralProperties: devcontainer.GeneralProperties{
+		PortsAttributes: map[string]devcontainer.PortAttributes{"3000": {Label: "Application"}},
+	}}
+
+	mappings := []devcontainer.PortMapping{{ContainerPort: 3000, HostPort: 3000, Label: "Application"}}
+	mockContainerManager.On("PortMappings", mock.Anything, "container-id", config).Return(mappings, nil)
+
+	runner := devcontainer.NewDockerRunner(&mocks.MockExecutor{}, &mocks.MockImageManager{}, mockContainerManager)
+
+	// the config of the caller, e.g. the one stored with the project, is used for labels
+	actual, err := runner.PortMappings(context.Background(), "container-id", config)
+	require.NoError(t, err)
+	assert.Equal(t, mappings, actual)
+}
@@ -1,14 +1,30 @@
 package devcontainer
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 
+	"github.com/distribution/reference"
 	"github.com/docker/docker/api/types/registry"
 )
 
+// dockerHubServerAddress is the key of Docker Hub in the Docker config and credential helpers.
+const dockerHubServerAddress = "https://index.docker.io/v1/"
+
 type RegistryCredentials interface {
-	GetCredentials() (string, error)
+	// GetCredentials returns the encoded auth config of the registry of the image, empty if there is none.
+	GetCredentials(image string) (string, error)
+	// GetAllCredentials returns the auth configs of all known registries by server address, for the base images of
+	// builds.
+	GetAllCredentials() (map[string]registry.AuthConfig, error)
 }
 
 type DockerHubRegistryCredentials struct {
@@ -20,13 +36,254 @@ func NewDockerHubRegistryCredentials(username, password string) RegistryCredenti
 	return &DockerHubRegistryCredentials{username: username, password: password}
 }
 
-// Encodes the credentials as a base64 encoded JSON string
-func (c *DockerHubRegistryCredentials) GetCredentials() (string, error) {
-	authConfig := registry.AuthConfig{
-		Username: c.username,
-		Password: c.password,
+// Encodes the credentials as a base64 encoded JSON string, if the image is on Docker Hub
+func (c *DockerHubRegistryCredentials) GetCredentials(image string) (string, error) {
+	serverAddress, err := registryServerAddress(image)
+	if err != nil || serverAddress != dockerHubServerAddress || c.username == "" {
+		return "", err
+	}
+
+	return encodeAuthConfig(c.authConfig())
+}
+
+func (c *DockerHubRegistryCredentials) GetAllCredentials() (map[string]registry.AuthConfig, error) {
+	if c.username == "" {
+		return nil, nil
+	}
+
+	return map[string]registry.AuthConfig{dockerHubServerAddress: c.authConfig()}, nil
+}
+
+func (c *DockerHubRegistryCredentials) authConfig() registry.AuthConfig {
+	return registry.AuthConfig{
+		Username:      c.username,
+		Password:      c.password,
+		ServerAddress: dockerHubServerAddress,
+	}
+}
+
+// DockerConfigRegistryCredentials resolves the credentials of registries like the Docker CLI does, from the auths,
+// credsStore and credHelpers of a Docker config file. The file is read on every call, so that logins made in the
+// meantime are picked up.
+type DockerConfigRegistryCredentials struct {
+	configPath string
+}
+
+func NewDockerConfigRegistryCredentials(configPath string) RegistryCredentials {
+	return &DockerConfigRegistryCredentials{configPath: configPath}
+}
+
+// DefaultDockerConfigPath returns the path of the Docker config file, $DOCKER_CONFIG/config.json or
+// ~/.docker/config.json.
+func DefaultDockerConfigPath() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return filepath.Join(dir, "config.json")
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".docker", "config.json")
+	}
+	return filepath.Join(home, ".docker", "config.json")
+}
+
+type dockerConfigFile struct {
+	Auths       map[string]dockerConfigAuth `json:"auths"`
+	CredsStore  string                      `json:"credsStore"`
+	CredHelpers map[string]string           `json:"credHelpers"`
+}
+
+type dockerConfigAuth struct {
+	Auth          string `json:"auth"`
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	IdentityToken string `json:"identitytoken"`
+	RegistryToken string `json:"registrytoken"`
+}
+
+// Encodes the credentials of the registry of the image as a base64 encoded JSON string
+func (c *DockerConfigRegistryCredentials) GetCredentials(image string) (string, error) {
+	serverAddress, err := registryServerAddress(image)
+	if err != nil {
+		return "", err
+	}
+
+	config, err := c.readConfig()
+	if err != nil {
+		return "", err
+	}
+
+	authConfig, err := config.authConfig(serverAddress)
+	if err != nil || authConfig == nil {
+		return "", err
+	}
+
+	return encodeAuthConfig(*authConfig)
+}
+
+func (c *DockerConfigRegistryCredentials) GetAllCredentials() (map[string]registry.AuthConfig, error) {
+	config, err := c.readConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// logins with a credsStore leave an empty entry in auths, so auths and credHelpers list all known registries
+	serverAddresses := make(map[string]bool)
+	for serverAddress := range config.Auths {
+		serverAddresses[normalizeServerAddress(serverAddress)] = true
+	}
+	for serverAddress := range config.CredHelpers {
+		serverAddresses[normalizeServerAddress(serverAddress)] = true
 	}
 
+	authConfigs := make(map[string]registry.AuthConfig)
+	for serverAddress := range serverAddresses {
+		authConfig, err := config.authConfig(serverAddress)
+		if err != nil {
+			return nil, err
+		}
+		if authConfig != nil {
+			authConfigs[serverAddress] = *authConfig
+		}
+	}
+
+	return authConfigs, nil
+}
+
+func (c *DockerConfigRegistryCredentials) readConfig() (*dockerConfigFile, error) {
+	var config dockerConfigFile
+
+	content, err := os.ReadFile(c.configPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read Docker config %s: %w", c.configPath, err)
+	}
+
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("Failed to parse Docker config %s: %w", c.configPath, err)
+	}
+
+	return &config, nil
+}
+
+// authConfig returns the credentials of the registry, from its credential helper, the credsStore or auths, in that
+// order. It returns nil if there are none.
+func (f *dockerConfigFile) authConfig(serverAddress string) (*registry.AuthConfig, error) {
+	helper := f.CredsStore
+	for key, name := range f.CredHelpers {
+		if normalizeServerAddress(key) == serverAddress {
+			helper = name
+		}
+	}
+
+	if helper != "" {
+		authConfig, err := credentialHelperAuthConfig(helper, serverAddress)
+		if err != nil || authConfig != nil {
+			return authConfig, err
+		}
+	}
+
+	for key, auth := range f.Auths {
+		if normalizeServerAddress(key) != serverAddress {
+			continue
+		}
+
+		authConfig := registry.AuthConfig{
+			Username:      auth.Username,
+			Password:      auth.Password,
+			IdentityToken: auth.IdentityToken,
+			RegistryToken: auth.RegistryToken,
+			ServerAddress: serverAddress,
+		}
+		if auth.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid auth of %s in Docker config: %w", key, err)
+			}
+			username, password, ok := strings.Cut(string(decoded), ":")
+			if !ok {
+				return nil, fmt.Errorf("Invalid auth of %s in Docker config, expected username:password", key)
+			}
+			authConfig.Username, authConfig.Password = username, password
+		}
+
+		if authConfig.Username == "" && authConfig.IdentityToken == "" && authConfig.RegistryToken == "" {
+			continue
+		}
+		return &authConfig, nil
+	}
+
+	return nil, nil
+}
+
+// credentialHelperAuthConfig gets the credentials of the registry from docker-credential-<helper>. It returns nil if
+// the helper has none.
+func credentialHelperAuthConfig(helper, serverAddress string) (*registry.AuthConfig, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(serverAddress)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		// helpers print the error to stdout
+		message := strings.TrimSpace(stdout.String() + stderr.String())
+		if strings.Contains(message, "credentials not found") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Failed to get credentials of %s from docker-credential-%s: %w: %s", serverAddress, helper, err, message)
+	}
+
+	var credentials struct {
+		Username string
+		Secret   string
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &credentials); err != nil {
+		return nil, fmt.Errorf("Invalid credentials of %s from docker-credential-%s: %w", serverAddress, helper, err)
+	}
+
+	authConfig := registry.AuthConfig{ServerAddress: serverAddress}
+	// helpers store identity tokens with the <token> username
+	if credentials.Username == "<token>" {
+		authConfig.IdentityToken = credentials.Secret
+	} else {
+		authConfig.Username = credentials.Username
+		authConfig.Password = credentials.Secret
+	}
+
+	return &authConfig, nil
+}
+
+// registryServerAddress returns the address of the registry of the image as used in the Docker config, the hostname
+// of the registry or dockerHubServerAddress for Docker Hub.
+func registryServerAddress(image string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", fmt.Errorf("Invalid image reference %s: %w", image, err)
+	}
+
+	return normalizeServerAddress(reference.Domain(named)), nil
+}
+
+// normalizeServerAddress strips the scheme and path of a registry address, e.g. https://ghcr.io/v2/, except for Docker
+// Hub, which the Docker CLI stores as dockerHubServerAddress.
+func normalizeServerAddress(address string) string {
+	host := address
+	if _, rest, ok := strings.Cut(host, "://"); ok {
+		host = rest
+	}
+	host, _, _ = strings.Cut(host, "/")
+
+	switch host {
+	case "docker.io", "index.docker.io", "registry-1.docker.io":
+		return dockerHubServerAddress
+	}
+	return host
+}
+
+func encodeAuthConfig(authConfig registry.AuthConfig) (string, error) {
 	encodedJSON, err := json.Marshal(authConfig)
 	if err != nil {
 		return "", err
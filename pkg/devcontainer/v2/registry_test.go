@@ -0,0 +1,152 @@
+package devcontainer_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/docker/docker/api/types/registry"
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeAuthConfig(t *testing.T, authStr string) registry.AuthConfig {
+	t.Helper()
+
+	var authConfig registry.AuthConfig
+	decoded, err := base64.URLEncoding.DecodeString(authStr)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(decoded, &authConfig))
+	return authConfig
+}
+
+// writeCredentialHelper installs docker-credential-<name> in PATH, printing credentials for the registries in secrets
+// and "credentials not found" for others.
+func writeCredentialHelper(t *testing.T, name string, secrets map[string][2]string) {
+	t.Helper()
+
+	script := "#!/bin/sh\nread server\ncase \"$server\" in\n"
+	for server, secret := range secrets {
+		script += "\"" + server + "\") echo '{\"ServerURL\":\"" + server + "\",\"Username\":\"" + secret[0] + "\",\"Secret\":\"" + secret[1] + "\"}' ;;\n"
+	}
+	script += "*) echo 'credentials not found in native keychain'; exit 1 ;;\nesac\n"
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docker-credential-"+name), []byte(script), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestDockerConfigRegistryCredentials(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("credential helpers are shell scripts")
+	}
+
+	writeCredentialHelper(t, "store", map[string][2]string{
+		"https://index.docker.io/v1/": {"hub-user", "hub-token"},
+	})
+	writeCredentialHelper(t, "gcloud", map[string][2]string{
+		"europe-docker.pkg.dev": {"<token>", "identity-token"},
+	})
+
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(configPath, []byte(`{
+		"auths": {
+			"https://index.docker.io/v1/": {},
+			"ghcr.io": {"auth": "`+base64.StdEncoding.EncodeToString([]byte("gh-user:gh-token"))+`"},
+			"http://localhost:5000/v2/": {"username": "local-user", "password": "local-password"}
+		},
+		"credsStore": "store",
+		"credHelpers": {"europe-docker.pkg.dev": "gcloud"}
+	}`), 0o600))
+
+	credentials := devcontainer.NewDockerConfigRegistryCredentials(configPath)
+
+	tests := []struct {
+		name     string
+		image    string
+		expected *registry.AuthConfig
+	}{
+		{
+			name:     "Docker Hub from credsStore",
+			image:    "ubuntu:22.04",
+			expected: &registry.AuthConfig{Username: "hub-user", Password: "hub-token", ServerAddress: "https://index.docker.io/v1/"},
+		},
+		{
+			name:     "Registry from auths",
+			image:    "ghcr.io/hide-org/private:latest",
+			expected: &registry.AuthConfig{Username: "gh-user", Password: "gh-token", ServerAddress: "ghcr.io"},
+		},
+		{
+			name:     "Local registry from auths",
+			image:    "localhost:5000/project/image",
+			expected: &registry.AuthConfig{Username: "local-user", Password: "local-password", ServerAddress: "localhost:5000"},
+		},
+		{
+			name:     "Identity token from credHelpers",
+			image:    "europe-docker.pkg.dev/project/repo/image:1",
+			expected: &registry.AuthConfig{IdentityToken: "identity-token", ServerAddress: "europe-docker.pkg.dev"},
+		},
+		{
+			name:  "Unknown registry",
+			image: "quay.io/org/image",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authStr, err := credentials.GetCredentials(tt.image)
+			require.NoError(t, err)
+
+			if tt.expected == nil {
+				assert.Empty(t, authStr)
+				return
+			}
+			assert.Equal(t, *tt.expected, decodeAuthConfig(t, authStr))
+		})
+	}
+
+	t.Run("All credentials", func(t *testing.T) {
+		authConfigs, err := credentials.GetAllCredentials()
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]registry.AuthConfig{
+			"https://index.docker.io/v1/": {Username: "hub-user", Password: "hub-token", ServerAddress: "https://index.docker.io/v1/"},
+			"ghcr.io":                     {Username: "gh-user", Password: "gh-token", ServerAddress: "ghcr.io"},
+			"localhost:5000":              {Username: "local-user", Password: "local-password", ServerAddress: "localhost:5000"},
+			"europe-docker.pkg.dev":       {IdentityToken: "identity-token", ServerAddress: "europe-docker.pkg.dev"},
+		}, authConfigs)
+	})
+
+	t.Run("Missing config", func(t *testing.T) {
+		credentials := devcontainer.NewDockerConfigRegistryCredentials(filepath.Join(t.TempDir(), "config.json"))
+
+		authStr, err := credentials.GetCredentials("ghcr.io/hide-org/private")
+		require.NoError(t, err)
+		assert.Empty(t, authStr)
+	})
+
+	t.Run("Failing credential helper", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(configPath, []byte(`{"credsStore": "missing"}`), 0o600))
+
+		_, err := devcontainer.NewDockerConfigRegistryCredentials(configPath).GetCredentials("ghcr.io/hide-org/private")
+		assert.ErrorContains(t, err, "Failed to get credentials of ghcr.io from docker-credential-missing")
+	})
+}
+
+func TestDockerHubRegistryCredentials(t *testing.T) {
+	credentials := devcontainer.NewDockerHubRegistryCredentials("test-username", "test-password")
+
+	authStr, err := credentials.GetCredentials("docker.io/library/ubuntu")
+	require.NoError(t, err)
+	assert.Equal(t, registry.AuthConfig{Username: "test-username", Password: "test-password", ServerAddress: "https://index.docker.io/v1/"}, decodeAuthConfig(t, authStr))
+
+	// the credentials are not sent to other registries
+	authStr, err = credentials.GetCredentials("ghcr.io/hide-org/private")
+	require.NoError(t, err)
+	assert.Empty(t, authStr)
+}
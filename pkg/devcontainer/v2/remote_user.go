@@ -0,0 +1,189 @@
+package devcontainer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	UserEnvProbeNone                  = "none"
+	UserEnvProbeInteractiveShell      = "interactiveShell"
+	UserEnvProbeLoginShell            = "loginShell"
+	UserEnvProbeLoginInteractiveShell = "loginInteractiveShell"
+
+	// userEnvProbeMarker delimits the environment in the output of the probe, shells may print other things too
+	userEnvProbeMarker = "b5c1f3d2-hide-user-env-probe"
+)
+
+// userEnvProbeFlags returns the flags of the shell used to probe the environment of the remote user, none if the
+// environment is not probed. loginInteractiveShell is the default.
+func (g *GeneralProperties) userEnvProbeFlags() (string, error) {
+	switch g.UserEnvProbe {
+	case UserEnvProbeNone:
+		return "", nil
+	case UserEnvProbeInteractiveShell:
+		return "-ic", nil
+	case UserEnvProbeLoginShell:
+		return "-lc", nil
+	case UserEnvProbeLoginInteractiveShell, "":
+		return "-lic", nil
+	default:
+		return "", fmt.Errorf("Invalid userEnvProbe %q, expected one of %v", g.UserEnvProbe, []string{UserEnvProbeNone, UserEnvProbeInteractiveShell, UserEnvProbeLoginShell, UserEnvProbeLoginInteractiveShell})
+	}
+}
+
+// userEnvProbeCommand returns the command that prints the environment of the login shell of the user between markers.
+func userEnvProbeCommand(flags string) []string {
+	script := fmt.Sprintf(
+		`shell=$(getent passwd "$(id -un)" 2>/dev/null | cut -d: -f7); exec "${shell:-%s}" %s 'printf %s; cat /proc/self/environ; printf %s'`,
+		DefaultShell, flags, userEnvProbeMarker, userEnvProbeMarker,
+	)
+	return []string{DefaultShell, "-c", script}
+}
+
+// parseUserEnv parses the NUL separated environment between the markers in the output of the probe.
+func parseUserEnv(output string) (map[string]string, error) {
+	start := strings.Index(output, userEnvProbeMarker)
+	end := strings.LastIndex(output, userEnvProbeMarker)
+	if start < 0 || end <= start {
+		return nil, errors.New("Environment not found in the output of the probe")
+	}
+
+	env := make(map[string]string)
+	for _, entry := range strings.Split(output[start+len(userEnvProbeMarker):end], "\x00") {
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok || key == "" {
+			continue
+		}
+		// these belong to the probing shell
+		if key == "PWD" || key == "OLDPWD" || key == "SHLVL" || key == "_" {
+			continue
+		}
+		env[key] = value
+	}
+
+	return env, nil
+}
+
+// probeUserEnv returns the environment of the remote user according to userEnvProbe, nil if it is not probed.
+func (r *DockerRunner) probeUserEnv(ctx context.Context, containerId string, config Config) (map[string]string, error) {
+	flags, err := config.userEnvProbeFlags()
+	if err != nil || flags == "" {
+		return nil, err
+	}
+
+	var opts []ExecOption
+	if config.RemoteUser != "" {
+		opts = append(opts, ExecWithUser(config.RemoteUser))
+	}
+
+	var stdout, stderr bytes.Buffer
+	exitCode, err := r.containerManager.ExecStream(ctx, containerId, userEnvProbeCommand(flags), &stdout, &stderr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if exitCode != 0 {
+		return nil, fmt.Errorf("Probe exited with code %d: %s", exitCode, strings.TrimSpace(stderr.String()))
+	}
+
+	return parseUserEnv(stdout.String())
+}
+
+// remoteEnv returns the environment of commands run in the container, the probed environment of the remote user with
+// remoteEnv on top. Failing to probe is not fatal, the commands run with remoteEnv only.
+func (r *DockerRunner) remoteEnv(ctx context.Context, containerId string, config Config) map[string]string {
+	env, err := r.probeUserEnv(ctx, containerId, config)
+	if err != nil {
+		log.Warn().Err(err).Str("containerId", containerId).Msg("Failed to probe user environment")
+	}
+
+	if env == nil {
+		env = make(map[string]string)
+	}
+	maps.Copy(env, config.RemoteEnv)
+
+	return env
+}
+
+// execOptions returns the options of commands run in the container, as remoteUser with the remote environment.
+func (r *DockerRunner) execOptions(containerId string) []ExecOption {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var opts []ExecOption
+	if user := r.configs[containerId].RemoteUser; user != "" {
+		opts = append(opts, ExecWithUser(user))
+	}
+	if env := r.remoteEnvs[containerId]; len(env) > 0 {
+		opts = append(opts, ExecWithEnv(env))
+	}
+
+	return opts
+}
+
+// userToUpdate returns the user whose UID/GID is updated to match the local user, remoteUser or containerUser. Root
+// and numeric users are not updated.
+func (g *GeneralProperties) userToUpdate() string {
+	if g.UpdateRemoteUserUID != nil && !*g.UpdateRemoteUserUID {
+		return ""
+	}
+
+	user := g.RemoteUser
+	if user == "" {
+		user = g.ContainerUser
+	}
+
+	// containerUser can be user:group
+	user, _, _ = strings.Cut(user, ":")
+	if user == "root" {
+		return ""
+	}
+	if _, err := strconv.Atoi(user); err == nil {
+		return ""
+	}
+
+	return user
+}
+
+// updateUIDScript changes the UID/GID of REMOTE_USER to NEW_UID/NEW_GID, unless they are taken by another user or group.
+const updateUIDScript = `eval $(sed -n "s/^${REMOTE_USER}:[^:]*:\([^:]*\):\([^:]*\):[^:]*:\([^:]*\).*/OLD_UID=\1;OLD_GID=\2;HOME_FOLDER=\3/p" /etc/passwd); \
+	eval $(sed -n "s/^\([^:]*\):[^:]*:${NEW_UID}:.*/EXISTING_USER=\1/p" /etc/passwd); \
+	eval $(sed -n "s/^\([^:]*\):[^:]*:${NEW_GID}:.*/EXISTING_GROUP=\1/p" /etc/group); \
+	if [ -z "$OLD_UID" ]; then \
+		echo "Remote user not found in /etc/passwd ($REMOTE_USER)."; \
+	elif [ "$OLD_UID" = "$NEW_UID" -a "$OLD_GID" = "$NEW_GID" ]; then \
+		echo "UIDs and GIDs are the same ($NEW_UID:$NEW_GID)."; \
+	elif [ "$OLD_UID" != "$NEW_UID" -a -n "$EXISTING_USER" ]; then \
+		echo "User with UID exists ($EXISTING_USER=$NEW_UID)."; \
+	else \
+		if [ "$OLD_GID" != "$NEW_GID" -a -n "$EXISTING_GROUP" ]; then \
+			echo "Group with GID exists ($EXISTING_GROUP=$NEW_GID)."; \
+			NEW_GID="$OLD_GID"; \
+		fi; \
+		echo "Updating UID:GID from $OLD_UID:$OLD_GID to $NEW_UID:$NEW_GID."; \
+		sed -i -e "s/^\(${REMOTE_USER}:[^:]*:\)[^:]*:[^:]*/\1${NEW_UID}:${NEW_GID}/" /etc/passwd; \
+		if [ "$OLD_GID" != "$NEW_GID" ]; then \
+			sed -i -e "s/^\([^:]*:[^:]*:\)${OLD_GID}:/\1${NEW_GID}:/" /etc/group; \
+		fi; \
+		chown -R $NEW_UID:$NEW_GID $HOME_FOLDER; \
+	fi`
+
+// updateUIDDockerfile returns the Dockerfile that updates the UID/GID of a user of baseImage, passed as build args.
+func updateUIDDockerfile(baseImage, baseUser string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "FROM %s\n", baseImage)
+	b.WriteString("USER root\n")
+	b.WriteString("ARG REMOTE_USER\nARG NEW_UID\nARG NEW_GID\n")
+	fmt.Fprintf(&b, "RUN %s\n", updateUIDScript)
+	if baseUser != "" {
+		fmt.Fprintf(&b, "USER %s\n", baseUser)
+	}
+	return b.String()
+}
@@ -0,0 +1,215 @@
+package devcontainer_test
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
+	"github.com/hide-org/hide/pkg/devcontainer/v2/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var userEnvProbeMarker = regexp.MustCompile(`printf (\S+);`)
+
+func isUserEnvProbe(command []string) bool {
+	return len(command) == 3 && strings.Contains(command[2], "/proc/self/environ")
+}
+
+// probedUserEnv makes the environment probe of the user print env, KEY=value pairs. The user is empty if the probe
+// runs as the user of the container.
+func probedUserEnv(mcm *mocks.MockContainerManager, user string, env ...string) *mock.Call {
+	args := []any{mock.Anything, mock.Anything, mock.MatchedBy(isUserEnvProbe), mock.Anything, mock.Anything}
+	if user != "" {
+		args = append(args, devcontainer.ExecOptions{User: user})
+	}
+
+	return mcm.On("ExecStream", args...).
+		Run(func(args mock.Arguments) {
+			marker := userEnvProbeMarker.FindStringSubmatch(args.Get(2).([]string)[2])[1]
+			fmt.Fprintf(args.Get(3).(io.Writer), "Welcome!\n%s%s\x00%s", marker, strings.Join(env, "\x00"), marker)
+		}).
+		Return(0, nil)
+}
+
+func TestDockerRunner_RemoteUser(t *testing.T) {
+	config := lifecycleConfig(devcontainer.LifecycleProps{
+		PostCreateCommand: devcontainer.LifecycleCommand{"": []string{"make", "setup"}},
+		WaitFor:           "postCreateCommand",
+	})
+	config.RemoteUser = "root"
+	config.RemoteEnv = map[string]string{"GOFLAGS": "-mod=mod", "PATH": "/workspace/bin:/usr/bin"}
+
+	t.Run("Runs commands as remoteUser with the probed environment and remoteEnv", func(t *testing.T) {
+		mockImageManager := &mocks.MockImageManager{}
+		mockContainerManager := &mocks.MockContainerManager{}
+		startedContainer(mockImageManager, mockContainerManager)
+
+		// the environment is probed once, as the remote user
+		probedUserEnv(mockContainerManager, "root", "HOME=/root", "PATH=/usr/bin", "PWD=/root").Once()
+		options := devcontainer.ExecOptions{User: "root", Env: []string{"GOFLAGS=-mod=mod", "HOME=/root", "PATH=/workspace/bin:/usr/bin"}}
+		mockContainerManager.On("ExecStream", mock.Anything, "container-id", []string{"make", "setup"}, mock.Anything, mock.Anything, options).Return(0, nil)
+		mockContainerManager.On("Exec", mock.Anything, "container-id", []string{"go", "test"}, options).Return(devcontainer.ExecResult{}, nil)
+
+		runner := devcontainer.NewDockerRunner(&mocks.MockExecutor{}, mockImageManager, mockContainerManager)
+		containerId, err := runner.Run(context.Background(), "/test/project", config)
+		require.NoError(t, err)
+
+		_, err = runner.Exec(context.Background(), containerId, []string{"go", "test"})
+		require.NoError(t, err)
+
+		mockContainerManager.AssertExpectations(t)
+	})
+
+	t.Run("Uses remoteEnv only if the probe fails", func(t *testing.T) {
+		mockImageManager := &mocks.MockImageManager{}
+		mockContainerManager := &mocks.MockContainerManager{}
+		startedContainer(mockImageManager, mockContainerManager)
+
+		mockContainerManager.On("ExecStream", mock.Anything, "container-id", mock.MatchedBy(isUserEnvProbe), mock.Anything, mock.Anything, mock.Anything).Return(0, errors.New("no shell"))
+		options := devcontainer.ExecOptions{User: "root", Env: []string{"GOFLAGS=-mod=mod", "PATH=/workspace/bin:/usr/bin"}}
+		mockContainerManager.On("ExecStream", mock.Anything, "container-id", []string{"make", "setup"}, mock.Anything, mock.Anything, options).Return(0, nil)
+
+		runner := devcontainer.NewDockerRunner(&mocks.MockExecutor{}, mockImageManager, mockContainerManager)
+		_, err := runner.Run(context.Background(), "/test/project", config)
+
+		require.NoError(t, err)
+		mockContainerManager.AssertExpectations(t)
+	})
+
+	t.Run("Does not probe with userEnvProbe none", func(t *testing.T) {
+		mockImageManager := &mocks.MockImageManager{}
+		mockContainerManager := &mocks.MockContainerManager{}
+		startedContainer(mockImageManager, mockContainerManager)
+
+		options := devcontainer.ExecOptions{User: "root", Env: []string{"GOFLAGS=-mod=mod", "PATH=/workspace/bin:/usr/bin"}}
+		mockContainerManager.On("ExecStream", mock.Anything, "container-id", []string{"make", "setup"}, mock.Anything, mock.Anything, options).Return(0, nil)
+
+		config := config
+		config.UserEnvProbe = devcontainer.UserEnvProbeNone
+
+		runner := devcontainer.NewDockerRunner(&mocks.MockExecutor{}, mockImageManager, mockContainerManager)
+		_, err := runner.Run(context.Background(), "/test/project", config)
+
+		require.NoError(t, err)
+		mockContainerManager.AssertExpectations(t)
+	})
+
+	t.Run("Invalid userEnvProbe", func(t *testing.T) {
+		config := config
+		config.UserEnvProbe = "bash"
+
+		runner := devcontainer.NewDockerRunner(&mocks.MockExecutor{}, &mocks.MockImageManager{}, &mocks.MockContainerManager{})
+		_, err := runner.Run(context.Background(), "/test/project", config)
+
+		assert.ErrorContains(t, err, `Invalid userEnvProbe "bash"`)
+	})
+}
+
+func TestDockerRunner_UpdateRemoteUserUID(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("UID/GID are only updated on Linux")
+	}
+
+	disabled := false
+	tests := []struct {
+		name       string
+		properties devcontainer.GeneralProperties
+		user       string
+	}{
+		{name: "Updates remoteUser", properties: devcontainer.GeneralProperties{RemoteUser: "dev", ContainerUser: "app"}, user: "dev"},
+		{name: "Updates containerUser", properties: devcontainer.GeneralProperties{ContainerUser: "app:staff"}, user: "app"},
+		{name: "Does not update root", properties: devcontainer.GeneralProperties{RemoteUser: "root"}},
+		{name: "Does not update numeric users", properties: devcontainer.GeneralProperties{ContainerUser: "1000"}},
+		{name: "Does not update if disabled", properties: devcontainer.GeneralProperties{RemoteUser: "dev", UpdateRemoteUserUID: &disabled}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := devcontainer.Config{DockerImageProps: devcontainer.DockerImageProps{Image: "test-image"}, GeneralProperties: tt.properties}
+			config.UserEnvProbe = devcontainer.UserEnvProbeNone
+
+			image := "test-image"
+			mockImageManager := &mocks.MockImageManager{}
+			mockImageManager.On("LocalImageExists", mock.Anything, "test-image").Return(true, nil)
+			noImageMetadata(mockImageManager)
+			if tt.user != "" {
+				image = "uid-image"
+				mockImageManager.On("BuildUpdateUIDImage", mock.Anything, "test-image", tt.user, os.Getuid(), os.Getgid()).Return(image, nil)
+			}
+
+			mockContainerManager := &mocks.MockContainerManager{}
+			mockContainerManager.On("CreateContainer", mock.Anything, image, "/test/project", config).Return("container-id", nil)
+			mockContainerManager.On("StartContainer", mock.Anything, "container-id").Return(nil)
+
+			runner := devcontainer.NewDockerRunner(&mocks.MockExecutor{}, mockImageManager, mockContainerManager)
+			_, err := runner.Run(context.Background(), "/test/project", config)
+
+			require.NoError(t, err)
+			mockImageManager.AssertExpectations(t)
+			mockContainerManager.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDockerImageManager_BuildUpdateUIDImage(t *testing.T) {
+	mockClient := &mocks.MockDockerImageClient{}
+	mockClient.On("ImageInspectWithRaw", mock.Anything, "base-image").Return(types.ImageInspect{ID: "sha256:base", Config: &container.Config{User: "vscode"}}, []byte{}, nil)
+	mockClient.On("ImageList", mock.Anything, mock.Anything).Return([]image.Summary{}, nil)
+
+	var dockerfile string
+	var options types.ImageBuildOptions
+	mockClient.On("ImageBuild", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			options = args.Get(2).(types.ImageBuildOptions)
+			tr := tar.NewReader(args.Get(1).(io.Reader))
+			if _, err := tr.Next(); err == nil {
+				content, _ := io.ReadAll(tr)
+				dockerfile = string(content)
+			}
+		}).
+		Return(types.ImageBuildResponse{Body: io.NopCloser(strings.NewReader(""))}, nil)
+
+	imageManager := devcontainer.NewImageManager(mockClient, &mocks.MockRegistryCredentials{})
+	tag, err := imageManager.BuildUpdateUIDImage(context.Background(), "base-image", "dev", 1001, 1002)
+
+	require.NoError(t, err)
+	assert.Regexp(t, `^devcontainer-uid:[0-9a-f]{12}$`, tag)
+	assert.Equal(t, []string{tag}, options.Tags)
+
+	user, uid, gid := "dev", "1001", "1002"
+	assert.Equal(t, map[string]*string{"REMOTE_USER": &user, "NEW_UID": &uid, "NEW_GID": &gid}, options.BuildArgs)
+	assert.True(t, strings.HasPrefix(dockerfile, "FROM base-image\nUSER root\nARG REMOTE_USER\nARG NEW_UID\nARG NEW_GID\nRUN "))
+	assert.Contains(t, dockerfile, "/etc/passwd")
+	assert.True(t, strings.HasSuffix(dockerfile, "USER vscode\n"))
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestDockerContainerManager_ExecOptions(t *testing.T) {
+	mockClient := &mocks.MockDockerContainerClient{}
+	mockClient.On("ContainerExecCreate", mock.Anything, "test-container-id", mock.MatchedBy(func(config types.ExecConfig) bool {
+		return config.User == "dev" && assert.Equal(t, []string{"A=1", "B=2"}, config.Env)
+	})).Return(types.IDResponse{ID: "exec-id"}, nil)
+	mockClient.On("ContainerExecStart", mock.Anything, "exec-id", types.ExecStartCheck{}).Return(nil)
+
+	containerManager := devcontainer.NewDockerContainerManager(mockClient)
+	execId, err := containerManager.ExecDetached(context.Background(), "test-container-id", []string{"serve"},
+		devcontainer.ExecWithUser("dev"), devcontainer.ExecWithEnv(map[string]string{"B": "2", "A": "1"}))
+
+	require.NoError(t, err)
+	assert.Equal(t, "exec-id", execId)
+	mockClient.AssertExpectations(t)
+}
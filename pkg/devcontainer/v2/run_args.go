@@ -0,0 +1,383 @@
+package devcontainer
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+	"github.com/docker/go-units"
+)
+
+type UnsupportedRunArgError struct {
+	Flag string
+}
+
+func (e UnsupportedRunArgError) Error() string {
+	return fmt.Sprintf("Unsupported runArgs flag %s, supported flags are %s", e.Flag, strings.Join(supportedRunArgs(), ", "))
+}
+
+func NewUnsupportedRunArgError(flag string) *UnsupportedRunArgError {
+	return &UnsupportedRunArgError{Flag: flag}
+}
+
+// runArgFlag applies a docker run flag to the configs of the container.
+type runArgFlag struct {
+	// boolean flags take no value, unless it is set with "=", e.g. --privileged=false
+	boolean bool
+	apply   func(value string, config *container.Config, hostConfig *container.HostConfig) error
+}
+
+var runArgAliases = map[string]string{
+	"-c":    "--cpu-shares",
+	"-e":    "--env",
+	"-h":    "--hostname",
+	"-l":    "--label",
+	"-m":    "--memory",
+	"-p":    "--publish",
+	"-u":    "--user",
+	"-v":    "--volume",
+	"-w":    "--workdir",
+	"--net": "--network",
+}
+
+var runArgFlags = map[string]runArgFlag{
+	"--add-host": {apply: func(value string, _ *container.Config, hostConfig *container.HostConfig) error {
+		// docker accepts both host:ip and host=ip
+		host, ip, ok := strings.Cut(value, "=")
+		if !ok {
+			host, ip, ok = strings.Cut(value, ":")
+		}
+		if !ok || host == "" || ip == "" {
+			return fmt.Errorf("expected host:ip, got %q", value)
+		}
+		hostConfig.ExtraHosts = append(hostConfig.ExtraHosts, host+":"+ip)
+		return nil
+	}},
+	"--cap-add": {apply: func(value string, _ *container.Config, hostConfig *container.HostConfig) error {
+		hostConfig.CapAdd = append(hostConfig.CapAdd, value)
+		return nil
+	}},
+	"--cap-drop": {apply: func(value string, _ *container.Config, hostConfig *container.HostConfig) error {
+		hostConfig.CapDrop = append(hostConfig.CapDrop, value)
+		return nil
+	}},
+	"--cpu-shares": {apply: func(value string, _ *container.Config, hostConfig *container.HostConfig) error {
+		shares, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		hostConfig.CPUShares = shares
+		return nil
+	}},
+	"--cpus": {apply: func(value string, _ *container.Config, hostConfig *container.HostConfig) error {
+		cpus, err := strconv.ParseFloat(value, 64)
+		if err != nil || cpus < 0 {
+			return fmt.Errorf("expected a number of CPUs, got %q", value)
+		}
+		hostConfig.NanoCPUs = int64(cpus * 1e9)
+		return nil
+	}},
+	"--cpuset-cpus": {apply: func(value string, _ *container.Config, hostConfig *container.HostConfig) error {
+		hostConfig.CpusetCpus = value
+		return nil
+	}},
+	"--device": {apply: func(value string, _ *container.Config, hostConfig *container.HostConfig) error {
+		device, err := parseDevice(value)
+		if err != nil {
+			return err
+		}
+		hostConfig.Devices = append(hostConfig.Devices, device)
+		return nil
+	}},
+	"--dns": {apply: func(value string, _ *container.Config, hostConfig *container.HostConfig) error {
+		hostConfig.DNS = append(hostConfig.DNS, value)
+		return nil
+	}},
+	"--env": {apply: func(value string, config *container.Config, _ *container.HostConfig) error {
+		// like docker, a variable without a value is taken from the host, if it is set there
+		if !strings.Contains(value, "=") {
+			hostValue, ok := os.LookupEnv(value)
+			if !ok {
+				return nil
+			}
+			value = value + "=" + hostValue
+		}
+		config.Env = append(config.Env, value)
+		return nil
+	}},
+	"--gpus": {apply: func(value string, _ *container.Config, hostConfig *container.HostConfig) error {
+		count := -1
+		if value != "all" {
+			var err error
+			if count, err = strconv.Atoi(value); err != nil || count <= 0 {
+				return fmt.Errorf("expected \"all\" or a number of GPUs, got %q", value)
+			}
+		}
+		hostConfig.DeviceRequests = append(hostConfig.DeviceRequests, container.DeviceRequest{Count: count, Capabilities: [][]string{{"gpu"}}})
+		return nil
+	}},
+	"--hostname": {apply: func(value string, config *container.Config, _ *container.HostConfig) error {
+		config.Hostname = value
+		return nil
+	}},
+	"--init": {boolean: true, apply: func(value string, _ *container.Config, hostConfig *container.HostConfig) error {
+		init, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		hostConfig.Init = &init
+		return nil
+	}},
+	"--ipc": {apply: func(value string, _ *container.Config, hostConfig *container.HostConfig) error {
+		hostConfig.IpcMode = container.IpcMode(value)
+		return nil
+	}},
+	"--label": {apply: func(value string, config *container.Config, _ *container.HostConfig) error {
+		key, labelValue, _ := strings.Cut(value, "=")
+		if config.Labels == nil {
+			config.Labels = make(map[string]string)
+		}
+		config.Labels[key] = labelValue
+		return nil
+	}},
+	"--memory": {apply: func(value string, _ *container.Config, hostConfig *container.HostConfig) error {
+		memory, err := units.RAMInBytes(value)
+		if err != nil {
+			return err
+		}
+		hostConfig.Memory = memory
+		return nil
+	}},
+	"--memory-reservation": {apply: func(value string, _ *container.Config, hostConfig *container.HostConfig) error {
+		memory, err := units.RAMInBytes(value)
+		if err != nil {
+			return err
+		}
+		hostConfig.MemoryReservation = memory
+		return nil
+	}},
+	"--memory-swap": {apply: func(value string, _ *container.Config, hostConfig *container.HostConfig) error {
+		// -1 is unlimited swap
+		if value == "-1" {
+			hostConfig.MemorySwap = -1
+			return nil
+		}
+		memory, err := units.RAMInBytes(value)
+		if err != nil {
+			return err
+		}
+		hostConfig.MemorySwap = memory
+		return nil
+	}},
+	"--network": {apply: func(value string, _ *container.Config, hostConfig *container.HostConfig) error {
+		// Docker discards published ports in these modes, and the hide server has to be published on ServerPort
+		mode := container.NetworkMode(value)
+		if mode.IsHost() || mode.IsNone() || mode.IsContainer() {
+			return fmt.Errorf("the hide server port %d cannot be published with network %s", ServerPort, value)
+		}
+		hostConfig.NetworkMode = mode
+		return nil
+	}},
+	"--pid": {apply: func(value string, _ *container.Config, hostConfig *container.HostConfig) error {
+		hostConfig.PidMode = container.PidMode(value)
+		return nil
+	}},
+	"--pids-limit": {apply: func(value string, _ *container.Config, hostConfig *container.HostConfig) error {
+		limit, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		hostConfig.PidsLimit = &limit
+		return nil
+	}},
+	"--privileged": {boolean: true, apply: func(value string, _ *container.Config, hostConfig *container.HostConfig) error {
+		privileged, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		hostConfig.Privileged = privileged
+		return nil
+	}},
+	"--publish": {apply: func(value string, config *container.Config, hostConfig *container.HostConfig) error {
+		exposed, bindings, err := nat.ParsePortSpecs([]string{value})
+		if err != nil {
+			return err
+		}
+		if config.ExposedPorts == nil {
+			config.ExposedPorts = make(nat.PortSet)
+		}
+		if hostConfig.PortBindings == nil {
+			hostConfig.PortBindings = make(nat.PortMap)
+		}
+		for port := range exposed {
+			config.ExposedPorts[port] = struct{}{}
+		}
+		for port, portBindings := range bindings {
+			hostConfig.PortBindings[port] = append(hostConfig.PortBindings[port], portBindings...)
+		}
+		return nil
+	}},
+	"--read-only": {boolean: true, apply: func(value string, _ *container.Config, hostConfig *container.HostConfig) error {
+		readOnly, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		hostConfig.ReadonlyRootfs = readOnly
+		return nil
+	}},
+	"--security-opt": {apply: func(value string, _ *container.Config, hostConfig *container.HostConfig) error {
+		hostConfig.SecurityOpt = append(hostConfig.SecurityOpt, value)
+		return nil
+	}},
+	"--shm-size": {apply: func(value string, _ *container.Config, hostConfig *container.HostConfig) error {
+		size, err := units.RAMInBytes(value)
+		if err != nil {
+			return err
+		}
+		hostConfig.ShmSize = size
+		return nil
+	}},
+	"--sysctl": {apply: func(value string, _ *container.Config, hostConfig *container.HostConfig) error {
+		key, sysctlValue, ok := strings.Cut(value, "=")
+		if !ok {
+			return fmt.Errorf("expected key=value, got %q", value)
+		}
+		if hostConfig.Sysctls == nil {
+			hostConfig.Sysctls = make(map[string]string)
+		}
+		hostConfig.Sysctls[key] = sysctlValue
+		return nil
+	}},
+	"--tmpfs": {apply: func(value string, _ *container.Config, hostConfig *container.HostConfig) error {
+		path, options, _ := strings.Cut(value, ":")
+		if hostConfig.Tmpfs == nil {
+			hostConfig.Tmpfs = make(map[string]string)
+		}
+		hostConfig.Tmpfs[path] = options
+		return nil
+	}},
+	"--ulimit": {apply: func(value string, _ *container.Config, hostConfig *container.HostConfig) error {
+		ulimit, err := units.ParseUlimit(value)
+		if err != nil {
+			return err
+		}
+		hostConfig.Ulimits = append(hostConfig.Ulimits, ulimit)
+		return nil
+	}},
+	"--user": {apply: func(value string, config *container.Config, _ *container.HostConfig) error {
+		config.User = value
+		return nil
+	}},
+	"--volume": {apply: func(value string, _ *container.Config, hostConfig *container.HostConfig) error {
+		hostConfig.Binds = append(hostConfig.Binds, value)
+		return nil
+	}},
+	"--workdir": {apply: func(value string, config *container.Config, _ *container.HostConfig) error {
+		config.WorkingDir = value
+		return nil
+	}},
+}
+
+func supportedRunArgs() []string {
+	flags := make([]string, 0, len(runArgFlags))
+	for flag := range runArgFlags {
+		flags = append(flags, flag)
+	}
+	sort.Strings(flags)
+	return flags
+}
+
+// applyRunArgs translates the docker run flags of runArgs into the configs of the container. Flags take their value
+// after "=" or as the next argument, like with the Docker CLI.
+func applyRunArgs(args []string, config *container.Config, hostConfig *container.HostConfig) error {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" || arg == "--" {
+			return fmt.Errorf("Invalid runArgs argument %q, expected a flag", arg)
+		}
+
+		name, value, hasValue := strings.Cut(arg, "=")
+		if !strings.HasPrefix(arg, "--") && len(arg) > 2 {
+			// short flags can have their value attached, e.g. -m4g or -m=4g
+			name, value, hasValue = arg[:2], strings.TrimPrefix(arg[2:], "="), true
+		}
+
+		if alias, ok := runArgAliases[name]; ok {
+			name = alias
+		}
+
+		flag, ok := runArgFlags[name]
+		if !ok {
+			return NewUnsupportedRunArgError(name)
+		}
+
+		if !hasValue {
+			if flag.boolean {
+				value = "true"
+			} else {
+				if i+1 >= len(args) {
+					return fmt.Errorf("Missing value for runArgs flag %s", arg)
+				}
+				i++
+				value = args[i]
+			}
+		}
+
+		if err := flag.apply(value, config, hostConfig); err != nil {
+			return fmt.Errorf("Invalid value %q for runArgs flag %s: %w", value, name, err)
+		}
+	}
+
+	return nil
+}
+
+// applyHostRequirements sets the hostRequirements as resource limits of the container. The storage limit needs a
+// storage driver that supports the size option, e.g. overlay2 on xfs with pquota.
+func applyHostRequirements(requirements HostRequirements, hostConfig *container.HostConfig) error {
+	if requirements.Cpus > 0 {
+		hostConfig.NanoCPUs = int64(requirements.Cpus) * 1e9
+	}
+
+	if requirements.Memory != "" {
+		memory, err := units.RAMInBytes(requirements.Memory)
+		if err != nil {
+			return fmt.Errorf("Invalid hostRequirements.memory %q: %w", requirements.Memory, err)
+		}
+		hostConfig.Memory = memory
+	}
+
+	if requirements.Storage != "" {
+		storage, err := units.RAMInBytes(requirements.Storage)
+		if err != nil {
+			return fmt.Errorf("Invalid hostRequirements.storage %q: %w", requirements.Storage, err)
+		}
+		if hostConfig.StorageOpt == nil {
+			hostConfig.StorageOpt = make(map[string]string)
+		}
+		hostConfig.StorageOpt["size"] = strconv.FormatInt(storage, 10)
+	}
+
+	return nil
+}
+
+// parseDevice parses a --device value, host-path[:container-path[:permissions]].
+func parseDevice(value string) (container.DeviceMapping, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) > 3 || parts[0] == "" {
+		return container.DeviceMapping{}, fmt.Errorf("expected host-path[:container-path[:permissions]], got %q", value)
+	}
+
+	device := container.DeviceMapping{PathOnHost: parts[0], PathInContainer: parts[0], CgroupPermissions: "rwm"}
+	if len(parts) > 1 && parts[1] != "" {
+		device.PathInContainer = parts[1]
+	}
+	if len(parts) > 2 {
+		device.CgroupPermissions = parts[2]
+	}
+
+	return device, nil
+}
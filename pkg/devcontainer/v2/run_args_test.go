@@ -0,0 +1,178 @@
+package devcontainer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-units"
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
+	"github.com/hide-org/hide/pkg/devcontainer/v2/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerContainerManager_CreateContainerRunArgs(t *testing.T) {
+	t.Setenv("HIDE_TEST_TOKEN", "secret")
+
+	tests := []struct {
+		name             string
+		config           devcontainer.Config
+		assertConfig     func(t *testing.T, config *container.Config)
+		assertHostConfig func(t *testing.T, hostConfig *container.HostConfig)
+		expectedError    string
+	}{
+		{
+			name: "Applies hostRequirements as resource limits",
+			config: devcontainer.Config{
+				HostRequirements: devcontainer.HostRequirements{Cpus: 2, Memory: "4gb", Storage: "32gb"},
+			},
+			assertHostConfig: func(t *testing.T, hostConfig *container.HostConfig) {
+				assert.Equal(t, int64(2e9), hostConfig.NanoCPUs)
+				assert.Equal(t, int64(4*units.GiB), hostConfig.Memory)
+				assert.Equal(t, map[string]string{"size": "34359738368"}, hostConfig.StorageOpt)
+			},
+		},
+		{
+			name: "runArgs override hostRequirements",
+			config: devcontainer.Config{
+				HostRequirements: devcontainer.HostRequirements{Cpus: 2, Memory: "4gb"},
+				DockerImageProps: devcontainer.DockerImageProps{RunArgs: []string{"--cpus=1.5", "-m", "512m"}},
+			},
+			assertHostConfig: func(t *testing.T, hostConfig *container.HostConfig) {
+				assert.Equal(t, int64(1.5e9), hostConfig.NanoCPUs)
+				assert.Equal(t, int64(512*units.MiB), hostConfig.Memory)
+			},
+		},
+		{
+			name: "Translates docker run flags",
+			config: devcontainer.Config{
+				GeneralProperties: devcontainer.GeneralProperties{CapAdd: []string{"NET_ADMIN"}},
+				DockerImageProps: devcontainer.DockerImageProps{RunArgs: []string{
+					"--network=dev",
+					"--add-host", "host.docker.internal:host-gateway",
+					"-e", "FOO=bar",
+					"-eHIDE_TEST_TOKEN",
+					"--env=HIDE_TEST_UNSET",
+					"--shm-size=1g",
+					"--cap-add=SYS_PTRACE",
+					"--security-opt", "seccomp=unconfined",
+					"--privileged",
+					"--init=false",
+					"--gpus", "all",
+					"--ulimit", "nofile=1024:2048",
+					"--device=/dev/fuse",
+					"-l", "team=hide",
+					"-w", "/src",
+				}},
+			},
+			assertConfig: func(t *testing.T, config *container.Config) {
+				assert.Equal(t, []string{"FOO=bar", "HIDE_TEST_TOKEN=secret"}, config.Env)
+				assert.Equal(t, map[string]string{"team": "hide"}, config.Labels)
+				assert.Equal(t, "/src", config.WorkingDir)
+			},
+			assertHostConfig: func(t *testing.T, hostConfig *container.HostConfig) {
+				assert.Equal(t, container.NetworkMode("dev"), hostConfig.NetworkMode)
+				assert.Equal(t, []string{"host.docker.internal:host-gateway"}, hostConfig.ExtraHosts)
+				assert.Equal(t, int64(units.GiB), hostConfig.ShmSize)
+				assert.Equal(t, []string{"NET_ADMIN", "SYS_PTRACE"}, []string(hostConfig.CapAdd))
+				assert.Equal(t, []string{"seccomp=unconfined"}, hostConfig.SecurityOpt)
+				assert.True(t, hostConfig.Privileged)
+				assert.False(t, *hostConfig.Init)
+				assert.Equal(t, []container.DeviceRequest{{Count: -1, Capabilities: [][]string{{"gpu"}}}}, hostConfig.DeviceRequests)
+				assert.Equal(t, []*units.Ulimit{{Name: "nofile", Soft: 1024, Hard: 2048}}, hostConfig.Ulimits)
+				assert.Equal(t, []container.DeviceMapping{{PathOnHost: "/dev/fuse", PathInContainer: "/dev/fuse", CgroupPermissions: "rwm"}}, hostConfig.Devices)
+			},
+		},
+		{
+			name: "Unsupported flag",
+			config: devcontainer.Config{
+				DockerImageProps: devcontainer.DockerImageProps{RunArgs: []string{"--cpus=2", "--rm"}},
+			},
+			expectedError: "Unsupported runArgs flag --rm",
+		},
+		{
+			name: "Missing value",
+			config: devcontainer.Config{
+				DockerImageProps: devcontainer.DockerImageProps{RunArgs: []string{"--network"}},
+			},
+			expectedError: "Missing value for runArgs flag --network",
+		},
+		{
+			name: "Host network",
+			config: devcontainer.Config{
+				DockerImageProps: devcontainer.DockerImageProps{RunArgs: []string{"--net", "host"}},
+			},
+			expectedError: `Invalid value "host" for runArgs flag --network: the hide server port 8081 cannot be published with network host`,
+		},
+		{
+			name: "Container network",
+			config: devcontainer.Config{
+				DockerImageProps: devcontainer.DockerImageProps{RunArgs: []string{"--network=container:db"}},
+			},
+			expectedError: "cannot be published with network container:db",
+		},
+		{
+			name: "Invalid value",
+			config: devcontainer.Config{
+				DockerImageProps: devcontainer.DockerImageProps{RunArgs: []string{"--memory", "lots"}},
+			},
+			expectedError: `Invalid value "lots" for runArgs flag --memory`,
+		},
+		{
+			name: "Positional argument",
+			config: devcontainer.Config{
+				DockerImageProps: devcontainer.DockerImageProps{RunArgs: []string{"--privileged", "ubuntu"}},
+			},
+			expectedError: `Invalid runArgs argument "ubuntu", expected a flag`,
+		},
+		{
+			name: "Invalid hostRequirements",
+			config: devcontainer.Config{
+				HostRequirements: devcontainer.HostRequirements{Memory: "a lot"},
+			},
+			expectedError: `Invalid hostRequirements.memory "a lot"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mocks.MockDockerContainerClient{}
+			var config *container.Config
+			var hostConfig *container.HostConfig
+			if tt.expectedError == "" {
+				mockClient.On("ContainerCreate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, "").
+					Run(func(args mock.Arguments) {
+						config = args.Get(1).(*container.Config)
+						hostConfig = args.Get(2).(*container.HostConfig)
+					}).
+					Return(container.CreateResponse{ID: "test-container-id"}, nil)
+			}
+
+			_, err := devcontainer.NewDockerContainerManager(mockClient).CreateContainer(context.Background(), "test-image", "/test/project", tt.config)
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				mockClient.AssertNotCalled(t, "ContainerCreate")
+				return
+			}
+
+			require.NoError(t, err)
+			if tt.assertConfig != nil {
+				tt.assertConfig(t, config)
+			}
+			tt.assertHostConfig(t, hostConfig)
+		})
+	}
+
+	t.Run("Unsupported flags are reported as such", func(t *testing.T) {
+		_, err := devcontainer.NewDockerContainerManager(&mocks.MockDockerContainerClient{}).CreateContainer(context.Background(), "test-image", "/test/project", devcontainer.Config{
+			DockerImageProps: devcontainer.DockerImageProps{RunArgs: []string{"--name", "dev"}},
+		})
+
+		var unsupportedErr *devcontainer.UnsupportedRunArgError
+		require.ErrorAs(t, err, &unsupportedErr)
+		assert.Equal(t, "--name", unsupportedErr.Flag)
+	})
+}
@@ -3,7 +3,12 @@ package devcontainer
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sync"
 
 	"github.com/rs/zerolog/log"
 )
@@ -19,127 +24,256 @@ type Runner interface {
 	Stop(ctx context.Context, containerId string) error
 	Exec(ctx context.Context, containerId string, command []string) (ExecResult, error)
 	ExecDetached(ctx context.Context, containerId string, command []string) (string, error)
+	LifecycleLogs(containerId string) []LifecycleLog
+	// HostPort returns the host port the TCP port of the container is published on, e.g. ServerPort.
+	HostPort(ctx context.Context, containerId string, port int) (int, error)
+	// PortMappings returns the ports of the container published on the host, e.g. the app's HTTP endpoints, labeled with
+	// the portsAttributes of the config the container was run with.
+	PortMappings(ctx context.Context, containerId string, config Config) ([]PortMapping, error)
 }
 
 type DockerRunner struct {
 	commandExecutor  Executor
 	imageManager     ImageManager
 	containerManager ContainerManager
+	featureResolver  FeatureResolver
+
+	mu              sync.Mutex
+	composeProjects map[string]composeProject    // by container ID of the devcontainer service
+	lifecycleLogs   map[string]*lifecycleLogs    // by container ID
+	configs         map[string]Config            // by container ID
+	remoteEnvs      map[string]map[string]string // by container ID
+}
+
+type RunnerOption func(r *DockerRunner)
+
+// RunnerWithFeatureResolver sets the resolver of devcontainer features. By default, features are pulled from their
+// registries and cached in the user cache directory.
+func RunnerWithFeatureResolver(resolver FeatureResolver) RunnerOption {
+	return func(r *DockerRunner) {
+		r.featureResolver = resolver
+	}
 }
 
-func NewDockerRunner(commandExecutor Executor, imageManager ImageManager, containerManager ContainerManager) Runner {
-	return &DockerRunner{
+func NewDockerRunner(commandExecutor Executor, imageManager ImageManager, containerManager ContainerManager, opts ...RunnerOption) Runner {
+	r := &DockerRunner{
 		commandExecutor:  commandExecutor,
 		imageManager:     imageManager,
 		containerManager: containerManager,
+		composeProjects:  make(map[string]composeProject),
+		lifecycleLogs:    make(map[string]*lifecycleLogs),
+		configs:          make(map[string]Config),
+		remoteEnvs:       make(map[string]map[string]string),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.featureResolver == nil {
+		r.featureResolver = NewOCIFeatureResolver(http.DefaultClient, defaultFeaturesCacheDir())
 	}
+
+	return r
 }
 
+// Run starts the devcontainer and returns once the lifecycle commands up to waitFor have completed. The remaining
+// lifecycle commands run in the background, their output is available in LifecycleLogs. If a command up to waitFor
+// fails, the devcontainer is stopped. The variables of the config
+// are substituted, and the config is merged with the devcontainer.metadata label of the image.
 func (r *DockerRunner) Run(ctx context.Context, projectPath string, config Config) (string, error) {
 	log.Debug().Any("config", config).Msg("Running container")
-	// Run initialize commands
-	if command := config.LifecycleProps.InitializeCommand; command != nil {
-		if err := r.executeLifecycleCommand(command, projectPath); err != nil {
-			return "", fmt.Errorf("Failed to run initialize commands: %w", err)
-		}
-	}
 
-	// Get image
-	imageId, err := r.getImage(ctx, config, projectPath)
-	if err != nil {
-		return "", fmt.Errorf("Failed to get image: %w", err)
-	}
+	config = config.substituteLocalVariables(projectPath)
 
-	// Create container
-	containerId, err := r.containerManager.CreateContainer(ctx, imageId, projectPath, config)
+	waitFor, err := config.LifecycleProps.waitForStage()
 	if err != nil {
-		return "", fmt.Errorf("Failed to create container: %w", err)
+		return "", err
 	}
 
-	// Start container
-	if err := r.containerManager.StartContainer(ctx, containerId); err != nil {
-		return "", fmt.Errorf("Failed to start container: %w", err)
+	if _, err := config.userEnvProbeFlags(); err != nil {
+		return "", err
 	}
 
-	// Run onCreate commands
-	if command := config.LifecycleProps.OnCreateCommand; command != nil {
-		if err := r.executeLifecycleCommandInContainer(ctx, command, containerId); err != nil {
-			return "", fmt.Errorf("Failed to run onCreate commands: %w", err)
+	logs := &lifecycleLogs{}
+
+	// Run initialize commands on the host
+	if command := config.LifecycleProps.InitializeCommand; command != nil {
+		if err := r.runLifecycleStage(ctx, LifecycleInitialize, command, projectPath, "", logs); err != nil {
+			return "", fmt.Errorf("Failed to run %s: %w", LifecycleInitialize, err)
 		}
 	}
 
-	// Run updateContent commands
-	if command := config.LifecycleProps.UpdateContentCommand; command != nil {
-		if err := r.executeLifecycleCommandInContainer(ctx, command, containerId); err != nil {
-			return "", fmt.Errorf("Failed to run updateContent commands: %w", err)
-		}
+	containerId, config, err := r.startContainer(ctx, projectPath, config)
+	if err != nil {
+		return "", err
 	}
 
-	// Run postCreate commands
-	if command := config.LifecycleProps.PostCreateCommand; command != nil {
-		if err := r.executeLifecycleCommandInContainer(ctx, command, containerId); err != nil {
-			return "", fmt.Errorf("Failed to run postCreate commands: %w", err)
+	// a devcontainer that does not get ready is not left running
+	stop := func(err error) (string, error) {
+		if stopErr := r.Stop(context.WithoutCancel(ctx), containerId); stopErr != nil {
+			log.Error().Err(stopErr).Str("containerId", containerId).Msg("Failed to stop container")
 		}
+		return "", err
 	}
 
-	// Run postStart commands
-	if command := config.LifecycleProps.PostStartCommand; command != nil {
-		if err := r.executeLifecycleCommand(command, projectPath); err != nil {
-			return "", fmt.Errorf("Failed to run postStart commands: %w", err)
+	// containerEnv variables are substituted once the container is running
+	if config.usesContainerEnv() {
+		containerEnv, err := r.containerManager.ContainerEnv(ctx, containerId)
+		if err != nil {
+			return stop(fmt.Errorf("Failed to get environment of container: %w", err))
 		}
+		config = config.substituteContainerEnv(containerEnv)
 	}
 
-	// Run postAttach commands
-	if command := config.LifecycleProps.PostAttachCommand; command != nil {
-		if err := r.executeLifecycleCommand(command, projectPath); err != nil {
-			return "", fmt.Errorf("Failed to run postAttach commands: %w", err)
-		}
+	// the image metadata may set waitFor
+	if waitFor, err = config.waitForStage(); err != nil {
+		return stop(err)
 	}
 
-	return containerId, nil
-}
+	// Commands in the container run as remoteUser with its environment
+	remoteEnv := r.remoteEnv(ctx, containerId, config)
 
-func (r *DockerRunner) Stop(ctx context.Context, containerId string) error {
-	return r.containerManager.StopContainer(ctx, containerId)
-}
+	r.mu.Lock()
+	r.lifecycleLogs[containerId] = logs
+	r.configs[containerId] = config
+	r.remoteEnvs[containerId] = remoteEnv
+	r.mu.Unlock()
 
-func (r *DockerRunner) Exec(ctx context.Context, containerID string, command []string) (ExecResult, error) {
-	return r.containerManager.Exec(ctx, containerID, command)
-}
+	// Run the other commands in the container, the ones after waitFor in the background
+	stages := lifecycleStages[1:]
+	ready := slices.Index(stages, waitFor) + 1
 
-// TODO: review the design; i don't like that we simply forward the call to the container manager
-func (r *DockerRunner) ExecDetached(ctx context.Context, containerID string, command []string) (string, error) {
-	return r.containerManager.ExecDetached(ctx, containerID, command)
-}
+	if err := r.runLifecycleStages(ctx, config, projectPath, containerId, stages[:ready], logs); err != nil {
+		return stop(err)
+	}
+
+	if ready < len(stages) {
+		go func() {
+			if err := r.runLifecycleStages(context.WithoutCancel(ctx), config, projectPath, containerId, stages[ready:], logs); err != nil {
+				log.Error().Err(err).Str("containerId", containerId).Msg("Failed to run lifecycle commands")
+			}
+		}()
+	}
 
-func (r *DockerRunner) executeLifecycleCommand(lifecycleCommand LifecycleCommand, workingDir string) error {
-	for name, command := range lifecycleCommand {
-		log.Debug().Str("name", name).Str("command", fmt.Sprintf("%s", command)).Msg("Running command")
+	return containerId, nil
+}
 
-		if err := r.commandExecutor.Run(command, workingDir, os.Stdout, os.Stderr); err != nil {
-			return fmt.Errorf("Failed to run command %s %s: %w", name, command, err)
+func (r *DockerRunner) runLifecycleStages(ctx context.Context, config Config, projectPath, containerId string, stages []LifecycleStage, logs *lifecycleLogs) error {
+	for _, stage := range stages {
+		for _, command := range config.lifecycleCommands(stage) {
+			if err := r.runLifecycleStage(ctx, stage, command, projectPath, containerId, logs); err != nil {
+				return fmt.Errorf("Failed to run %s: %w", stage, err)
+			}
 		}
 	}
 
 	return nil
 }
 
-func (r *DockerRunner) executeLifecycleCommandInContainer(ctx context.Context, lifecycleCommand LifecycleCommand, containerId string) error {
-	for name, command := range lifecycleCommand {
-		log.Debug().Str("name", name).Str("command", fmt.Sprintf("%s", command)).Msg("Running command")
+// LifecycleLogs returns the output of the lifecycle commands of the container, including running ones.
+func (r *DockerRunner) LifecycleLogs(containerId string) []LifecycleLog {
+	r.mu.Lock()
+	logs, ok := r.lifecycleLogs[containerId]
+	r.mu.Unlock()
 
-		result, err := r.Exec(ctx, containerId, command)
-		if err != nil {
-			return fmt.Errorf("Failed to run command %s %s in container %s: %w", name, command, containerId, err)
+	if !ok {
+		return nil
+	}
+
+	return logs.list()
+}
+
+// startContainer starts the devcontainer, or the Docker Compose project with the devcontainer service. It returns the
+// config merged with the metadata of the image.
+// NOTE: the metadata of the images of Docker Compose services is not merged
+func (r *DockerRunner) startContainer(ctx context.Context, projectPath string, config Config) (string, Config, error) {
+	if config.IsComposeDevContainer() {
+		containerId, err := r.composeUp(ctx, projectPath, config)
+		return containerId, config, err
+	}
+
+	// Get image
+	imageId, err := r.getImage(ctx, config, projectPath)
+	if err != nil {
+		return "", config, fmt.Errorf("Failed to get image: %w", err)
+	}
+
+	// Merge the properties of the image, e.g. the remoteUser of the devcontainers images
+	metadata, err := r.imageManager.ImageMetadata(ctx, imageId)
+	if err != nil {
+		return "", config, fmt.Errorf("Failed to get image metadata: %w", err)
+	}
+	config = config.mergeImageMetadata(substitute(metadata, config.localVariableResolver(projectPath)))
+
+	// Install features
+	if len(config.Features) > 0 {
+		if imageId, err = r.installFeatures(ctx, imageId, projectPath, config); err != nil {
+			return "", config, fmt.Errorf("Failed to install features: %w", err)
 		}
+	}
 
-		if result.ExitCode != 0 {
-			return fmt.Errorf("Failed to run command %s %s in container %s: Exit code %d. Stdout: %s, Stderr: %s", name, command, containerId, result.ExitCode, result.StdOut, result.StdErr)
+	// Update the UID/GID of the user to the local ones, so that files in bind mounts have the right owner
+	if user := config.userToUpdate(); user != "" && runtime.GOOS == "linux" {
+		if imageId, err = r.imageManager.BuildUpdateUIDImage(ctx, imageId, user, os.Getuid(), os.Getgid()); err != nil {
+			return "", config, fmt.Errorf("Failed to update UID of user %s: %w", user, err)
 		}
+	}
 
+	// Create container
+	containerId, err := r.containerManager.CreateContainer(ctx, imageId, projectPath, config)
+	if err != nil {
+		return "", config, fmt.Errorf("Failed to create container: %w", err)
 	}
 
-	return nil
+	// Start container
+	if err := r.containerManager.StartContainer(ctx, containerId); err != nil {
+		return "", config, fmt.Errorf("Failed to start container: %w", err)
+	}
+
+	return containerId, config, nil
+}
+
+// installFeatures builds an image from baseImage with the features of the config installed.
+func (r *DockerRunner) installFeatures(ctx context.Context, baseImage string, projectPath string, config Config) (string, error) {
+	features, err := r.featureResolver.Resolve(ctx, projectPath, config)
+	if err != nil {
+		return "", err
+	}
+
+	return r.imageManager.BuildFeaturesImage(ctx, baseImage, features, config)
+}
+
+// Stop stops the container, or the whole Docker Compose project if the container is a Docker Compose service.
+func (r *DockerRunner) Stop(ctx context.Context, containerId string) error {
+	r.mu.Lock()
+	delete(r.lifecycleLogs, containerId)
+	delete(r.configs, containerId)
+	delete(r.remoteEnvs, containerId)
+	r.mu.Unlock()
+
+	if ok, err := r.composeDown(containerId); ok {
+		return err
+	}
+
+	return r.containerManager.StopContainer(ctx, containerId)
+}
+
+func (r *DockerRunner) HostPort(ctx context.Context, containerId string, port int) (int, error) {
+	return r.containerManager.HostPort(ctx, containerId, port)
+}
+
+func (r *DockerRunner) PortMappings(ctx context.Context, containerId string, config Config) ([]PortMapping, error) {
+	return r.containerManager.PortMappings(ctx, containerId, config)
+}
+
+func (r *DockerRunner) Exec(ctx context.Context, containerID string, command []string) (ExecResult, error) {
+	return r.containerManager.Exec(ctx, containerID, command, r.execOptions(containerID)...)
+}
+
+// TODO: review the design; i don't like that we simply forward the call to the container manager
+func (r *DockerRunner) ExecDetached(ctx context.Context, containerID string, command []string) (string, error) {
+	return r.containerManager.ExecDetached(ctx, containerID, command, r.execOptions(containerID)...)
 }
 
 func (r *DockerRunner) getImage(ctx context.Context, config Config, projectPath string) (string, error) {
@@ -152,9 +286,6 @@ func (r *DockerRunner) getImage(ctx context.Context, config Config, projectPath
 			return "", fmt.Errorf("Failed to build image: %w", err)
 		}
 		return imageId, nil
-	case config.IsComposeDevContainer():
-		// TODO: build docker-compose file
-		return "", fmt.Errorf("Docker Compose is not supported yet")
 	default:
 		return "", fmt.Errorf("Invalid devcontainer configuration")
 	}
@@ -180,3 +311,12 @@ func (r *DockerRunner) getOrPullImage(ctx context.Context, imageId string) (stri
 
 	return imageId, nil
 }
+
+func defaultFeaturesCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return filepath.Join(dir, "hide", "features")
+}
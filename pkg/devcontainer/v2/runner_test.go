@@ -5,8 +5,8 @@ import (
 	"errors"
 	"testing"
 
-	"github.com/hide-org/hide/pkg/devcontainer"
-	"github.com/hide-org/hide/pkg/devcontainer/mocks"
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
+	"github.com/hide-org/hide/pkg/devcontainer/v2/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -62,17 +62,6 @@ func TestDockerRunner_Run(t *testing.T) {
 			},
 			wantResult: "container-id",
 		},
-		{
-			name: "Failed with docker compose",
-			config: devcontainer.Config{
-				DockerComposeProps: devcontainer.DockerComposeProps{
-					DockerComposeFile: []string{"docker-compose.yml"},
-					Service:           "test-service",
-				},
-			},
-			setupMocks: func(me *mocks.MockExecutor, mim *mocks.MockImageManager, mcm *mocks.MockContainerManager) {},
-			wantError:  "Docker Compose is not supported yet",
-		},
 		{
 			name:       "Failed with invalid devcontainer",
 			config:     devcontainer.Config{},
@@ -156,6 +145,7 @@ func TestDockerRunner_Run(t *testing.T) {
 					PostCreateCommand:    devcontainer.LifecycleCommand{"command": []string{"postCreate"}},
 					PostStartCommand:     devcontainer.LifecycleCommand{"command": []string{"postStart"}},
 					PostAttachCommand:    devcontainer.LifecycleCommand{"command": []string{"postAttach"}},
+					WaitFor:              "postAttachCommand",
 				},
 			},
 			setupMocks: func(me *mocks.MockExecutor, mim *mocks.MockImageManager, mcm *mocks.MockContainerManager) {
@@ -163,11 +153,11 @@ func TestDockerRunner_Run(t *testing.T) {
 				mcm.On("CreateContainer", mock.Anything, "test-image", mock.Anything, mock.Anything).Return("container-id", nil)
 				mcm.On("StartContainer", mock.Anything, "container-id").Return(nil)
 				me.On("Run", []string{"initialize"}, mock.Anything, mock.Anything, mock.Anything).Return(nil)
-				mcm.On("Exec", mock.Anything, "container-id", []string{"onCreate"}).Return(devcontainer.ExecResult{ExitCode: 0}, nil)
-				mcm.On("Exec", mock.Anything, "container-id", []string{"updateContent"}).Return(devcontainer.ExecResult{ExitCode: 0}, nil)
-				mcm.On("Exec", mock.Anything, "container-id", []string{"postCreate"}).Return(devcontainer.ExecResult{ExitCode: 0}, nil)
-				me.On("Run", []string{"postStart"}, mock.Anything, mock.Anything, mock.Anything).Return(nil)
-				me.On("Run", []string{"postAttach"}, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				mcm.On("ExecStream", mock.Anything, "container-id", []string{"onCreate"}, mock.Anything, mock.Anything).Return(0, nil)
+				mcm.On("ExecStream", mock.Anything, "container-id", []string{"updateContent"}, mock.Anything, mock.Anything).Return(0, nil)
+				mcm.On("ExecStream", mock.Anything, "container-id", []string{"postCreate"}, mock.Anything, mock.Anything).Return(0, nil)
+				mcm.On("ExecStream", mock.Anything, "container-id", []string{"postStart"}, mock.Anything, mock.Anything).Return(0, nil)
+				mcm.On("ExecStream", mock.Anything, "container-id", []string{"postAttach"}, mock.Anything, mock.Anything).Return(0, nil)
 			},
 			wantResult: "container-id",
 		},
@@ -185,9 +175,10 @@ func TestDockerRunner_Run(t *testing.T) {
 				mim.On("LocalImageExists", mock.Anything, "test-image").Return(true, nil)
 				mcm.On("CreateContainer", mock.Anything, "test-image", mock.Anything, mock.Anything).Return("container-id", nil)
 				mcm.On("StartContainer", mock.Anything, "container-id").Return(nil)
-				mcm.On("Exec", mock.Anything, "container-id", []string{"test", "command"}).Return(devcontainer.ExecResult{ExitCode: 1, StdErr: "command failed"}, nil)
+				mcm.On("ExecStream", mock.Anything, "container-id", []string{"test", "command"}, mock.Anything, mock.Anything).Return(1, nil)
+				mcm.On("StopContainer", mock.Anything, "container-id").Return(nil)
 			},
-			wantError: "Failed to run command test [test command] in container container-id",
+			wantError: "Failed to run onCreateCommand: onCreateCommand test [test command] failed with exit code 1",
 		},
 	}
 
@@ -195,6 +186,8 @@ func TestDockerRunner_Run(t *testing.T) {
 		mockExecutor := &mocks.MockExecutor{}
 		mockImageManager := &mocks.MockImageManager{}
 		mockContainerManager := &mocks.MockContainerManager{}
+		probedUserEnv(mockContainerManager, "").Maybe()
+		noImageMetadata(mockImageManager)
 
 		t.Run(tt.name, func(t *testing.T) {
 			tt.setupMocks(mockExecutor, mockImageManager, mockContainerManager)
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"maps"
 	"slices"
+	"strconv"
 )
 
 type StringArray []string
@@ -38,6 +39,31 @@ func (c *StringArray) UnmarshalJSON(data []byte) error {
 	return fmt.Errorf("Unsupported type for StringArray: %T", jsonObj)
 }
 
+// ForwardPorts are port numbers or "host:port" values, stored as strings.
+type ForwardPorts []string
+
+func (f *ForwardPorts) UnmarshalJSON(data []byte) error {
+	var jsonObj []interface{}
+	if err := json.Unmarshal(data, &jsonObj); err != nil {
+		return fmt.Errorf("Failed to unmarshal ForwardPorts: %w", err)
+	}
+
+	ports := make([]string, 0, len(jsonObj))
+	for _, v := range jsonObj {
+		switch value := v.(type) {
+		case string:
+			ports = append(ports, value)
+		case float64:
+			ports = append(ports, strconv.Itoa(int(value)))
+		default:
+			return fmt.Errorf("Unsupported type for ForwardPorts: %T", v)
+		}
+	}
+
+	*f = ports
+	return nil
+}
+
 type LifecycleCommand map[string][]string
 
 func (c *LifecycleCommand) UnmarshalJSON(data []byte) error {
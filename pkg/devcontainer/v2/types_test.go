@@ -6,7 +6,7 @@ import (
 	"slices"
 	"testing"
 
-	"github.com/hide-org/hide/pkg/devcontainer"
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
 )
 
 type stringArrayTestStruct struct {
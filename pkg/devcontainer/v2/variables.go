@@ -0,0 +1,208 @@
+package devcontainer
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// variablePattern matches the variables of devcontainer.json, e.g. ${localEnv:HOME} or ${containerEnv:PATH:/usr/bin}.
+var variablePattern = regexp.MustCompile(`\$\{([^{}]+)\}`)
+
+// variableResolver returns the value of a variable with its arguments, e.g. localEnv with ["HOME"]. It returns false
+// for variables it does not know, which are left as they are.
+type variableResolver func(name string, args []string) (string, bool)
+
+func substituteVariables(s string, resolve variableResolver) string {
+	return variablePattern.ReplaceAllStringFunc(s, func(match string) string {
+		name, rest, hasArgs := strings.Cut(match[2:len(match)-1], ":")
+
+		var args []string
+		if hasArgs {
+			// the default value may contain colons
+			args = strings.SplitN(rest, ":", 2)
+		}
+
+		if value, ok := resolve(name, args); ok {
+			return value
+		}
+		return match
+	})
+}
+
+// envVariable returns the value of the environment variable named by the first argument, the default value of the
+// second argument or empty if it is not set.
+func envVariable(args []string, lookup func(string) (string, bool)) (string, bool) {
+	if len(args) == 0 || args[0] == "" {
+		return "", false
+	}
+
+	if value, ok := lookup(args[0]); ok {
+		return value, true
+	}
+	if len(args) > 1 {
+		return args[1], true
+	}
+	return "", true
+}
+
+// substitute returns a copy of v with the variables in all of its strings substituted, including the ones in nested
+// structs, pointers, slices, maps and interfaces. Map keys are left as they are.
+func substitute[T any](v T, resolve variableResolver) T {
+	value := reflect.ValueOf(&v).Elem()
+	return substituteValue(value, resolve).Interface().(T)
+}
+
+func substituteValue(v reflect.Value, resolve variableResolver) reflect.Value {
+	switch v.Kind() {
+	case reflect.String:
+		out := reflect.New(v.Type()).Elem()
+		out.SetString(substituteVariables(v.String(), resolve))
+		return out
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(substituteValue(v.Elem(), resolve))
+		return out
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(substituteValue(v.Elem(), resolve))
+		return out
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if field := out.Field(i); field.CanSet() {
+				field.Set(substituteValue(v.Field(i), resolve))
+			}
+		}
+		return out
+	case reflect.Slice:
+		// raw JSON is not substituted
+		if v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8 {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(substituteValue(v.Index(i), resolve))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), substituteValue(iter.Value(), resolve))
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// containerWorkspaceFolder returns the folder of the workspace in the container.
+func (c *Config) containerWorkspaceFolder() string {
+	if c.WorkspaceMount != nil && c.WorkspaceFolder != "" {
+		return c.WorkspaceFolder
+	}
+	return DefaultWorkingDir
+}
+
+// devcontainerId returns a stable identifier of the devcontainer of the project, derived from the folder of the
+// project and the folder of devcontainer.json in it, encoded like the reference implementation does.
+func devcontainerId(projectPath, configPath string) string {
+	labels, _ := json.Marshal(map[string]string{
+		"devcontainer.config_file":  filepath.Join(projectPath, configPath, "devcontainer.json"),
+		"devcontainer.local_folder": projectPath,
+	})
+	sum := sha256.Sum256(labels)
+	return fmt.Sprintf("%052s", new(big.Int).SetBytes(sum[:]).Text(32))
+}
+
+// localVariableResolver resolves the variables that are known before the container is created: localEnv (and its
+// alias env), localWorkspaceFolder, containerWorkspaceFolder, their basenames and devcontainerId.
+func (c *Config) localVariableResolver(projectPath string) variableResolver {
+	id := devcontainerId(projectPath, c.Path)
+	local := func(name string, args []string) (string, bool) {
+		switch name {
+		case "localEnv", "env":
+			return envVariable(args, os.LookupEnv)
+		case "localWorkspaceFolder":
+			return projectPath, true
+		case "localWorkspaceFolderBasename":
+			return filepath.Base(projectPath), true
+		case "devcontainerId":
+			return id, true
+		default:
+			return "", false
+		}
+	}
+
+	// the workspace folder is often derived from the local one, e.g. /workspaces/${localWorkspaceFolderBasename}
+	workspace := Config{DockerImageProps: DockerImageProps{
+		WorkspaceMount:  c.WorkspaceMount,
+		WorkspaceFolder: substituteVariables(c.WorkspaceFolder, local),
+	}}
+	folder := workspace.containerWorkspaceFolder()
+
+	return func(name string, args []string) (string, bool) {
+		switch name {
+		case "containerWorkspaceFolder":
+			return folder, true
+		case "containerWorkspaceFolderBasename":
+			return path.Base(folder), true
+		default:
+			return local(name, args)
+		}
+	}
+}
+
+// substituteLocalVariables returns the config with the variables known before the container is created substituted.
+func (c *Config) substituteLocalVariables(projectPath string) Config {
+	return substitute(*c, c.localVariableResolver(projectPath))
+}
+
+// usesContainerEnv returns whether the config references the environment of the container.
+func (c *Config) usesContainerEnv() bool {
+	var uses bool
+	substitute(*c, func(name string, args []string) (string, bool) {
+		uses = uses || name == "containerEnv"
+		return "", false
+	})
+	return uses
+}
+
+// substituteContainerEnv returns the config with the containerEnv variables substituted from the environment of the
+// container, KEY=value pairs.
+func (c *Config) substituteContainerEnv(containerEnv []string) Config {
+	env := make(map[string]string, len(containerEnv))
+	for _, entry := range containerEnv {
+		if key, value, ok := strings.Cut(entry, "="); ok {
+			env[key] = value
+		}
+	}
+
+	return substitute(*c, func(name string, args []string) (string, bool) {
+		if name != "containerEnv" {
+			return "", false
+		}
+		return envVariable(args, func(key string) (string, bool) {
+			value, ok := env[key]
+			return value, ok
+		})
+	})
+}
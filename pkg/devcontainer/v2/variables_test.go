@@ -0,0 +1,78 @@
+package devcontainer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
+	"github.com/hide-org/hide/pkg/devcontainer/v2/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerRunner_SubstituteVariables(t *testing.T) {
+	t.Setenv("HIDE_TEST_TOKEN", "secret")
+
+	config := devcontainer.Config{
+		Path: ".devcontainer",
+		DockerImageProps: devcontainer.DockerImageProps{
+			Image:           "test-image",
+			WorkspaceMount:  &devcontainer.Mount{Type: "bind", Source: "${localWorkspaceFolder}", Destination: "/workspaces/${localWorkspaceFolderBasename}"},
+			WorkspaceFolder: "/workspaces/${localWorkspaceFolderBasename}",
+		},
+		LifecycleProps: devcontainer.LifecycleProps{
+			PostCreateCommand: devcontainer.LifecycleCommand{"": []string{"/bin/sh", "-c", "echo ${containerWorkspaceFolderBasename} ${containerEnv:HOSTNAME}"}},
+			WaitFor:           "postCreateCommand",
+		},
+		GeneralProperties: devcontainer.GeneralProperties{
+			ContainerEnv: map[string]string{
+				"TOKEN":   "${localEnv:HIDE_TEST_TOKEN}",
+				"ALIAS":   "${env:HIDE_TEST_TOKEN}",
+				"DEFAULT": "${localEnv:HIDE_TEST_UNSET:a:b}",
+				"UNSET":   "${localEnv:HIDE_TEST_UNSET}",
+				"ID":      "${devcontainerId}",
+			},
+			RemoteEnv: map[string]string{
+				"PATH":      "${containerEnv:PATH}:/workspace/bin",
+				"WORKSPACE": "${containerWorkspaceFolder}",
+				"UNKNOWN":   "${unknownVariable}",
+			},
+			UserEnvProbe: devcontainer.UserEnvProbeNone,
+		},
+	}
+
+	mockImageManager := &mocks.MockImageManager{}
+	mockImageManager.On("LocalImageExists", mock.Anything, "test-image").Return(true, nil)
+	noImageMetadata(mockImageManager)
+
+	var created devcontainer.Config
+	mockContainerManager := &mocks.MockContainerManager{}
+	mockContainerManager.On("CreateContainer", mock.Anything, "test-image", "/home/dev/my-project", mock.Anything).
+		Run(func(args mock.Arguments) { created = args.Get(3).(devcontainer.Config) }).
+		Return("container-id", nil)
+	mockContainerManager.On("StartContainer", mock.Anything, "container-id").Return(nil)
+	mockContainerManager.On("ContainerEnv", mock.Anything, "container-id").Return([]string{"PATH=/usr/local/bin:/usr/bin", "HOSTNAME=abc123"}, nil)
+	options := devcontainer.ExecOptions{Env: []string{"PATH=/usr/local/bin:/usr/bin:/workspace/bin", "UNKNOWN=${unknownVariable}", "WORKSPACE=/workspaces/my-project"}}
+	mockContainerManager.On("ExecStream", mock.Anything, "container-id", []string{"/bin/sh", "-c", "echo my-project abc123"}, mock.Anything, mock.Anything, options).Return(0, nil)
+
+	runner := devcontainer.NewDockerRunner(&mocks.MockExecutor{}, mockImageManager, mockContainerManager)
+	_, err := runner.Run(context.Background(), "/home/dev/my-project", config)
+	require.NoError(t, err)
+
+	// local variables are substituted before the container is created
+	assert.Equal(t, &devcontainer.Mount{Type: "bind", Source: "/home/dev/my-project", Destination: "/workspaces/my-project"}, created.WorkspaceMount)
+	assert.Equal(t, "/workspaces/my-project", created.WorkspaceFolder)
+	assert.Equal(t, "secret", created.ContainerEnv["TOKEN"])
+	assert.Equal(t, "secret", created.ContainerEnv["ALIAS"])
+	assert.Equal(t, "a:b", created.ContainerEnv["DEFAULT"])
+	assert.Equal(t, "", created.ContainerEnv["UNSET"])
+	assert.Regexp(t, `^[0-9a-v]{52}$`, created.ContainerEnv["ID"])
+	assert.Equal(t, "${containerEnv:PATH}:/workspace/bin", created.RemoteEnv["PATH"])
+
+	// the config of the caller is not changed
+	assert.Equal(t, "${localWorkspaceFolder}", config.WorkspaceMount.Source)
+	assert.Equal(t, "${localEnv:HIDE_TEST_TOKEN}", config.ContainerEnv["TOKEN"])
+
+	mockContainerManager.AssertExpectations(t)
+}
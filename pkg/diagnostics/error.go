@@ -0,0 +1,13 @@
+package diagnostics
+
+type InvalidFilterError struct {
+	message string
+}
+
+func (e InvalidFilterError) Error() string {
+	return e.message
+}
+
+func NewInvalidFilterError(message string) *InvalidFilterError {
+	return &InvalidFilterError{message: message}
+}
@@ -0,0 +1,81 @@
+package diagnostics
+
+import (
+	"slices"
+	"strings"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+type ListOptions struct {
+	// Pull triggers a workspace diagnostics pull before listing
+	Pull       bool
+	Severities []protocol.DiagnosticSeverity
+	// Paths are glob patterns matched against paths relative to the workspace, "*" does not match "/" but "**" does
+	Paths   []string
+	Sources []string
+}
+
+type ListOption func(opts *ListOptions)
+
+func ListWithPull() ListOption {
+	return func(opts *ListOptions) {
+		opts.Pull = true
+	}
+}
+
+func ListWithSeverities(severities ...protocol.DiagnosticSeverity) ListOption {
+	return func(opts *ListOptions) {
+		opts.Severities = append(opts.Severities, severities...)
+	}
+}
+
+func ListWithPaths(patterns ...string) ListOption {
+	return func(opts *ListOptions) {
+		opts.Paths = append(opts.Paths, patterns...)
+	}
+}
+
+func ListWithSources(sources ...string) ListOption {
+	return func(opts *ListOptions) {
+		opts.Sources = append(opts.Sources, sources...)
+	}
+}
+
+func (o *ListOptions) keep(diagnostic protocol.Diagnostic) bool {
+	if len(o.Severities) > 0 {
+		// servers may omit severity, clients should treat it as an error
+		severity := protocol.DiagnosticSeverityError
+		if diagnostic.Severity != nil {
+			severity = *diagnostic.Severity
+		}
+
+		if !slices.Contains(o.Severities, severity) {
+			return false
+		}
+	}
+
+	if len(o.Sources) > 0 {
+		if diagnostic.Source == nil || !slices.Contains(o.Sources, *diagnostic.Source) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ParseSeverity parses severity names as error, warning, information (info) or hint.
+func ParseSeverity(name string) (protocol.DiagnosticSeverity, error) {
+	switch strings.ToLower(name) {
+	case "error":
+		return protocol.DiagnosticSeverityError, nil
+	case "warning":
+		return protocol.DiagnosticSeverityWarning, nil
+	case "information", "info":
+		return protocol.DiagnosticSeverityInformation, nil
+	case "hint":
+		return protocol.DiagnosticSeverityHint, nil
+	default:
+		return 0, NewInvalidFilterError("unknown severity " + name)
+	}
+}
@@ -0,0 +1,133 @@
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/rs/zerolog/log"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+
+	"github.com/hide-org/hide/pkg/lsp/v2"
+)
+
+type FileDiagnostics struct {
+	Path        string                `json:"path"`
+	Diagnostics []protocol.Diagnostic `json:"diagnostics"`
+}
+
+type Service interface {
+	// List returns diagnostics of all files in the workspace sorted by path.
+	List(ctx context.Context, opts ...ListOption) ([]FileDiagnostics, error)
+}
+
+type ServiceImpl struct {
+	workspaceDir string
+	lsp          lsp.Service
+}
+
+func NewService(lsp lsp.Service, workspaceDir string) Service {
+	return &ServiceImpl{lsp: lsp, workspaceDir: workspaceDir}
+}
+
+func (s *ServiceImpl) List(ctx context.Context, opts ...ListOption) ([]FileDiagnostics, error) {
+	opt := &ListOptions{}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	paths, err := compilePatterns(opt.Paths)
+	if err != nil {
+		return nil, err
+	}
+
+	if opt.Pull {
+		if err := s.lsp.PullWorkspaceDiagnostics(ctx); err != nil {
+			log.Error().Err(err).Msg("failed to pull workspace diagnostics")
+			return nil, fmt.Errorf("failed to pull workspace diagnostics: %w", err)
+		}
+	}
+
+	all, err := s.lsp.GetWorkspaceDiagnostics(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get workspace diagnostics")
+		return nil, fmt.Errorf("failed to get workspace diagnostics: %w", err)
+	}
+
+	result := make([]FileDiagnostics, 0, len(all))
+	for uri, diagnostics := range all {
+		path, err := s.relativePath(uri)
+		if err != nil {
+			log.Warn().Err(err).Str("uri", uri).Msg("skipping diagnostics outside of workspace")
+			continue
+		}
+
+		if len(paths) > 0 && !slices.ContainsFunc(paths, func(g glob.Glob) bool { return g.Match(filepath.ToSlash(path)) }) {
+			continue
+		}
+
+		filtered := make([]protocol.Diagnostic, 0, len(diagnostics))
+		for _, diagnostic := range diagnostics {
+			if opt.keep(diagnostic) {
+				filtered = append(filtered, diagnostic)
+			}
+		}
+
+		if len(filtered) == 0 {
+			continue
+		}
+
+		sort.SliceStable(filtered, func(i, j int) bool {
+			a, b := filtered[i].Range.Start, filtered[j].Range.Start
+			if a.Line != b.Line {
+				return a.Line < b.Line
+			}
+			return a.Character < b.Character
+		})
+
+		result = append(result, FileDiagnostics{Path: path, Diagnostics: filtered})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+
+	return result, nil
+}
+
+func (s *ServiceImpl) relativePath(uri protocol.DocumentUri) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("not a file URL")
+	}
+
+	path, err := filepath.Rel(s.workspaceDir, filepath.FromSlash(u.Path))
+	if err != nil {
+		return "", err
+	}
+	if path == ".." || strings.HasPrefix(path, "../") {
+		return "", fmt.Errorf("path %s is outside of workspace", u.Path)
+	}
+
+	return path, nil
+}
+
+func compilePatterns(patterns []string) ([]glob.Glob, error) {
+	out := make([]glob.Glob, 0, len(patterns))
+	for _, pattern := range patterns {
+		g, err := glob.Compile(pattern, '/')
+		if err != nil {
+			return nil, NewInvalidFilterError(fmt.Sprintf("invalid path pattern %s: %s", pattern, err))
+		}
+		out = append(out, g)
+	}
+	return out, nil
+}
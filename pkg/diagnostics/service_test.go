@@ -0,0 +1,188 @@
+package diagnostics_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/diagnostics"
+	"github.com/hide-org/hide/pkg/lsp/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+type fakeLsp struct {
+	lsp.Service
+	diagnostics lsp.LspDiagnostics
+	pulled      bool
+}
+
+func (f *fakeLsp) PullWorkspaceDiagnostics(ctx context.Context) error {
+	f.pulled = true
+	return nil
+}
+
+func (f *fakeLsp) GetWorkspaceDiagnostics(ctx context.Context) (lsp.LspDiagnostics, error) {
+	return f.diagnostics, nil
+}
+
+func diagnostic(line int, severity protocol.DiagnosticSeverity, source string, message string) protocol.Diagnostic {
+	d := protocol.Diagnostic{
+		Range:   protocol.Range{Start: protocol.Position{Line: protocol.UInteger(line)}},
+		Message: message,
+	}
+	if severity != 0 {
+		d.Severity = &severity
+	}
+	if source != "" {
+		d.Source = &source
+	}
+	return d
+}
+
+func TestService_List(t *testing.T) {
+	unusedVar := diagnostic(3, protocol.DiagnosticSeverityWarning, "compiler", "unused variable")
+	undefined := diagnostic(1, protocol.DiagnosticSeverityError, "compiler", "undefined: x")
+	lint := diagnostic(5, protocol.DiagnosticSeverityHint, "staticcheck", "should omit type")
+	noSeverity := diagnostic(2, 0, "", "syntax error")
+	testError := diagnostic(0, protocol.DiagnosticSeverityError, "compiler", "too many arguments")
+
+	all := lsp.LspDiagnostics{
+		"file:///workspace/main.go":              {unusedVar, undefined},
+		"file:///workspace/pkg/server/server.go": {lint, noSeverity},
+		"file:///workspace/test/main_test.go":    {testError},
+		"file:///tmp/outside.go":                 {undefined},
+		"file:///workspace/clean.go":             {},
+	}
+
+	tests := []struct {
+		name     string
+		opts     []diagnostics.ListOption
+		expected []diagnostics.FileDiagnostics
+	}{
+		{
+			name: "No filters",
+			expected: []diagnostics.FileDiagnostics{
+				{Path: "main.go", Diagnostics: []protocol.Diagnostic{undefined, unusedVar}},
+				{Path: "pkg/server/server.go", Diagnostics: []protocol.Diagnostic{noSeverity, lint}},
+				{Path: "test/main_test.go", Diagnostics: []protocol.Diagnostic{testError}},
+			},
+		},
+		{
+			name: "Severity",
+			opts: []diagnostics.ListOption{diagnostics.ListWithSeverities(protocol.DiagnosticSeverityError)},
+			expected: []diagnostics.FileDiagnostics{
+				{Path: "main.go", Diagnostics: []protocol.Diagnostic{undefined}},
+				// a missing severity counts as an error
+				{Path: "pkg/server/server.go", Diagnostics: []protocol.Diagnostic{noSeverity}},
+				{Path: "test/main_test.go", Diagnostics: []protocol.Diagnostic{testError}},
+			},
+		},
+		{
+			name: "Severities",
+			opts: []diagnostics.ListOption{diagnostics.ListWithSeverities(protocol.DiagnosticSeverityWarning, protocol.DiagnosticSeverityHint)},
+			expected: []diagnostics.FileDiagnostics{
+				{Path: "main.go", Diagnostics: []protocol.Diagnostic{unusedVar}},
+				{Path: "pkg/server/server.go", Diagnostics: []protocol.Diagnostic{lint}},
+			},
+		},
+		{
+			name: "Paths",
+			opts: []diagnostics.ListOption{diagnostics.ListWithPaths("pkg/**", "test/*_test.go")},
+			expected: []diagnostics.FileDiagnostics{
+				{Path: "pkg/server/server.go", Diagnostics: []protocol.Diagnostic{noSeverity, lint}},
+				{Path: "test/main_test.go", Diagnostics: []protocol.Diagnostic{testError}},
+			},
+		},
+		{
+			// "*" does not match "/", like in the other path patterns
+			name: "Pattern without directory",
+			opts: []diagnostics.ListOption{diagnostics.ListWithPaths("*.go")},
+			expected: []diagnostics.FileDiagnostics{
+				{Path: "main.go", Diagnostics: []protocol.Diagnostic{undefined, unusedVar}},
+			},
+		},
+		{
+			name: "Source",
+			opts: []diagnostics.ListOption{diagnostics.ListWithSources("staticcheck")},
+			expected: []diagnostics.FileDiagnostics{
+				{Path: "pkg/server/server.go", Diagnostics: []protocol.Diagnostic{lint}},
+			},
+		},
+		{
+			name: "Combined filters",
+			opts: []diagnostics.ListOption{
+				diagnostics.ListWithSeverities(protocol.DiagnosticSeverityError),
+				diagnostics.ListWithSources("compiler"),
+				diagnostics.ListWithPaths("**.go"),
+			},
+			expected: []diagnostics.FileDiagnostics{
+				{Path: "main.go", Diagnostics: []protocol.Diagnostic{undefined}},
+				{Path: "test/main_test.go", Diagnostics: []protocol.Diagnostic{testError}},
+			},
+		},
+		{
+			name:     "No match",
+			opts:     []diagnostics.ListOption{diagnostics.ListWithSources("eslint")},
+			expected: []diagnostics.FileDiagnostics{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := diagnostics.NewService(&fakeLsp{diagnostics: all}, "/workspace")
+
+			result, err := service.List(context.Background(), tt.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestService_List_Pull(t *testing.T) {
+	fake := &fakeLsp{}
+	service := diagnostics.NewService(fake, "/workspace")
+
+	_, err := service.List(context.Background())
+	require.NoError(t, err)
+	assert.False(t, fake.pulled)
+
+	_, err = service.List(context.Background(), diagnostics.ListWithPull())
+	require.NoError(t, err)
+	assert.True(t, fake.pulled)
+}
+
+func TestService_List_InvalidPath(t *testing.T) {
+	service := diagnostics.NewService(&fakeLsp{}, "/workspace")
+
+	_, err := service.List(context.Background(), diagnostics.ListWithPaths("pkg/[a"))
+	var invalidFilterError *diagnostics.InvalidFilterError
+	assert.ErrorAs(t, err, &invalidFilterError)
+}
+
+func TestParseSeverity(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected protocol.DiagnosticSeverity
+		wantErr  bool
+	}{
+		{name: "error", expected: protocol.DiagnosticSeverityError},
+		{name: "Warning", expected: protocol.DiagnosticSeverityWarning},
+		{name: "info", expected: protocol.DiagnosticSeverityInformation},
+		{name: "information", expected: protocol.DiagnosticSeverityInformation},
+		{name: "hint", expected: protocol.DiagnosticSeverityHint},
+		{name: "fatal", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			severity, err := diagnostics.ParseSeverity(tt.name)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, severity)
+		})
+	}
+}
@@ -353,17 +353,30 @@ func (s *ServiceImpl) getDiagnostics(ctx context.Context, file model.File, waitF
 		return nil, fmt.Errorf("Failed to notify didOpen while reading file %s: %w", file.Path, err)
 	}
 
-	// wait for diagnostics
-	time.Sleep(waitFor)
-
-	diagnostics, err := s.lspService.GetDiagnostics(ctx, *fileWithRealPath)
+	diagnostics, err := s.lspService.PullDiagnostics(ctx, *fileWithRealPath)
 	if err != nil {
 		var lspLanguageServerNotFoundError *lsp.LanguageServerNotFoundError
 		if errors.As(err, &lspLanguageServerNotFoundError) {
 			return nil, nil
 		}
 
-		return nil, fmt.Errorf("Failed to get diagnostics for file %s: %w", file.Path, err)
+		var lspPullDiagnosticsNotSupportedError *lsp.PullDiagnosticsNotSupportedError
+		if !errors.As(err, &lspPullDiagnosticsNotSupportedError) {
+			return nil, fmt.Errorf("Failed to pull diagnostics for file %s: %w", file.Path, err)
+		}
+
+		// fall back to diagnostics published by the server, wait for them
+		time.Sleep(waitFor)
+
+		diagnostics, err = s.lspService.GetDiagnostics(ctx, *fileWithRealPath)
+		if err != nil {
+			var lspLanguageServerNotFoundError *lsp.LanguageServerNotFoundError
+			if errors.As(err, &lspLanguageServerNotFoundError) {
+				return nil, nil
+			}
+
+			return nil, fmt.Errorf("Failed to get diagnostics for file %s: %w", file.Path, err)
+		}
 	}
 
 	if err := s.lspService.NotifyDidClose(ctx, *fileWithRealPath); err != nil {
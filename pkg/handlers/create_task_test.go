@@ -9,7 +9,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
-	"github.com/hide-org/hide/pkg/devcontainer"
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
 	"github.com/hide-org/hide/pkg/handlers"
 	"github.com/hide-org/hide/pkg/project"
 	"github.com/hide-org/hide/pkg/project/mocks"
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
+	"github.com/hide-org/hide/pkg/project"
+)
+
+type ListLifecycleLogsHandler struct {
+	Manager project.Manager
+}
+
+func (h ListLifecycleLogsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, err := getProjectID(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid project ID: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	logs, err := h.Manager.LifecycleLogs(r.Context(), projectID)
+	if err != nil {
+		var projectNotFoundError *project.ProjectNotFoundError
+		if errors.As(err, &projectNotFoundError) {
+			http.Error(w, projectNotFoundError.Error(), http.StatusNotFound)
+			return
+		}
+
+		http.Error(w, fmt.Sprintf("Failed to get lifecycle logs: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	if logs == nil {
+		logs = []devcontainer.LifecycleLog{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(logs)
+}
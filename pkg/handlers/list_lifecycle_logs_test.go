@@ -0,0 +1,76 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
+	"github.com/hide-org/hide/pkg/handlers"
+	"github.com/hide-org/hide/pkg/project"
+	"github.com/hide-org/hide/pkg/project/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListLifecycleLogsHandler_ServeHTTP(t *testing.T) {
+	startedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		target         string
+		mockManager    *mocks.MockProjectManager
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "project with logs",
+			target: "/projects/project-id/lifecycle-logs",
+			mockManager: &mocks.MockProjectManager{
+				LifecycleLogsFunc: func(ctx context.Context, projectId string) ([]devcontainer.LifecycleLog, error) {
+					return []devcontainer.LifecycleLog{
+						{Stage: devcontainer.LifecyclePostCreate, Command: []string{"make", "setup"}, Output: "done\n", StartedAt: startedAt, FinishedAt: startedAt},
+					}, nil
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"stage":"postCreateCommand","command":["make","setup"],"output":"done\n","exitCode":0,"startedAt":"2024-01-01T00:00:00Z","finishedAt":"2024-01-01T00:00:00Z"}]`,
+		},
+		{
+			name:   "project without logs",
+			target: "/projects/project-id/lifecycle-logs",
+			mockManager: &mocks.MockProjectManager{
+				LifecycleLogsFunc: func(ctx context.Context, projectId string) ([]devcontainer.LifecycleLog, error) {
+					return nil, nil
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[]`,
+		},
+		{
+			name:   "project not found",
+			target: "/projects/project-id/lifecycle-logs",
+			mockManager: &mocks.MockProjectManager{
+				LifecycleLogsFunc: func(ctx context.Context, projectId string) ([]devcontainer.LifecycleLog, error) {
+					return nil, project.NewProjectNotFoundError(projectId)
+				},
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `project project-id not found`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := handlers.ListLifecycleLogsHandler{Manager: tt.mockManager}
+			router := handlers.NewRouter().WithListLifecycleLogsHandler(handler).Build()
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.expectedBody)
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hide-org/hide/pkg/project"
+)
+
+type ListPortsHandler struct {
+	Manager project.Manager
+}
+
+func (h ListPortsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, err := getProjectID(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid project ID: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	mappings, err := h.Manager.PortMappings(r.Context(), projectID)
+	if err != nil {
+		var projectNotFoundError *project.ProjectNotFoundError
+		if errors.As(err, &projectNotFoundError) {
+			http.Error(w, projectNotFoundError.Error(), http.StatusNotFound)
+			return
+		}
+
+		http.Error(w, fmt.Sprintf("Failed to get port mappings: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(mappings)
+}
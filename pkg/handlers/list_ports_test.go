@@ -0,0 +1,74 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
+	"github.com/hide-org/hide/pkg/handlers"
+	"github.com/hide-org/hide/pkg/project"
+	"github.com/hide-org/hide/pkg/project/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListPortsHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name           string
+		target         string
+		mockManager    *mocks.MockProjectManager
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "project with ports",
+			target: "/projects/project-id/ports",
+			mockManager: &mocks.MockProjectManager{
+				PortMappingsFunc: func(ctx context.Context, projectId string) ([]devcontainer.PortMapping, error) {
+					return []devcontainer.PortMapping{
+						{ContainerPort: 3000, HostIP: "127.0.0.1", HostPort: 49153, Label: "Application", URL: "http://127.0.0.1:49153"},
+					}, nil
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"containerPort":3000,"hostIP":"127.0.0.1","hostPort":49153,"label":"Application","url":"http://127.0.0.1:49153"}]`,
+		},
+		{
+			name:   "project not found",
+			target: "/projects/project-id/ports",
+			mockManager: &mocks.MockProjectManager{
+				PortMappingsFunc: func(ctx context.Context, projectId string) ([]devcontainer.PortMapping, error) {
+					return nil, project.NewProjectNotFoundError(projectId)
+				},
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `project project-id not found`,
+		},
+		{
+			name:   "internal server error",
+			target: "/projects/project-id/ports",
+			mockManager: &mocks.MockProjectManager{
+				PortMappingsFunc: func(ctx context.Context, projectId string) ([]devcontainer.PortMapping, error) {
+					return nil, errors.New("internal error")
+				},
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `Failed to get port mappings: internal error`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := handlers.ListPortsHandler{Manager: tt.mockManager}
+			router := handlers.NewRouter().WithListPortsHandler(handler).Build()
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.expectedBody)
+		})
+	}
+}
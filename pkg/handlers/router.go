@@ -48,6 +48,16 @@ func (r *Router) WithListTasksHandler(handler http.Handler) *Router {
 	return r
 }
 
+func (r *Router) WithListPortsHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/ports", handler).Methods(http.MethodGet)
+	return r
+}
+
+func (r *Router) WithListLifecycleLogsHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/lifecycle-logs", handler).Methods(http.MethodGet)
+	return r
+}
+
 func (r *Router) WithCreateFileHandler(handler http.Handler) *Router {
 	r.Handle("/projects/{id}/files", handler).Methods(http.MethodPost)
 	return r
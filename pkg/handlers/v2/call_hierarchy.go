@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/hide-org/hide/pkg/lsp/v2"
+	"github.com/hide-org/hide/pkg/navigation"
+)
+
+const maxCallHierarchyDepth = 5
+
+type CallHierarchyHandler struct {
+	Navigation navigation.Service
+}
+
+func (h CallHierarchyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	filePath, err := GetFilePath(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid file path: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	position, err := getPosition(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	direction := lsp.CallDirectionIncoming
+	if r.URL.Query().Has("direction") {
+		direction = r.URL.Query().Get("direction")
+	}
+	if direction != lsp.CallDirectionIncoming && direction != lsp.CallDirectionOutgoing {
+		http.Error(w, fmt.Sprintf("invalid direction %s: must be %s or %s", direction, lsp.CallDirectionIncoming, lsp.CallDirectionOutgoing), http.StatusBadRequest)
+		return
+	}
+
+	depth, ok, err := parseIntQueryParam(r.URL.Query(), "depth")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !ok {
+		depth = 1
+	}
+	if depth < 1 || depth > maxCallHierarchyDepth {
+		http.Error(w, fmt.Sprintf("depth must be between 1 and %d", maxCallHierarchyDepth), http.StatusBadRequest)
+		return
+	}
+
+	items, err := h.Navigation.CallHierarchy(r.Context(), filePath, position, direction, depth)
+	if err != nil {
+		writeLanguageServerError(w, err, "failed to get call hierarchy")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(items)
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/hide-org/hide/pkg/completion"
+)
+
+const (
+	defaultCompletionLimit = 20
+	maxCompletionLimit     = 100
+)
+
+type CompletionHandler struct {
+	Completion completion.Service
+}
+
+func (h CompletionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	filePath, err := GetFilePath(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid file path: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	position, err := getPosition(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	limit, ok, err := parseIntQueryParam(r.URL.Query(), "limit")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !ok {
+		limit = defaultCompletionLimit
+	}
+	if limit < 1 || limit > maxCompletionLimit {
+		http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxCompletionLimit), http.StatusBadRequest)
+		return
+	}
+
+	opts := []completion.CompleteOption{completion.CompleteWithLimit(limit)}
+	if r.URL.Query().Has("prefix") {
+		opts = append(opts, completion.CompleteWithPrefix(r.URL.Query().Get("prefix")))
+	}
+	if r.URL.Query().Has("kind") {
+		opts = append(opts, completion.CompleteWithKinds(r.URL.Query()["kind"]...))
+	}
+
+	items, err := h.Completion.Complete(r.Context(), filePath, position, opts...)
+	if err != nil {
+		writeLanguageServerError(w, err, "failed to get completions")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(items)
+}
+
+type SignatureHelpHandler struct {
+	Completion completion.Service
+}
+
+func (h SignatureHelpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	filePath, err := GetFilePath(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid file path: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	position, err := getPosition(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	help, err := h.Completion.SignatureHelp(r.Context(), filePath, position)
+	if err != nil {
+		writeLanguageServerError(w, err, "failed to get signature help")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(help)
+}
@@ -21,7 +21,7 @@ func (h DocumentOutline) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	outline, err := h.Outline.Get(r.Context(), filePath)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("failed to create file outline: %s", err), http.StatusInternalServerError)
+		writeLanguageServerError(w, err, "failed to create file outline")
 		return
 	}
 
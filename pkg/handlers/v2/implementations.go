@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/hide-org/hide/pkg/navigation"
+)
+
+type ImplementationsHandler struct {
+	Navigation navigation.Service
+}
+
+func (h ImplementationsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	filePath, err := GetFilePath(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid file path: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	position, err := getPosition(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	locations, err := h.Navigation.Implementations(r.Context(), filePath, position)
+	if err != nil {
+		writeLanguageServerError(w, err, "failed to get implementations")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(locations)
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hide-org/hide/pkg/diagnostics"
+)
+
+type ListDiagnosticsHandler struct {
+	Diagnostics diagnostics.Service
+}
+
+func (h ListDiagnosticsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	opts, err := getListDiagnosticsOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.Diagnostics.List(r.Context(), opts...)
+	if err != nil {
+		var invalidFilterError *diagnostics.InvalidFilterError
+		if errors.As(err, &invalidFilterError) {
+			http.Error(w, invalidFilterError.Error(), http.StatusBadRequest)
+			return
+		}
+
+		http.Error(w, fmt.Sprintf("failed to list diagnostics: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+func getListDiagnosticsOptions(r *http.Request) ([]diagnostics.ListOption, error) {
+	query := r.URL.Query()
+
+	var opts []diagnostics.ListOption
+	if query.Has("pull") {
+		opts = append(opts, diagnostics.ListWithPull())
+	}
+
+	for _, name := range query["severity"] {
+		severity, err := diagnostics.ParseSeverity(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid severity: %w", err)
+		}
+		opts = append(opts, diagnostics.ListWithSeverities(severity))
+	}
+
+	if query.Has("path") {
+		opts = append(opts, diagnostics.ListWithPaths(query["path"]...))
+	}
+
+	if query.Has("source") {
+		opts = append(opts, diagnostics.ListWithSources(query["source"]...))
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/hide-org/hide/pkg/lsp/v2"
+)
+
+type ListLanguageServersHandler struct {
+	LSP lsp.Service
+}
+
+func (h ListLanguageServersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(h.LSP.Health(r.Context()))
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/hide-org/hide/pkg/outline"
+)
+
+const defaultOutlineDepth = 2
+
+type ListOutlinesHandler struct {
+	Outline outline.Service
+}
+
+func (h ListOutlinesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	depth, ok, err := parseIntQueryParam(r.URL.Query(), "depth")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !ok {
+		depth = defaultOutlineDepth
+	}
+	if depth < 0 {
+		http.Error(w, "depth must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	opts := []outline.ListOption{outline.ListWithDepth(depth)}
+	if r.URL.Query().Has("kind") {
+		opts = append(opts, outline.ListWithKinds(r.URL.Query()["kind"]...))
+	}
+
+	outlines, err := h.Outline.List(r.Context(), r.URL.Query().Get("path"), opts...)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list outlines: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	if getAcceptFormat(r) == "text/plain" {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(outline.Format(outlines)))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(outlines)
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/hide-org/hide/pkg/repomap"
+)
+
+type RepoMapHandler struct {
+	RepoMap repomap.Service
+}
+
+func (h RepoMapHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	opts := []repomap.MapOption{repomap.MapWithQuery(r.URL.Query().Get("query"))}
+
+	tokens, ok, err := parseIntQueryParam(r.URL.Query(), "tokens")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if ok {
+		if tokens < 1 {
+			http.Error(w, "tokens must be positive", http.StatusBadRequest)
+			return
+		}
+		opts = append(opts, repomap.MapWithTokenBudget(tokens))
+	}
+
+	repoMap, err := h.RepoMap.Map(r.Context(), opts...)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to create repo map: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	if getAcceptFormat(r) == "text/plain" {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(repoMap.String()))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(repoMap)
+}
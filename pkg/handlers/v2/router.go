@@ -78,6 +78,46 @@ func (r *Router) WithDocumentOutlineHandler(handler http.Handler) *Router {
 	return r
 }
 
+func (r *Router) WithListOutlinesHandler(handler http.Handler) *Router {
+	r.Handle("/outlines", handler).Methods(http.MethodGet)
+	return r
+}
+
+func (r *Router) WithRepoMapHandler(handler http.Handler) *Router {
+	r.Handle("/repo-map", handler).Methods(http.MethodGet)
+	return r
+}
+
+func (r *Router) WithListDiagnosticsHandler(handler http.Handler) *Router {
+	r.Handle("/diagnostics", handler).Methods(http.MethodGet)
+	return r
+}
+
+func (r *Router) WithListLanguageServersHandler(handler http.Handler) *Router {
+	r.Handle("/lsp/servers", handler).Methods(http.MethodGet)
+	return r
+}
+
+func (r *Router) WithCallHierarchyHandler(handler http.Handler) *Router {
+	r.Handle("/calls/{path:.*}", handler).Methods(http.MethodGet)
+	return r
+}
+
+func (r *Router) WithImplementationsHandler(handler http.Handler) *Router {
+	r.Handle("/implementations/{path:.*}", handler).Methods(http.MethodGet)
+	return r
+}
+
+func (r *Router) WithCompletionHandler(handler http.Handler) *Router {
+	r.Handle("/completions/{path:.*}", handler).Methods(http.MethodGet)
+	return r
+}
+
+func (r *Router) WithSignatureHelpHandler(handler http.Handler) *Router {
+	r.Handle("/signature-help/{path:.*}", handler).Methods(http.MethodGet)
+	return r
+}
+
 func (r *Router) Build() *mux.Router {
 	return r.Router
 }
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/gobwas/glob"
 	"github.com/hide-org/hide/pkg/lsp/v2"
 	"github.com/hide-org/hide/pkg/symbols"
 	protocol "github.com/tliron/glsp/protocol_3_16"
@@ -78,7 +79,8 @@ func (h SearchSymbolsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 
 	if r.URL.Query().Has("limit") {
 		limitParam := r.URL.Query().Get("limit")
-		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+		var err error
+		limit, err = strconv.Atoi(limitParam)
 		if err != nil {
 			http.Error(w, fmt.Sprintf("invalid limit %s: %s", limitParam, err), http.StatusBadRequest)
 			return
@@ -90,8 +92,14 @@ func (h SearchSymbolsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
+	opts, err := h.searchOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// TODO: check how we handle nil response
-	symbols, err := h.symbols.Search(r.Context(), query, h.opts.symbolFilter)
+	symbols, err := h.symbols.Search(r.Context(), query, opts...)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("failed to get workspace symbols: %s", err), http.StatusInternalServerError)
 		return
@@ -102,3 +110,56 @@ func (h SearchSymbolsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(symbols[:min(limit, len(symbols))])
 	return
 }
+
+// searchOptions reads includeKind and excludeKind, which replace the default filter, path and mode.
+func (h SearchSymbolsHandler) searchOptions(r *http.Request) ([]symbols.SearchOption, error) {
+	params := r.URL.Query()
+
+	filter := h.opts.symbolFilter
+	if params.Has("includeKind") || params.Has("excludeKind") {
+		include, err := parseSymbolKinds(params["includeKind"])
+		if err != nil {
+			return nil, err
+		}
+
+		exclude, err := parseSymbolKinds(params["excludeKind"])
+		if err != nil {
+			return nil, err
+		}
+
+		filter = lsp.NewSymbolFilter(include, exclude)
+	}
+
+	opts := []symbols.SearchOption{symbols.SearchWithFilter(filter)}
+
+	if path := params.Get("path"); path != "" {
+		if _, err := glob.Compile(path, '/'); err != nil {
+			return nil, fmt.Errorf("invalid path pattern %s: %w", path, err)
+		}
+		opts = append(opts, symbols.SearchWithPath(path))
+	}
+
+	if params.Has("mode") {
+		mode := params.Get("mode")
+		switch mode {
+		case symbols.MatchModeExact, symbols.MatchModePrefix, symbols.MatchModeFuzzy:
+			opts = append(opts, symbols.SearchWithMode(mode))
+		default:
+			return nil, fmt.Errorf("invalid mode %s: must be one of %s, %s, %s", mode, symbols.MatchModeExact, symbols.MatchModePrefix, symbols.MatchModeFuzzy)
+		}
+	}
+
+	return opts, nil
+}
+
+func parseSymbolKinds(names []string) ([]protocol.SymbolKind, error) {
+	kinds := make([]protocol.SymbolKind, 0, len(names))
+	for _, name := range names {
+		kind, ok := lsp.ParseSymbolKind(name)
+		if !ok {
+			return nil, fmt.Errorf("invalid symbol kind %s", name)
+		}
+		kinds = append(kinds, kind)
+	}
+	return kinds, nil
+}
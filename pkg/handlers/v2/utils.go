@@ -9,6 +9,7 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/lsp/v2"
 )
 
 func getProjectID(r *http.Request) (string, error) {
@@ -77,6 +78,47 @@ func parseIntQueryParam(params url.Values, paramName string) (int, bool, error)
 	return value, true, nil
 }
 
+// getPosition reads a 1-based line and a 0-based character from the query.
+func getPosition(r *http.Request) (lsp.Position, error) {
+	line, ok, err := parseIntQueryParam(r.URL.Query(), "line")
+	if err != nil {
+		return lsp.Position{}, err
+	}
+	if !ok {
+		return lsp.Position{}, errors.New("line not specified")
+	}
+	if line < 1 {
+		return lsp.Position{}, errors.New("line must be greater than 0")
+	}
+
+	character, _, err := parseIntQueryParam(r.URL.Query(), "character")
+	if err != nil {
+		return lsp.Position{}, err
+	}
+	if character < 0 {
+		return lsp.Position{}, errors.New("character must not be negative")
+	}
+
+	return lsp.Position{Line: line, Character: character}, nil
+}
+
+// writeLanguageServerError responds with 404 if the file or its language server does not exist.
+func writeLanguageServerError(w http.ResponseWriter, err error, msg string) {
+	var notFound *lsp.LanguageServerNotFoundError
+	if errors.As(err, &notFound) {
+		http.Error(w, notFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	var fileNotFound *files.FileNotFoundError
+	if errors.As(err, &fileNotFound) {
+		http.Error(w, fileNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	http.Error(w, fmt.Sprintf("%s: %s", msg, err), http.StatusInternalServerError)
+}
+
 func getAcceptFormat(r *http.Request) string {
 	return r.Header.Get("Accept")
 }
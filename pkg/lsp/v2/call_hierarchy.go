@@ -0,0 +1,178 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hide-org/hide/pkg/model"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// GetCallHierarchy implements Service.
+func (s *ServiceImpl) GetCallHierarchy(ctx context.Context, file model.File, position protocol.Position, direction CallDirection, depth int) ([]CallHierarchyItem, error) {
+	var out []CallHierarchyItem
+	err := s.withOpenDocument(ctx, file, func(client Client) error {
+		if err := s.waitReady(ctx, client); err != nil {
+			return err
+		}
+
+		items, err := client.PrepareCallHierarchy(ctx, protocol.CallHierarchyPrepareParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: DocumentURI(file.Path)},
+				Position:     position,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to prepare call hierarchy: %w", err)
+		}
+
+		out = make([]CallHierarchyItem, 0, len(items))
+		for _, item := range items {
+			result := s.callHierarchyItemFrom(item)
+
+			visited := map[string]bool{callHierarchyItemKey(item): true}
+			if result.Calls, err = s.getCalls(ctx, client, item, direction, depth, visited); err != nil {
+				return err
+			}
+
+			out = append(out, result)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// getCalls follows calls of the item up to depth levels. Items already visited on the current path are not followed again, which breaks recursion.
+func (s *ServiceImpl) getCalls(ctx context.Context, client Client, item protocol.CallHierarchyItem, direction CallDirection, depth int, visited map[string]bool) ([]CallHierarchyItem, error) {
+	if depth <= 0 {
+		return nil, nil
+	}
+
+	type call struct {
+		item   protocol.CallHierarchyItem
+		ranges []protocol.Range
+	}
+
+	var calls []call
+	switch direction {
+	case CallDirectionIncoming:
+		incoming, err := client.GetIncomingCalls(ctx, protocol.CallHierarchyIncomingCallsParams{Item: item})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get incoming calls: %w", err)
+		}
+		for _, c := range incoming {
+			calls = append(calls, call{item: c.From, ranges: c.FromRanges})
+		}
+	case CallDirectionOutgoing:
+		outgoing, err := client.GetOutgoingCalls(ctx, protocol.CallHierarchyOutgoingCallsParams{Item: item})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get outgoing calls: %w", err)
+		}
+		for _, c := range outgoing {
+			calls = append(calls, call{item: c.To, ranges: c.FromRanges})
+		}
+	default:
+		return nil, fmt.Errorf("unknown call direction %s", direction)
+	}
+
+	out := make([]CallHierarchyItem, 0, len(calls))
+	for _, c := range calls {
+		result := s.callHierarchyItemFrom(c.item)
+		for _, r := range c.ranges {
+			result.CallSites = append(result.CallSites, rangeFrom(r))
+		}
+
+		key := callHierarchyItemKey(c.item)
+		if !visited[key] {
+			visited[key] = true
+
+			var err error
+			if result.Calls, err = s.getCalls(ctx, client, c.item, direction, depth-1, visited); err != nil {
+				return nil, err
+			}
+
+			delete(visited, key)
+		}
+
+		out = append(out, result)
+	}
+
+	return out, nil
+}
+
+// GetImplementations implements Service.
+func (s *ServiceImpl) GetImplementations(ctx context.Context, file model.File, position protocol.Position) ([]Location, error) {
+	var locations []protocol.Location
+	err := s.withOpenDocument(ctx, file, func(client Client) error {
+		if err := s.waitReady(ctx, client); err != nil {
+			return err
+		}
+
+		var err error
+		locations, err = client.GetImplementations(ctx, protocol.ImplementationParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: DocumentURI(file.Path)},
+				Position:     position,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get implementations: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Location, 0, len(locations))
+	for _, location := range locations {
+		out = append(out, Location{Path: s.workspacePath(location.URI), Range: rangeFrom(location.Range)})
+	}
+
+	return out, nil
+}
+
+func (s *ServiceImpl) callHierarchyItemFrom(item protocol.CallHierarchyItem) CallHierarchyItem {
+	out := CallHierarchyItem{
+		Name:     item.Name,
+		Kind:     symbolKindToString(item.Kind),
+		Location: Location{Path: s.workspacePath(item.URI), Range: rangeFrom(item.SelectionRange)},
+	}
+
+	if item.Detail != nil {
+		out.Detail = *item.Detail
+	}
+
+	return out
+}
+
+// workspacePath returns the path of the document relative to the workspace root, or the absolute path if it is outside of it.
+func (s *ServiceImpl) workspacePath(uri protocol.DocumentUri) string {
+	path, err := removeFilePrefix(uri)
+	if err != nil {
+		return uri
+	}
+
+	root, err := removeFilePrefix(s.rootURI)
+	if err != nil {
+		return path
+	}
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+
+	return rel
+}
+
+func callHierarchyItemKey(item protocol.CallHierarchyItem) string {
+	return fmt.Sprintf("%s:%d:%d", item.URI, item.SelectionRange.Start.Line, item.SelectionRange.Start.Character)
+}
@@ -3,6 +3,7 @@ package lsp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 
 	"github.com/rs/zerolog/log"
 	"github.com/sourcegraph/jsonrpc2"
@@ -10,58 +11,79 @@ import (
 	protocol "github.com/tliron/glsp/protocol_3_16"
 )
 
-type lspHandler struct {
-	diagnosticsHandler func(protocol.PublishDiagnosticsParams)
-}
-
-func (h *lspHandler) Handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result interface{}, err error) {
-	switch req.Method {
-	case "textDocument/publishDiagnostics":
-		select {
-		case <-ctx.Done():
-			return nil, nil
-		default:
-			var params protocol.PublishDiagnosticsParams
-			if err := json.Unmarshal(*req.Params, &params); err != nil {
-				return nil, err
-			}
-			// Handler will block until completion, enables faster unblocking
-			go h.diagnosticsHandler(params)
-		}
-	}
-
-	return nil, nil
-}
-
 type Client interface {
 	GetWorkspaceSymbols(ctx context.Context, params protocol.WorkspaceSymbolParams) ([]protocol.SymbolInformation, error)
 	GetDocumentSymbols(ctx context.Context, params protocol.DocumentSymbolParams) ([]protocol.DocumentSymbol, error)
-	Initialize(ctx context.Context, params protocol.InitializeParams) (protocol.InitializeResult, error)
+	// GetCompletion returns completion items, a plain item array is returned as a complete list.
+	GetCompletion(ctx context.Context, params protocol.CompletionParams) (protocol.CompletionList, error)
+	GetSignatureHelp(ctx context.Context, params protocol.SignatureHelpParams) (*protocol.SignatureHelp, error)
+	PrepareCallHierarchy(ctx context.Context, params protocol.CallHierarchyPrepareParams) ([]protocol.CallHierarchyItem, error)
+	GetIncomingCalls(ctx context.Context, params protocol.CallHierarchyIncomingCallsParams) ([]protocol.CallHierarchyIncomingCall, error)
+	GetOutgoingCalls(ctx context.Context, params protocol.CallHierarchyOutgoingCallsParams) ([]protocol.CallHierarchyOutgoingCall, error)
+	// GetImplementations returns implementation locations, location links are converted to locations.
+	GetImplementations(ctx context.Context, params protocol.ImplementationParams) ([]protocol.Location, error)
+	Initialize(ctx context.Context, params InitializeParams) (protocol.InitializeResult, error)
 	NotifyInitialized(ctx context.Context) error
 	NotifyDidOpen(ctx context.Context, params protocol.DidOpenTextDocumentParams) error
 	NotifyDidClose(ctx context.Context, params protocol.DidCloseTextDocumentParams) error
-	// TODO: check if any LSP server supports this
-	// PullDiagnostics(ctx context.Context, params DocumentDiagnosticParams) (DocumentDiagnosticReport, error)
+	NotifyDidChangeConfiguration(ctx context.Context, params protocol.DidChangeConfigurationParams) error
+	// DiagnosticProvider returns the pull diagnostics options advertised by the server on initialize or registered later.
+	DiagnosticProvider() (DiagnosticOptions, bool)
+	PullDiagnostics(ctx context.Context, params DocumentDiagnosticParams) (DocumentDiagnosticReport, error)
+	PullWorkspaceDiagnostics(ctx context.Context, params WorkspaceDiagnosticParams) (WorkspaceDiagnosticReport, error)
+	// Progress returns work done progress currently reported by the server, e.g. indexing.
+	Progress() []Progress
+	// Ready reports whether the server has finished its work in progress.
+	Ready() bool
+	// WaitReady blocks until the server has finished its work in progress or the context is done.
+	WaitReady(ctx context.Context) error
+	// Ping checks that the server still responds to requests.
+	Ping(ctx context.Context) error
+	// Done is closed when the server process exits or the connection to it is lost.
+	Done() <-chan struct{}
+	// Kill stops the server process without the shutdown handshake.
+	Kill() error
 	Shutdown(ctx context.Context) error
 }
 type ClientImpl struct {
 	conn               Connection
 	server             Process
-	diagnosticsChannel chan protocol.PublishDiagnosticsParams
+	handler            *lspHandler
+	done               chan struct{}
+	diagnosticProvider *DiagnosticOptions
+}
+
+type ClientConfig struct {
+	// Settings are returned to the server on workspace/configuration requests
+	Settings json.RawMessage
+	// WorkspaceFolders are returned to the server on workspace/workspaceFolders requests
+	WorkspaceFolders []protocol.WorkspaceFolder
 }
 
+// Diagnostics receives published diagnostics until the client is done.
 type Diagnostics <-chan protocol.PublishDiagnosticsParams
 
-func NewClient(server Process) (Client, Diagnostics) {
+func NewClient(server Process, config ClientConfig) (Client, Diagnostics) {
 	d := make(chan protocol.PublishDiagnosticsParams)
+	done := make(chan struct{})
 
-	handler := &lspHandler{
-		diagnosticsHandler: func(params protocol.PublishDiagnosticsParams) {
-			d <- params
-		},
-	}
+	handler := newLspHandler(config, func(params protocol.PublishDiagnosticsParams) {
+		select {
+		case d <- params:
+		case <-done:
+		}
+	})
 	conn := NewConnection(context.Background(), server.ReadWriteCloser(), jsonrpc2.HandlerWithError(handler.Handle))
-	return &ClientImpl{conn: conn, server: server, diagnosticsChannel: d}, d
+
+	go func() {
+		select {
+		case <-server.Done():
+		case <-conn.DisconnectNotify():
+		}
+		close(done)
+	}()
+
+	return &ClientImpl{conn: conn, server: server, handler: handler, done: done}, d
 }
 
 func (c *ClientImpl) GetWorkspaceSymbols(ctx context.Context, params protocol.WorkspaceSymbolParams) ([]protocol.SymbolInformation, error) {
@@ -76,12 +98,82 @@ func (c *ClientImpl) GetDocumentSymbols(ctx context.Context, params protocol.Doc
 	return result, err
 }
 
-func (c *ClientImpl) Initialize(ctx context.Context, params protocol.InitializeParams) (protocol.InitializeResult, error) {
-	var result protocol.InitializeResult
-	err := c.conn.Call(ctx, "initialize", params, &result)
+func (c *ClientImpl) GetCompletion(ctx context.Context, params protocol.CompletionParams) (protocol.CompletionList, error) {
+	var raw json.RawMessage
+	if err := c.conn.Call(ctx, "textDocument/completion", params, &raw); err != nil {
+		return protocol.CompletionList{}, err
+	}
+
+	if len(raw) == 0 || string(raw) == "null" {
+		return protocol.CompletionList{}, nil
+	}
+
+	var items []protocol.CompletionItem
+	if err := json.Unmarshal(raw, &items); err == nil {
+		return protocol.CompletionList{Items: items}, nil
+	}
+
+	var list protocol.CompletionList
+	err := json.Unmarshal(raw, &list)
+	return list, err
+}
+
+func (c *ClientImpl) GetSignatureHelp(ctx context.Context, params protocol.SignatureHelpParams) (*protocol.SignatureHelp, error) {
+	var result *protocol.SignatureHelp
+	err := c.conn.Call(ctx, "textDocument/signatureHelp", params, &result)
+	return result, err
+}
+
+func (c *ClientImpl) PrepareCallHierarchy(ctx context.Context, params protocol.CallHierarchyPrepareParams) ([]protocol.CallHierarchyItem, error) {
+	var result []protocol.CallHierarchyItem
+	err := c.conn.Call(ctx, "textDocument/prepareCallHierarchy", params, &result)
+	return result, err
+}
+
+func (c *ClientImpl) GetIncomingCalls(ctx context.Context, params protocol.CallHierarchyIncomingCallsParams) ([]protocol.CallHierarchyIncomingCall, error) {
+	var result []protocol.CallHierarchyIncomingCall
+	err := c.conn.Call(ctx, "callHierarchy/incomingCalls", params, &result)
+	return result, err
+}
+
+func (c *ClientImpl) GetOutgoingCalls(ctx context.Context, params protocol.CallHierarchyOutgoingCallsParams) ([]protocol.CallHierarchyOutgoingCall, error) {
+	var result []protocol.CallHierarchyOutgoingCall
+	err := c.conn.Call(ctx, "callHierarchy/outgoingCalls", params, &result)
 	return result, err
 }
 
+func (c *ClientImpl) GetImplementations(ctx context.Context, params protocol.ImplementationParams) ([]protocol.Location, error) {
+	var raw json.RawMessage
+	if err := c.conn.Call(ctx, "textDocument/implementation", params, &raw); err != nil {
+		return nil, err
+	}
+
+	return parseLocations(raw)
+}
+
+func (c *ClientImpl) Initialize(ctx context.Context, params InitializeParams) (protocol.InitializeResult, error) {
+	var raw json.RawMessage
+	if err := c.conn.Call(ctx, "initialize", params, &raw); err != nil {
+		return protocol.InitializeResult{}, err
+	}
+
+	var result protocol.InitializeResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return protocol.InitializeResult{}, err
+	}
+
+	// protocol_3_16 drops capabilities introduced in 3.17, read them separately
+	var ext struct {
+		Capabilities ServerCapabilities `json:"capabilities"`
+	}
+	if err := json.Unmarshal(raw, &ext); err != nil {
+		return protocol.InitializeResult{}, err
+	}
+	c.diagnosticProvider = ext.Capabilities.DiagnosticProvider
+
+	return result, nil
+}
+
 func (c *ClientImpl) NotifyInitialized(ctx context.Context) error {
 	return c.conn.Notify(ctx, "initialized", nil)
 }
@@ -94,11 +186,72 @@ func (c *ClientImpl) NotifyDidClose(ctx context.Context, params protocol.DidClos
 	return c.conn.Notify(ctx, "textDocument/didClose", params)
 }
 
-// func (c *ClientImpl) PullDiagnostics(ctx context.Context, params DocumentDiagnosticParams) (DocumentDiagnosticReport, error) {
-// 	var result DocumentDiagnosticReport
-// 	err := c.conn.Call(ctx, "textDocument/diagnostic", params, &result)
-// 	return result, err
-// }
+func (c *ClientImpl) NotifyDidChangeConfiguration(ctx context.Context, params protocol.DidChangeConfigurationParams) error {
+	return c.conn.Notify(ctx, "workspace/didChangeConfiguration", params)
+}
+
+func (c *ClientImpl) DiagnosticProvider() (DiagnosticOptions, bool) {
+	if c.diagnosticProvider != nil {
+		return *c.diagnosticProvider, true
+	}
+
+	// some servers register pull diagnostics dynamically after initialize
+	raw, ok := c.handler.registration("textDocument/diagnostic")
+	if !ok {
+		return DiagnosticOptions{}, false
+	}
+
+	var options DiagnosticOptions
+	if err := json.Unmarshal(raw, &options); err != nil {
+		return DiagnosticOptions{}, false
+	}
+	return options, true
+}
+
+func (c *ClientImpl) Progress() []Progress {
+	return c.handler.progress.list()
+}
+
+func (c *ClientImpl) Ready() bool {
+	return c.handler.progress.ready()
+}
+
+func (c *ClientImpl) WaitReady(ctx context.Context) error {
+	return c.handler.progress.wait(ctx)
+}
+
+func (c *ClientImpl) PullDiagnostics(ctx context.Context, params DocumentDiagnosticParams) (DocumentDiagnosticReport, error) {
+	var result DocumentDiagnosticReport
+	err := c.conn.Call(ctx, "textDocument/diagnostic", params, &result)
+	return result, err
+}
+
+func (c *ClientImpl) PullWorkspaceDiagnostics(ctx context.Context, params WorkspaceDiagnosticParams) (WorkspaceDiagnosticReport, error) {
+	var result WorkspaceDiagnosticReport
+	err := c.conn.Call(ctx, "workspace/diagnostic", params, &result)
+	return result, err
+}
+
+func (c *ClientImpl) Ping(ctx context.Context) error {
+	// servers must answer unknown $/ requests with MethodNotFound, any response means the server is alive
+	err := c.conn.Call(ctx, "$/hide/ping", nil, nil)
+
+	var rpcErr *jsonrpc2.Error
+	if err == nil || errors.As(err, &rpcErr) {
+		return nil
+	}
+
+	return err
+}
+
+func (c *ClientImpl) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *ClientImpl) Kill() error {
+	c.conn.Close()
+	return c.server.Stop()
+}
 
 func (c *ClientImpl) Shutdown(ctx context.Context) error {
 	err := c.conn.Call(ctx, "shutdown", nil, nil)
@@ -113,7 +266,38 @@ func (c *ClientImpl) Shutdown(ctx context.Context) error {
 		return err
 	}
 
-	close(c.diagnosticsChannel)
-
 	return c.server.Wait()
 }
+
+// parseLocations parses a Location | Location[] | LocationLink[] | null result.
+func parseLocations(raw json.RawMessage) ([]protocol.Location, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		// single location
+		items = []json.RawMessage{raw}
+	}
+
+	out := make([]protocol.Location, 0, len(items))
+	for _, item := range items {
+		var location struct {
+			protocol.Location
+			TargetURI            protocol.DocumentUri `json:"targetUri"`
+			TargetSelectionRange protocol.Range       `json:"targetSelectionRange"`
+		}
+		if err := json.Unmarshal(item, &location); err != nil {
+			return nil, err
+		}
+
+		if location.TargetURI != "" {
+			out = append(out, protocol.Location{URI: location.TargetURI, Range: location.TargetSelectionRange})
+		} else {
+			out = append(out, location.Location)
+		}
+	}
+
+	return out, nil
+}
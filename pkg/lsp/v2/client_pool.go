@@ -31,7 +31,11 @@ func (c *ClientPoolImpl) GetAll() map[lang.LanguageID]Client {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	return c.clients
+	out := make(map[lang.LanguageID]Client, len(c.clients))
+	for languageId, client := range c.clients {
+		out[languageId] = client
+	}
+	return out
 }
 
 func (c *ClientPoolImpl) Set(languageId lang.LanguageID, client Client) {
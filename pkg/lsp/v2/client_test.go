@@ -0,0 +1,54 @@
+package lsp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+func TestParseLocations(t *testing.T) {
+	location := protocol.Location{
+		URI: "file:///workspace/main.go",
+		Range: protocol.Range{
+			Start: protocol.Position{Line: 1, Character: 5},
+			End:   protocol.Position{Line: 1, Character: 9},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		raw      string
+		expected []protocol.Location
+	}{
+		{
+			name:     "null",
+			raw:      `null`,
+			expected: nil,
+		},
+		{
+			name:     "single location",
+			raw:      `{"uri":"file:///workspace/main.go","range":{"start":{"line":1,"character":5},"end":{"line":1,"character":9}}}`,
+			expected: []protocol.Location{location},
+		},
+		{
+			name:     "locations",
+			raw:      `[{"uri":"file:///workspace/main.go","range":{"start":{"line":1,"character":5},"end":{"line":1,"character":9}}}]`,
+			expected: []protocol.Location{location},
+		},
+		{
+			name:     "location links",
+			raw:      `[{"targetUri":"file:///workspace/main.go","targetRange":{"start":{"line":0,"character":0},"end":{"line":3,"character":1}},"targetSelectionRange":{"start":{"line":1,"character":5},"end":{"line":1,"character":9}}}]`,
+			expected: []protocol.Location{location},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseLocations(json.RawMessage(tt.raw))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/hide-org/hide/pkg/model"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// documentation is trimmed to keep responses small enough for LLM context
+const maxDocumentationLength = 200
+
+// GetCompletions implements Service.
+func (s *ServiceImpl) GetCompletions(ctx context.Context, file model.File, position protocol.Position) ([]CompletionItem, error) {
+	var list protocol.CompletionList
+	err := s.withOpenDocument(ctx, file, func(client Client) error {
+		var err error
+		list, err = client.GetCompletion(ctx, protocol.CompletionParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: DocumentURI(file.Path)},
+				Position:     position,
+			},
+			Context: &protocol.CompletionContext{TriggerKind: protocol.CompletionTriggerKindInvoked},
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// servers rank items with sortText
+	sort.SliceStable(list.Items, func(i, j int) bool {
+		return sortText(list.Items[i]) < sortText(list.Items[j])
+	})
+
+	out := make([]CompletionItem, 0, len(list.Items))
+	for _, item := range list.Items {
+		out = append(out, completionItemFrom(item))
+	}
+
+	return out, nil
+}
+
+// GetSignatureHelp implements Service.
+func (s *ServiceImpl) GetSignatureHelp(ctx context.Context, file model.File, position protocol.Position) (SignatureHelp, error) {
+	var help *protocol.SignatureHelp
+	err := s.withOpenDocument(ctx, file, func(client Client) error {
+		var err error
+		help, err = client.GetSignatureHelp(ctx, protocol.SignatureHelpParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: DocumentURI(file.Path)},
+				Position:     position,
+			},
+		})
+		return err
+	})
+	if err != nil {
+		return SignatureHelp{}, err
+	}
+
+	return signatureHelpFrom(help), nil
+}
+
+// withOpenDocument calls fn with the file opened in the language server. Files opened here are closed afterwards.
+func (s *ServiceImpl) withOpenDocument(ctx context.Context, file model.File, fn func(client Client) error) error {
+	languageId := s.languageDetector.DetectLanguage(&file)
+	client, ok := s.getClient(ctx, languageId)
+	if !ok {
+		return NewLanguageServerNotFoundError(languageId)
+	}
+
+	s.mu.Lock()
+	_, open := s.openDocuments[DocumentURI(file.Path)]
+	s.mu.Unlock()
+
+	if !open {
+		if err := s.NotifyDidOpen(ctx, file); err != nil {
+			return fmt.Errorf("failed to open document: %w", err)
+		}
+		defer s.NotifyDidClose(ctx, file)
+	}
+
+	return fn(client)
+}
+
+func completionItemFrom(item protocol.CompletionItem) CompletionItem {
+	out := CompletionItem{
+		Label:         item.Label,
+		Documentation: compactDocumentation(item.Documentation),
+		FilterText:    item.Label,
+	}
+
+	if item.Kind != nil {
+		out.Kind = completionItemKindToString(*item.Kind)
+	}
+	if item.Detail != nil {
+		out.Detail = *item.Detail
+	}
+	if item.FilterText != nil {
+		out.FilterText = *item.FilterText
+	}
+
+	return out
+}
+
+func signatureHelpFrom(help *protocol.SignatureHelp) SignatureHelp {
+	if help == nil {
+		return SignatureHelp{Signatures: []Signature{}}
+	}
+
+	out := SignatureHelp{Signatures: make([]Signature, 0, len(help.Signatures))}
+	if help.ActiveSignature != nil {
+		out.ActiveSignature = int(*help.ActiveSignature)
+	}
+	if help.ActiveParameter != nil {
+		out.ActiveParameter = int(*help.ActiveParameter)
+	}
+
+	for _, signature := range help.Signatures {
+		s := Signature{
+			Label:         signature.Label,
+			Documentation: compactDocumentation(signature.Documentation),
+		}
+
+		for _, parameter := range signature.Parameters {
+			s.Parameters = append(s.Parameters, Parameter{
+				Label:         parameterLabel(signature.Label, parameter.Label),
+				Documentation: compactDocumentation(parameter.Documentation),
+			})
+		}
+
+		out.Signatures = append(out.Signatures, s)
+	}
+
+	return out
+}
+
+// parameterLabel resolves a label given as [start, end) UTF-16 offsets into the signature label.
+func parameterLabel(signature string, label any) string {
+	switch l := label.(type) {
+	case string:
+		return l
+	case []protocol.UInteger:
+		if len(l) != 2 {
+			return ""
+		}
+
+		encoded := utf16.Encode([]rune(signature))
+		start, end := int(l[0]), int(l[1])
+		if start > end || end > len(encoded) {
+			return ""
+		}
+		return string(utf16.Decode(encoded[start:end]))
+	default:
+		return ""
+	}
+}
+
+// compactDocumentation returns the first paragraph of the documentation, collapsed to one line and trimmed to maxDocumentationLength.
+func compactDocumentation(documentation any) string {
+	var text string
+	switch d := documentation.(type) {
+	case string:
+		text = d
+	case protocol.MarkupContent:
+		text = d.Value
+	default:
+		return ""
+	}
+
+	text = strings.TrimSpace(text)
+	if paragraph, _, ok := strings.Cut(text, "\n\n"); ok {
+		text = paragraph
+	}
+	text = strings.Join(strings.Fields(text), " ")
+
+	if runes := []rune(text); len(runes) > maxDocumentationLength {
+		text = strings.TrimSpace(string(runes[:maxDocumentationLength])) + "..."
+	}
+
+	return text
+}
+
+func sortText(item protocol.CompletionItem) string {
+	if item.SortText != nil {
+		return *item.SortText
+	}
+	return item.Label
+}
+
+func completionItemKindToString(kind protocol.CompletionItemKind) string {
+	switch kind {
+	case protocol.CompletionItemKindText:
+		return "Text"
+	case protocol.CompletionItemKindMethod:
+		return "Method"
+	case protocol.CompletionItemKindFunction:
+		return "Function"
+	case protocol.CompletionItemKindConstructor:
+		return "Constructor"
+	case protocol.CompletionItemKindField:
+		return "Field"
+	case protocol.CompletionItemKindVariable:
+		return "Variable"
+	case protocol.CompletionItemKindClass:
+		return "Class"
+	case protocol.CompletionItemKindInterface:
+		return "Interface"
+	case protocol.CompletionItemKindModule:
+		return "Module"
+	case protocol.CompletionItemKindProperty:
+		return "Property"
+	case protocol.CompletionItemKindUnit:
+		return "Unit"
+	case protocol.CompletionItemKindValue:
+		return "Value"
+	case protocol.CompletionItemKindEnum:
+		return "Enum"
+	case protocol.CompletionItemKindKeyword:
+		return "Keyword"
+	case protocol.CompletionItemKindSnippet:
+		return "Snippet"
+	case protocol.CompletionItemKindColor:
+		return "Color"
+	case protocol.CompletionItemKindFile:
+		return "File"
+	case protocol.CompletionItemKindReference:
+		return "Reference"
+	case protocol.CompletionItemKindFolder:
+		return "Folder"
+	case protocol.CompletionItemKindEnumMember:
+		return "EnumMember"
+	case protocol.CompletionItemKindConstant:
+		return "Constant"
+	case protocol.CompletionItemKindStruct:
+		return "Struct"
+	case protocol.CompletionItemKindEvent:
+		return "Event"
+	case protocol.CompletionItemKindOperator:
+		return "Operator"
+	case protocol.CompletionItemKindTypeParameter:
+		return "TypeParameter"
+	default:
+		return "Unknown"
+	}
+}
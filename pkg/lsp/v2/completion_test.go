@@ -0,0 +1,44 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+func TestCompactDocumentation(t *testing.T) {
+	tests := []struct {
+		name          string
+		documentation any
+		want          string
+	}{
+		{name: "nil", documentation: nil, want: ""},
+		{name: "string", documentation: "  Println formats\n  using the default formats.  ", want: "Println formats using the default formats."},
+		{name: "markup keeps first paragraph", documentation: protocol.MarkupContent{Kind: protocol.MarkupKindMarkdown, Value: "Summary.\n\nDetails."}, want: "Summary."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, compactDocumentation(tt.documentation))
+		})
+	}
+}
+
+func TestParameterLabel(t *testing.T) {
+	tests := []struct {
+		name  string
+		label any
+		want  string
+	}{
+		{name: "string", label: "a int", want: "a int"},
+		{name: "offsets", label: []protocol.UInteger{9, 14}, want: "b int"},
+		{name: "out of range", label: []protocol.UInteger{9, 100}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parameterLabel("f(a int, b int)", tt.label))
+		})
+	}
+}
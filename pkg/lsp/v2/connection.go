@@ -10,6 +10,9 @@ import (
 type Connection interface {
 	Call(ctx context.Context, method string, params interface{}, result interface{}) error
 	Notify(ctx context.Context, method string, params interface{}) error
+	Close() error
+	// DisconnectNotify is closed when the connection is closed.
+	DisconnectNotify() <-chan struct{}
 }
 
 type ConnectionImpl struct {
@@ -32,3 +35,13 @@ func (c *ConnectionImpl) Call(ctx context.Context, method string, params interfa
 func (c *ConnectionImpl) Notify(ctx context.Context, method string, params interface{}) error {
 	return c.conn.Notify(ctx, method, params)
 }
+
+// Close implements Connection.
+func (c *ConnectionImpl) Close() error {
+	return c.conn.Close()
+}
+
+// DisconnectNotify implements Connection.
+func (c *ConnectionImpl) DisconnectNotify() <-chan struct{} {
+	return c.conn.DisconnectNotify()
+}
@@ -9,7 +9,14 @@ import (
 // In memory store for diagnostics. Applies mutex locking for concurrent access.
 type DiagnosticsStore struct {
 	diagnostics map[protocol.DocumentUri][]protocol.Diagnostic
-	mu          sync.Mutex
+	// result ids of pulled diagnostics, sent back as previousResultId to the server that reported them
+	resultIDs map[protocol.DocumentUri]resultID
+	mu        sync.Mutex
+}
+
+type resultID struct {
+	client Client
+	value  string
 }
 
 func (d *DiagnosticsStore) Get(uri protocol.DocumentUri) ([]protocol.Diagnostic, bool) {
@@ -25,11 +32,69 @@ func (d *DiagnosticsStore) Set(uri protocol.DocumentUri, diagnostics []protocol.
 	defer d.mu.Unlock()
 
 	d.diagnostics[uri] = diagnostics
+	// pushed diagnostics invalidate the previous pull result
+	delete(d.resultIDs, uri)
 	return
 }
 
+func (d *DiagnosticsStore) GetAll() LspDiagnostics {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	out := make(LspDiagnostics, len(d.diagnostics))
+	for uri, diagnostics := range d.diagnostics {
+		out[uri] = diagnostics
+	}
+	return out
+}
+
+// GetResultID returns the result id of the diagnostics of the document pulled from the client.
+func (d *DiagnosticsStore) GetResultID(client Client, uri protocol.DocumentUri) (string, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	id, ok := d.resultIDs[uri]
+	if !ok || id.client != client {
+		return "", false
+	}
+	return id.value, true
+}
+
+// GetResultIDs returns the result ids of all diagnostics pulled from the client.
+func (d *DiagnosticsStore) GetResultIDs(client Client) []PreviousResultID {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	out := make([]PreviousResultID, 0, len(d.resultIDs))
+	for uri, id := range d.resultIDs {
+		if id.client == client {
+			out = append(out, PreviousResultID{URI: uri, Value: id.value})
+		}
+	}
+	return out
+}
+
+// SetReport stores a report pulled from the client. Unchanged reports keep the stored diagnostics.
+func (d *DiagnosticsStore) SetReport(client Client, uri protocol.DocumentUri, report DocumentDiagnosticReport) []protocol.Diagnostic {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if report.ResultID != nil {
+		d.resultIDs[uri] = resultID{client: client, value: *report.ResultID}
+	} else {
+		delete(d.resultIDs, uri)
+	}
+
+	if report.Kind == DocumentDiagnosticReportKindFull {
+		d.diagnostics[uri] = report.Items
+	}
+
+	return d.diagnostics[uri]
+}
+
 func NewDiagnosticsStore() *DiagnosticsStore {
 	return &DiagnosticsStore{
 		diagnostics: make(map[protocol.DocumentUri][]protocol.Diagnostic),
+		resultIDs:   make(map[protocol.DocumentUri]resultID),
 	}
 }
@@ -41,3 +41,15 @@ func (e LanguageServerNotFoundError) Error() string {
 func NewLanguageServerNotFoundError(languageId lang.LanguageID) *LanguageServerNotFoundError {
 	return &LanguageServerNotFoundError{LanguageID: languageId}
 }
+
+type PullDiagnosticsNotSupportedError struct {
+	LanguageID lang.LanguageID
+}
+
+func (e PullDiagnosticsNotSupportedError) Error() string {
+	return fmt.Sprintf("language server for language %s does not support pull diagnostics", e.LanguageID)
+}
+
+func NewPullDiagnosticsNotSupportedError(languageId lang.LanguageID) *PullDiagnosticsNotSupportedError {
+	return &PullDiagnosticsNotSupportedError{LanguageID: languageId}
+}
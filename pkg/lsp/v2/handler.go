@@ -0,0 +1,224 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/sourcegraph/jsonrpc2"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// lspHandler answers requests and notifications sent by the server.
+type lspHandler struct {
+	settings           json.RawMessage
+	workspaceFolders   []protocol.WorkspaceFolder
+	progress           *progressTracker
+	diagnosticsHandler func(protocol.PublishDiagnosticsParams)
+
+	mu            sync.Mutex
+	registrations map[string]protocol.Registration // keyed by registration id
+}
+
+func newLspHandler(config ClientConfig, diagnosticsHandler func(protocol.PublishDiagnosticsParams)) *lspHandler {
+	return &lspHandler{
+		settings:           config.Settings,
+		workspaceFolders:   config.WorkspaceFolders,
+		progress:           newProgressTracker(),
+		diagnosticsHandler: diagnosticsHandler,
+		registrations:      make(map[string]protocol.Registration),
+	}
+}
+
+func (h *lspHandler) Handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result interface{}, err error) {
+	switch req.Method {
+	case "textDocument/publishDiagnostics":
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		default:
+			var params protocol.PublishDiagnosticsParams
+			if err := unmarshalParams(req, &params); err != nil {
+				return nil, err
+			}
+			// Handler will block until completion, enables faster unblocking
+			go h.diagnosticsHandler(params)
+		}
+		return nil, nil
+
+	case "window/workDoneProgress/create":
+		var params workDoneProgressCreateParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		h.progress.create(params.Token)
+		return nil, nil
+
+	case "$/progress":
+		var params progressParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		h.progress.update(params)
+		log.Debug().Str("token", string(params.Token)).Str("kind", params.Value.Kind).Str("title", params.Value.Title).Msg(params.Value.Message)
+		return nil, nil
+
+	case "client/registerCapability":
+		var params protocol.RegistrationParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		h.register(params.Registrations)
+		return nil, nil
+
+	case "client/unregisterCapability":
+		var params protocol.UnregistrationParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		h.unregister(params.Unregisterations)
+		return nil, nil
+
+	case "workspace/configuration":
+		var params protocol.ConfigurationParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		return h.configuration(params)
+
+	case "workspace/workspaceFolders":
+		return h.workspaceFolders, nil
+
+	case "workspace/applyEdit":
+		// edits are applied by the agent through the files API, never by the server
+		return protocol.ApplyWorkspaceEditResponse{Applied: false, FailureReason: stringPointer("workspace edits are not supported")}, nil
+
+	case "window/showMessage", "window/logMessage":
+		var params protocol.ShowMessageParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		logMessage(req.Method, params.Type, params.Message)
+		return nil, nil
+
+	case "window/showMessageRequest":
+		var params protocol.ShowMessageRequestParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		logMessage(req.Method, params.Type, params.Message)
+		// nobody to pick an action
+		return nil, nil
+
+	case "window/showDocument":
+		return protocol.ShowDocumentResult{Success: false}, nil
+
+	case "workspace/semanticTokens/refresh", "workspace/codeLens/refresh", "workspace/inlayHint/refresh", "workspace/inlineValue/refresh", "workspace/diagnostic/refresh":
+		// nothing is cached on our side
+		return nil, nil
+
+	case "telemetry/event":
+		return nil, nil
+	}
+
+	if req.Notif || strings.HasPrefix(req.Method, "$/") {
+		// unknown notifications and $/ requests can be ignored
+		return nil, nil
+	}
+
+	log.Debug().Str("method", req.Method).Msg("Unsupported request from language server")
+	return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeMethodNotFound, Message: fmt.Sprintf("method not supported: %s", req.Method)}
+}
+
+func (h *lspHandler) configuration(params protocol.ConfigurationParams) ([]interface{}, error) {
+	// result must have an entry for every requested item, missing sections are null
+	result := make([]interface{}, len(params.Items))
+	for i, item := range params.Items {
+		var section string
+		if item.Section != nil {
+			section = *item.Section
+		}
+
+		value, err := settingsSection(h.settings, section)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = value
+	}
+	return result, nil
+}
+
+func (h *lspHandler) register(registrations []protocol.Registration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, registration := range registrations {
+		log.Debug().Str("id", registration.ID).Str("method", registration.Method).Msg("Language server registered capability")
+		h.registrations[registration.ID] = registration
+	}
+}
+
+func (h *lspHandler) unregister(unregistrations []protocol.Unregistration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, unregistration := range unregistrations {
+		delete(h.registrations, unregistration.ID)
+	}
+}
+
+// registration returns the registration options of a dynamically registered method.
+func (h *lspHandler) registration(method string) (json.RawMessage, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, registration := range h.registrations {
+		if registration.Method != method {
+			continue
+		}
+
+		options, err := json.Marshal(registration.RegisterOptions)
+		if err != nil {
+			return nil, false
+		}
+		return options, true
+	}
+
+	return nil, false
+}
+
+func unmarshalParams(req *jsonrpc2.Request, params interface{}) error {
+	if req.Params == nil {
+		return &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams, Message: "missing params"}
+	}
+
+	if err := json.Unmarshal(*req.Params, params); err != nil {
+		return &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams, Message: err.Error()}
+	}
+
+	return nil
+}
+
+func logMessage(method string, messageType protocol.MessageType, message string) {
+	level := zerolog.DebugLevel
+	switch messageType {
+	case protocol.MessageTypeError:
+		level = zerolog.ErrorLevel
+	case protocol.MessageTypeWarning:
+		level = zerolog.WarnLevel
+	case protocol.MessageTypeInfo:
+		level = zerolog.InfoLevel
+	}
+
+	// logMessage is chatty, keep it out of regular logs
+	if method == "window/logMessage" {
+		level = zerolog.DebugLevel
+	}
+
+	log.WithLevel(level).Str("method", method).Msg(message)
+}
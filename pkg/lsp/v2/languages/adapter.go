@@ -12,13 +12,13 @@ import (
 type ServerName = string
 
 type Binary struct {
-	Name string
+	Name string `json:"name"`
 	// Path to the binary
-	Path string
+	Path string `json:"path"`
 	// Command line arguments
-	Arguments []string
+	Arguments []string `json:"arguments,omitempty"`
 	// Environment variables
-	Env map[string]string
+	Env map[string]string `json:"env,omitempty"`
 }
 
 // EnvAsKeyVal returns env vars in the form "key=value".
@@ -41,7 +41,11 @@ type Adapter interface {
 	// Name returns the unique identifier for this language server
 	Name() ServerName
 
-	// FetchLatestServerVersion retrieves the latest available version info
+	// CheckIfUserInstalled returns the language server binary installed by the user, e.g. found in PATH, which is used
+	// instead of installing one.
+	CheckIfUserInstalled(ctx context.Context, delegate Delegate) (*Binary, bool)
+
+	// FetchLatestServerVersion retrieves the latest available version info, or the pinned version if there is one
 	FetchLatestServerVersion(ctx context.Context, delegate Delegate) (interface{}, error)
 
 	// FetchServerBinary downloads and prepares the language server binary
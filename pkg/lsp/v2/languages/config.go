@@ -0,0 +1,46 @@
+package lang
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// serverConfigFile declares language servers keyed by name, in the same shape as customizations.hide in devcontainer.json.
+type serverConfigFile struct {
+	LanguageServers map[ServerName]ServerConfig `json:"languageServers"`
+}
+
+// ParseServerConfigs parses language servers declared in a YAML or JSON file, e.g.
+//
+//	languageServers:
+//	  nil:
+//	    command: nil
+//	    languages: [Nix]
+//	    filePatterns: ["*.nix"]
+func ParseServerConfigs(content []byte) (map[ServerName]ServerConfig, error) {
+	// YAML is a superset of JSON, the document is converted to JSON so that options keep their json.RawMessage type
+	var document interface{}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse language server config: %w", err)
+	}
+
+	asJSON, err := json.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse language server config: %w", err)
+	}
+
+	var file serverConfigFile
+	if err := json.Unmarshal(asJSON, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse language server config: %w", err)
+	}
+
+	for name, config := range file.LanguageServers {
+		if err := config.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid language server %s: %w", name, err)
+		}
+	}
+
+	return file.LanguageServers, nil
+}
@@ -1,11 +1,22 @@
 package lang
 
 import (
+	"compress/gzip"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 
+	"github.com/hide-org/hide/pkg/util"
 	"github.com/spf13/afero"
 )
 
@@ -17,15 +28,58 @@ type Delegate interface {
 
 	MakeInstallPath(ctx context.Context, lspName string, version string) (path string, error error)
 	Exist(ctx context.Context, path string) bool
+	// Glob returns paths matching the pattern, see filepath.Match for the syntax.
+	Glob(ctx context.Context, pattern string) ([]string, error)
+
+	// InstalledVersions returns versions of the language server found in the install directory, most recently installed last.
+	InstalledVersions(ctx context.Context, lspName string) []string
+	// Which looks up an executable in PATH.
+	Which(ctx context.Context, name string) (string, bool)
+
+	// PinnedVersion returns the version the language server is pinned to, if any. Pinned versions are installed
+	// instead of the latest version and take precedence over binaries in PATH.
+	PinnedVersion(ctx context.Context, lspName string) (string, bool)
+	// InstalledBinary returns the binary and version recorded by the last successful install, if the binary still exists.
+	InstalledBinary(ctx context.Context, lspName string) (*Binary, string, bool)
+	// RecordInstall records the installed binary in the manifest of the install directory.
+	RecordInstall(ctx context.Context, lspName string, version string, bin *Binary) error
+
+	// NpmPackageLatestVersion returns the latest version of the package in the npm registry.
+	NpmPackageLatestVersion(ctx context.Context, pkg string) (string, error)
+	// NpmInstallPackages installs packages (name to version) into dir. Uses a shared npm cache, so installs work offline once seeded.
+	NpmInstallPackages(ctx context.Context, dir string, packages map[string]string) error
+
+	// DownloadFile downloads uri into the download cache and returns path to the cached file. Cached files are not downloaded again.
+	DownloadFile(ctx context.Context, uri string) (path string, err error)
+	// Extract unpacks a .gz, .tar.gz or .tgz archive into dir.
+	Extract(ctx context.Context, archive string, dir string) error
+}
+
+type DelegateOption func(d *defaultDelegate)
+
+// DelegateWithPinnedVersions pins language servers (name to version) to specific versions.
+func DelegateWithPinnedVersions(versions map[ServerName]string) DelegateOption {
+	return func(d *defaultDelegate) {
+		for name, version := range versions {
+			d.pinned[name] = version
+		}
+	}
 }
 
-func NewDefaultDelegate(fs afero.Fs, cli http.Client, rootDir string, binDir string) *defaultDelegate {
-	return &defaultDelegate{
+func NewDefaultDelegate(fs afero.Fs, cli http.Client, rootDir string, binDir string, opts ...DelegateOption) *defaultDelegate {
+	d := &defaultDelegate{
 		fs:      fs,
 		cli:     cli,
 		rootDir: rootDir,
 		binDir:  binDir,
+		pinned:  make(map[ServerName]string),
+	}
+
+	for _, opt := range opts {
+		opt(d)
 	}
+
+	return d
 }
 
 type defaultDelegate struct {
@@ -33,6 +87,10 @@ type defaultDelegate struct {
 	fs      afero.Fs
 	rootDir string
 	binDir  string
+	pinned  map[ServerName]string
+
+	// guards the manifest, servers are installed concurrently
+	manifestMu sync.Mutex
 }
 
 func (d *defaultDelegate) Get(ctx context.Context, uri string) ([]byte, error) {
@@ -41,11 +99,15 @@ func (d *defaultDelegate) Get(ctx context.Context, uri string) ([]byte, error) {
 		return nil, err
 	}
 
-	// TODO: check for status
 	resp, err := d.cli.Do(req)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s for %s", resp.Status, uri)
+	}
 
 	out, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -77,3 +139,165 @@ func (d *defaultDelegate) Exist(ctx context.Context, path string) bool {
 	}
 	return true
 }
+
+func (d *defaultDelegate) Glob(ctx context.Context, pattern string) ([]string, error) {
+	return afero.Glob(d.fs, pattern)
+}
+
+func (d *defaultDelegate) InstalledVersions(ctx context.Context, lspName string) []string {
+	entries, err := afero.ReadDir(d.fs, filepath.Join(d.binDir, lspName))
+	if err != nil {
+		return nil
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ModTime().Before(entries[j].ModTime())
+	})
+
+	out := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			out = append(out, entry.Name())
+		}
+	}
+	return out
+}
+
+func (d *defaultDelegate) Which(ctx context.Context, name string) (string, bool) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+func (d *defaultDelegate) PinnedVersion(ctx context.Context, lspName string) (string, bool) {
+	version, ok := d.pinned[lspName]
+	return version, ok && version != ""
+}
+
+func (d *defaultDelegate) NpmPackageLatestVersion(ctx context.Context, pkg string) (string, error) {
+	body, err := d.Get(ctx, fmt.Sprintf("https://registry.npmjs.org/%s/latest", pkg))
+	if err != nil {
+		return "", err
+	}
+
+	var info struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(body, &info); err != nil {
+		return "", err
+	}
+
+	if info.Version == "" {
+		return "", fmt.Errorf("no version found for npm package %s", pkg)
+	}
+
+	return info.Version, nil
+}
+
+func (d *defaultDelegate) NpmInstallPackages(ctx context.Context, dir string, packages map[string]string) error {
+	npm, ok := d.Which(ctx, "npm")
+	if !ok {
+		return errors.New("npm not found in PATH")
+	}
+
+	args := []string{"install", "--prefix", dir, "--cache", d.cachePath("npm"), "--prefer-offline", "--no-audit", "--no-fund", "--no-save"}
+
+	// sort for determinism
+	names := make([]string, 0, len(packages))
+	for name := range packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		args = append(args, fmt.Sprintf("%s@%s", name, packages[name]))
+	}
+
+	cmd := exec.CommandContext(ctx, npm, args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to install npm packages: %s: %w", string(output), err)
+	}
+
+	return nil
+}
+
+func (d *defaultDelegate) DownloadFile(ctx context.Context, uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+
+	// keep the host and path, so that different releases of the same file don't collide
+	path := filepath.Join(d.cachePath("downloads"), u.Host, filepath.FromSlash(u.Path))
+	if d.Exist(ctx, path) {
+		return path, nil
+	}
+
+	body, err := d.Get(ctx, uri)
+	if err != nil {
+		return "", err
+	}
+
+	if err := d.fs.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+
+	// write to a temporary file first, partial downloads must not end up in the cache
+	tmp := path + ".part"
+	if err := afero.WriteFile(d.fs, tmp, body, 0o644); err != nil {
+		return "", err
+	}
+
+	if err := d.fs.Rename(tmp, path); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+func (d *defaultDelegate) Extract(ctx context.Context, archive string, dir string) error {
+	f, err := d.fs.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("failed to read gzip archive %s: %w", archive, err)
+	}
+	defer gz.Close()
+
+	if !strings.HasSuffix(archive, ".tar.gz") && !strings.HasSuffix(archive, ".tgz") {
+		// plain gzip compressed binary
+		name := strings.TrimSuffix(filepath.Base(archive), ".gz")
+		return d.writeFile(filepath.Join(dir, name), gz, 0o755)
+	}
+
+	if err := util.ExtractTar(d.fs, gz, dir); err != nil {
+		return fmt.Errorf("failed to extract tar archive %s: %w", archive, err)
+	}
+
+	return nil
+}
+
+func (d *defaultDelegate) writeFile(path string, r io.Reader, perm os.FileMode) error {
+	if err := d.fs.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := d.fs.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, r)
+	return err
+}
+
+func (d *defaultDelegate) cachePath(name string) string {
+	return filepath.Join(d.binDir, ".cache", name)
+}
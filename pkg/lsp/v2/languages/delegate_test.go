@@ -0,0 +1,159 @@
+package lang
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultDelegate_DownloadFile(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path == "/missing.gz" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("content"))
+	}))
+	defer srv.Close()
+
+	fs := afero.NewMemMapFs()
+	d := NewDefaultDelegate(fs, *srv.Client(), "/workspace", "/bin")
+
+	path, err := d.DownloadFile(context.Background(), srv.URL+"/release/v1/server.gz")
+	assert.NoError(t, err)
+
+	content, err := afero.ReadFile(fs, path)
+	assert.NoError(t, err)
+	assert.Equal(t, "content", string(content))
+
+	// served from cache
+	cached, err := d.DownloadFile(context.Background(), srv.URL+"/release/v1/server.gz")
+	assert.NoError(t, err)
+	assert.Equal(t, path, cached)
+	assert.Equal(t, 1, calls)
+
+	_, err = d.DownloadFile(context.Background(), srv.URL+"/missing.gz")
+	assert.ErrorContains(t, err, "404")
+}
+
+func TestDefaultDelegate_Extract(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	d := NewDefaultDelegate(fs, http.Client{}, "/workspace", "/bin")
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	// archives created with tar -C dir . start with the root entry
+	assert.NoError(t, tw.WriteHeader(&tar.Header{Name: "./", Mode: 0o755, Typeflag: tar.TypeDir}))
+	files := map[string]string{"bin/server": "#!/bin/sh", "plugins/launcher.jar": "jar"}
+	for name, content := range files {
+		assert.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gz.Close())
+	assert.NoError(t, afero.WriteFile(fs, "/cache/server.tar.gz", buf.Bytes(), 0o644))
+
+	assert.NoError(t, d.Extract(context.Background(), "/cache/server.tar.gz", "/bin/server/1.0.0"))
+
+	for name, want := range files {
+		got, err := afero.ReadFile(fs, "/bin/server/1.0.0/"+name)
+		assert.NoError(t, err)
+		assert.Equal(t, want, string(got))
+	}
+
+	buf.Reset()
+	gz = gzip.NewWriter(&buf)
+	gz.Write([]byte("binary"))
+	assert.NoError(t, gz.Close())
+	assert.NoError(t, afero.WriteFile(fs, "/cache/rust-analyzer-x86_64-unknown-linux-gnu.gz", buf.Bytes(), 0o644))
+
+	assert.NoError(t, d.Extract(context.Background(), "/cache/rust-analyzer-x86_64-unknown-linux-gnu.gz", "/bin/rust-analyzer/1"))
+
+	got, err := afero.ReadFile(fs, "/bin/rust-analyzer/1/rust-analyzer-x86_64-unknown-linux-gnu")
+	assert.NoError(t, err)
+	assert.Equal(t, "binary", string(got))
+}
+
+func TestJdtlsLauncher(t *testing.T) {
+	ctx := context.Background()
+	fs := afero.NewMemMapFs()
+	d := NewDefaultDelegate(fs, http.Client{}, "/workspace", "/bin")
+
+	_, err := jdtlsLauncher(ctx, d, "/bin/jdtls/1.40.0")
+	assert.ErrorContains(t, err, "launcher not found")
+
+	assert.NoError(t, afero.WriteFile(fs, "/bin/jdtls/1.40.0/plugins/org.eclipse.equinox.launcher_1.6.900.jar", []byte("jar"), 0o644))
+
+	launcher, err := jdtlsLauncher(ctx, d, "/bin/jdtls/1.40.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "/bin/jdtls/1.40.0/plugins/org.eclipse.equinox.launcher_1.6.900.jar", launcher)
+}
+
+func TestDefaultDelegate_Manifest(t *testing.T) {
+	ctx := context.Background()
+	fs := afero.NewMemMapFs()
+	d := NewDefaultDelegate(fs, http.Client{}, "/workspace", "/bin")
+
+	_, _, ok := d.InstalledBinary(ctx, "gopls")
+	assert.False(t, ok)
+
+	bin := &Binary{Name: "gopls", Path: "/bin/gopls/v0.16.2/gopls", Arguments: []string{"serve"}}
+	assert.NoError(t, d.RecordInstall(ctx, "gopls", "v0.16.2", bin))
+
+	// the binary was removed since
+	_, _, ok = d.InstalledBinary(ctx, "gopls")
+	assert.False(t, ok)
+
+	assert.NoError(t, afero.WriteFile(fs, bin.Path, []byte("binary"), 0o755))
+	got, version, ok := d.InstalledBinary(ctx, "gopls")
+	assert.True(t, ok)
+	assert.Equal(t, "v0.16.2", version)
+	assert.Equal(t, bin, got)
+
+	// the manifest is read from disk, e.g. by the next run
+	got, version, ok = NewDefaultDelegate(fs, http.Client{}, "/workspace", "/bin").InstalledBinary(ctx, "gopls")
+	assert.True(t, ok)
+	assert.Equal(t, "v0.16.2", version)
+	assert.Equal(t, bin, got)
+
+	// a corrupt manifest is rewritten
+	assert.NoError(t, afero.WriteFile(fs, "/bin/manifest.json", []byte("{"), 0o644))
+	_, _, ok = d.InstalledBinary(ctx, "gopls")
+	assert.False(t, ok)
+	assert.NoError(t, d.RecordInstall(ctx, "gopls", "v0.16.2", bin))
+	_, _, ok = d.InstalledBinary(ctx, "gopls")
+	assert.True(t, ok)
+}
+
+func TestDefaultDelegate_PinnedVersion(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultDelegate(afero.NewMemMapFs(), http.Client{}, "/workspace", "/bin", DelegateWithPinnedVersions(map[ServerName]string{"gopls": "v0.16.2", "pyright": ""}))
+
+	version, ok := d.PinnedVersion(ctx, "gopls")
+	assert.True(t, ok)
+	assert.Equal(t, "v0.16.2", version)
+
+	_, ok = d.PinnedVersion(ctx, "pyright")
+	assert.False(t, ok)
+
+	// pins are used without asking the registry
+	got, err := (&gopls{}).FetchLatestServerVersion(ctx, d)
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.16.2", VersionString(got))
+
+	d = NewDefaultDelegate(afero.NewMemMapFs(), http.Client{}, "/workspace", "/bin", DelegateWithPinnedVersions(map[ServerName]string{"typescript-language-server": "4.3.3"}))
+	got, err = (&typescriptLanguageServer{}).FetchLatestServerVersion(ctx, d)
+	assert.NoError(t, err)
+	assert.Equal(t, typescriptVersion{Server: "4.3.3", TypeScript: "latest"}, got)
+}
@@ -34,11 +34,31 @@ func (a *gopls) Name() ServerName {
 	return "gopls"
 }
 
+func (a *gopls) CheckIfUserInstalled(ctx context.Context, delegate Delegate) (*Binary, bool) {
+	bin, ok := userBinary(ctx, delegate, a.Name(), "gopls", "serve")
+	if !ok {
+		return nil, false
+	}
+
+	bin.Env = map[string]string{
+		"GOPATH": os.Getenv("GOPATH"),
+		"GOROOT": os.Getenv("GOROOT"),
+	}
+	return bin, true
+}
+
 func (a *gopls) FetchLatestServerVersion(ctx context.Context, delegate Delegate) (interface{}, error) {
+	if pinned, ok := pinnedVersion(ctx, delegate, a.Name()); ok {
+		return goplsVersion{Version: pinned}, nil
+	}
+
 	body, err := delegate.Get(ctx, "https://proxy.golang.org/golang.org/x/tools/gopls/@latest")
 	if err != nil {
-		log.Error().Err(err).Msg("gopls: failed to get version")
-		return nil, err
+		installed, err := installedVersion(ctx, delegate, a.Name(), err)
+		if err != nil {
+			return nil, err
+		}
+		return goplsVersion{Version: installed}, nil
 	}
 
 	var version goplsVersion
@@ -55,7 +75,7 @@ func (a *gopls) FetchLatestServerVersion(ctx context.Context, delegate Delegate)
 func (a *gopls) FetchServerBinary(ctx context.Context, version interface{}, delegate Delegate) (*Binary, error) {
 	var ver string
 
-	if v, ok := version.(goplsVersion); ok {
+	if v, ok := version.(goplsVersion); ok && v.Version != "" {
 		ver = v.Version
 	} else {
 		ver = "latest"
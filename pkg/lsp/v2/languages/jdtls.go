@@ -0,0 +1,192 @@
+package lang
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+var _ Adapter = (*jdtls)(nil)
+
+type jdtls struct{}
+
+const jdtlsSnapshots = "https://download.eclipse.org/jdtls/snapshots"
+
+func (a *jdtls) Name() ServerName {
+	return "jdtls"
+}
+
+func (a *jdtls) CheckIfUserInstalled(ctx context.Context, delegate Delegate) (*Binary, bool) {
+	bin, ok := userBinary(ctx, delegate, a.Name(), "jdtls")
+	if !ok {
+		return nil, false
+	}
+
+	// the jdtls launch script keeps its data in the workspace unless told otherwise
+	dataDir, err := a.dataDir(ctx, delegate)
+	if err != nil {
+		return nil, false
+	}
+
+	bin.Arguments = []string{"-data", dataDir}
+	return bin, true
+}
+
+func (a *jdtls) FetchLatestServerVersion(ctx context.Context, delegate Delegate) (interface{}, error) {
+	if pinned, ok := pinnedVersion(ctx, delegate, a.Name()); ok {
+		return pinned, nil
+	}
+
+	// latest.txt contains the archive name, e.g. jdt-language-server-1.40.0-202409261450.tar.gz
+	body, err := delegate.Get(ctx, jdtlsSnapshots+"/latest.txt")
+	if err != nil {
+		return installedVersion(ctx, delegate, a.Name(), err)
+	}
+
+	archive := strings.TrimSpace(string(body))
+	version := strings.TrimSuffix(strings.TrimPrefix(archive, "jdt-language-server-"), ".tar.gz")
+	if version == archive {
+		return nil, fmt.Errorf("jdtls: unexpected latest archive %s", archive)
+	}
+
+	log.Debug().Msgf("jdtls: got version %s", version)
+	return version, nil
+}
+
+func (a *jdtls) FetchServerBinary(ctx context.Context, version interface{}, delegate Delegate) (*Binary, error) {
+	java, ok := delegate.Which(ctx, "java")
+	if !ok {
+		return nil, errors.New("jdtls: java not found in PATH")
+	}
+
+	ver, ok := version.(string)
+	if !ok || ver == "" {
+		return nil, errors.New("jdtls: version is required")
+	}
+
+	installDir, err := delegate.MakeInstallPath(ctx, a.Name(), ver)
+	if err != nil {
+		log.Error().Err(err).Msgf("jdtls: failed to make install path %s", ver)
+		return nil, err
+	}
+
+	if _, err := jdtlsLauncher(ctx, delegate, installDir); err != nil {
+		uri := fmt.Sprintf("%s/jdt-language-server-%s.tar.gz", jdtlsSnapshots, ver)
+		archive, err := delegate.DownloadFile(ctx, uri)
+		if err != nil {
+			return nil, fmt.Errorf("jdtls: failed to download %s: %w", uri, err)
+		}
+
+		if err := delegate.Extract(ctx, archive, installDir); err != nil {
+			return nil, fmt.Errorf("jdtls: failed to extract %s: %w", archive, err)
+		}
+	} else {
+		log.Debug().Msgf("jdtls: version already exists")
+	}
+
+	launcher, err := jdtlsLauncher(ctx, delegate, installDir)
+	if err != nil {
+		return nil, err
+	}
+
+	dataDir, err := a.dataDir(ctx, delegate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Binary{
+		Name: a.Name(),
+		Path: java,
+		Arguments: []string{
+			"-Declipse.application=org.eclipse.jdt.ls.core.id1",
+			"-Dosgi.bundles.defaultStartLevel=4",
+			"-Declipse.product=org.eclipse.jdt.ls.core.product",
+			"-Xmx1G",
+			"--add-modules=ALL-SYSTEM",
+			"--add-opens", "java.base/java.util=ALL-UNNAMED",
+			"--add-opens", "java.base/java.lang=ALL-UNNAMED",
+			"-jar", launcher,
+			"-configuration", filepath.Join(installDir, jdtlsConfigDir(ctx, delegate, installDir)),
+			"-data", dataDir,
+		},
+	}, nil
+}
+
+// dataDir keeps the jdtls index outside of the project, one data directory per project.
+func (a *jdtls) dataDir(ctx context.Context, delegate Delegate) (string, error) {
+	sum := sha256.Sum256([]byte(delegate.ProjectRootPath()))
+	return delegate.MakeInstallPath(ctx, a.Name()+"-data", hex.EncodeToString(sum[:8]))
+}
+
+func jdtlsLauncher(ctx context.Context, delegate Delegate, installDir string) (string, error) {
+	matches, err := delegate.Glob(ctx, filepath.Join(installDir, "plugins", "org.eclipse.equinox.launcher_*.jar"))
+	if err != nil {
+		return "", err
+	}
+	if len(matches) == 0 {
+		return "", errors.New("jdtls: launcher not found")
+	}
+	return matches[0], nil
+}
+
+func jdtlsConfigDir(ctx context.Context, delegate Delegate, installDir string) string {
+	switch runtime.GOOS {
+	case "darwin":
+		if runtime.GOARCH == "arm64" {
+			// only newer releases ship a separate configuration for arm
+			if delegate.Exist(ctx, filepath.Join(installDir, "config_mac_arm")) {
+				return "config_mac_arm"
+			}
+		}
+		return "config_mac"
+	case "windows":
+		return "config_win"
+	default:
+		return "config_linux"
+	}
+}
+
+func (a *jdtls) InitializationOptions(ctx context.Context, delegate Delegate) json.RawMessage {
+	options := map[string]interface{}{
+		"settings": a.settings(),
+		"extendedClientCapabilities": map[string]bool{
+			"classFileContentsSupport": true, // Allows navigating into library classes
+		},
+	}
+
+	// should always marshal
+	out, _ := json.Marshal(options)
+	return out
+}
+
+func (a *jdtls) WorkspaceConfiguration(ctx context.Context, delegate Delegate) (json.RawMessage, error) {
+	return json.Marshal(a.settings())
+}
+
+func (a *jdtls) settings() map[string]interface{} {
+	return map[string]interface{}{
+		"java": map[string]interface{}{
+			"autobuild":          map[string]bool{"enabled": true},
+			"maven":              map[string]bool{"downloadSources": false},
+			"import":             map[string]interface{}{"gradle": map[string]bool{"enabled": true}, "maven": map[string]bool{"enabled": true}},
+			"referencesCodeLens": map[string]bool{"enabled": false},
+		},
+	}
+}
+
+func (a *jdtls) CodeActions() ([]protocol.CodeActionKind, error) {
+	return nil, nil
+}
+
+func (a *jdtls) Languages() []LanguageID {
+	return []LanguageID{Java}
+}
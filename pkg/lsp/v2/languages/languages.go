@@ -6,11 +6,46 @@ type LanguageID = string
 // For reference see https://github.com/go-enry/go-enry/blob/master/data/languageInfo.go
 const (
 	Go         LanguageID = "Go"
+	Java       LanguageID = "Java"
 	JavaScript LanguageID = "JavaScript"
 	Python     LanguageID = "Python"
+	Rust       LanguageID = "Rust"
+	TSX        LanguageID = "TSX"
 	TypeScript LanguageID = "TypeScript"
 )
 
 var Adapters = []Adapter{
 	new(gopls),
+	new(pyright),
+	new(typescriptLanguageServer),
+	new(rustAnalyzer),
+	new(jdtls),
+}
+
+// RegisterAdapter adds an adapter, e.g. one configured by the user, to Adapters. It replaces built-in adapters with the
+// same name or serving any of its languages, as a language is served by one server only. Not safe for concurrent use,
+// adapters are registered on startup.
+func RegisterAdapter(adapter Adapter) (replaced []ServerName) {
+	out := make([]Adapter, 0, len(Adapters)+1)
+	for _, existing := range Adapters {
+		if existing.Name() == adapter.Name() || overlaps(existing.Languages(), adapter.Languages()) {
+			replaced = append(replaced, existing.Name())
+			continue
+		}
+		out = append(out, existing)
+	}
+
+	Adapters = append(out, adapter)
+	return replaced
+}
+
+func overlaps(a, b []LanguageID) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
 }
@@ -0,0 +1,98 @@
+package lang
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+const manifestFile = "manifest.json"
+
+// manifest records the installed language servers in the install directory, so that they can be reused offline.
+type manifest struct {
+	Servers map[ServerName]manifestEntry `json:"servers"`
+}
+
+type manifestEntry struct {
+	Version     string    `json:"version"`
+	Binary      Binary    `json:"binary"`
+	InstalledAt time.Time `json:"installedAt"`
+}
+
+func (d *defaultDelegate) InstalledBinary(ctx context.Context, lspName string) (*Binary, string, bool) {
+	d.manifestMu.Lock()
+	defer d.manifestMu.Unlock()
+
+	m, err := d.readManifest()
+	if err != nil {
+		return nil, "", false
+	}
+
+	entry, ok := m.Servers[lspName]
+	if !ok || !d.Exist(ctx, entry.Binary.Path) {
+		return nil, "", false
+	}
+
+	bin := entry.Binary
+	return &bin, entry.Version, true
+}
+
+func (d *defaultDelegate) RecordInstall(ctx context.Context, lspName string, version string, bin *Binary) error {
+	d.manifestMu.Lock()
+	defer d.manifestMu.Unlock()
+
+	m, err := d.readManifest()
+	if err != nil {
+		// a corrupt manifest is rewritten, it only caches installs
+		m = manifest{}
+	}
+	if m.Servers == nil {
+		m.Servers = make(map[ServerName]manifestEntry)
+	}
+
+	m.Servers[lspName] = manifestEntry{Version: version, Binary: *bin, InstalledAt: time.Now().UTC()}
+
+	out, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := d.fs.MkdirAll(d.binDir, 0o755); err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so that a crash doesn't leave a partial manifest
+	path := d.manifestPath()
+	if err := afero.WriteFile(d.fs, path+".tmp", out, 0o644); err != nil {
+		return err
+	}
+
+	return d.fs.Rename(path+".tmp", path)
+}
+
+func (d *defaultDelegate) readManifest() (manifest, error) {
+	var m manifest
+
+	content, err := afero.ReadFile(d.fs, d.manifestPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return m, err
+	}
+
+	if err := json.Unmarshal(content, &m); err != nil {
+		return m, err
+	}
+
+	return m, nil
+}
+
+func (d *defaultDelegate) manifestPath() string {
+	return filepath.Join(d.binDir, manifestFile)
+}
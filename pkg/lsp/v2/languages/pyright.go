@@ -0,0 +1,85 @@
+package lang
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+var _ Adapter = (*pyright)(nil)
+
+type pyright struct{}
+
+func (a *pyright) Name() ServerName {
+	return "pyright"
+}
+
+func (a *pyright) CheckIfUserInstalled(ctx context.Context, delegate Delegate) (*Binary, bool) {
+	return userBinary(ctx, delegate, a.Name(), "pyright-langserver", "--stdio")
+}
+
+func (a *pyright) FetchLatestServerVersion(ctx context.Context, delegate Delegate) (interface{}, error) {
+	return npmPackageVersion(ctx, delegate, a.Name(), "pyright")
+}
+
+func (a *pyright) FetchServerBinary(ctx context.Context, version interface{}, delegate Delegate) (*Binary, error) {
+	if _, ok := delegate.Which(ctx, "node"); !ok {
+		return nil, errors.New("pyright: node not found in PATH")
+	}
+
+	ver := versionString(version)
+
+	installDir, err := delegate.MakeInstallPath(ctx, a.Name(), ver)
+	if err != nil {
+		log.Error().Err(err).Msgf("pyright: failed to make install path %s", ver)
+		return nil, err
+	}
+
+	bin := &Binary{
+		Name:      a.Name(),
+		Path:      filepath.Join(installDir, "node_modules", ".bin", "pyright-langserver"),
+		Arguments: []string{"--stdio"},
+	}
+
+	if delegate.Exist(ctx, bin.Path) {
+		log.Debug().Msgf("pyright: version already exists")
+		return bin, nil
+	}
+
+	log.Debug().Msgf("pyright: will install to path: %s", installDir)
+	if err := delegate.NpmInstallPackages(ctx, installDir, map[string]string{"pyright": ver}); err != nil {
+		return nil, err
+	}
+
+	return bin, nil
+}
+
+func (a *pyright) InitializationOptions(ctx context.Context, delegate Delegate) json.RawMessage {
+	return nil
+}
+
+func (a *pyright) WorkspaceConfiguration(ctx context.Context, delegate Delegate) (json.RawMessage, error) {
+	config := map[string]interface{}{
+		"python": map[string]interface{}{
+			"analysis": map[string]interface{}{
+				"autoSearchPaths":        true,
+				"useLibraryCodeForTypes": true,            // Infer types from library sources when stubs are missing
+				"diagnosticMode":         "openFilesOnly", // Analysing the whole workspace is slow on large projects
+			},
+		},
+	}
+
+	return json.Marshal(config)
+}
+
+func (a *pyright) CodeActions() ([]protocol.CodeActionKind, error) {
+	return nil, nil
+}
+
+func (a *pyright) Languages() []LanguageID {
+	return []LanguageID{Python}
+}
@@ -0,0 +1,134 @@
+package lang
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"runtime"
+
+	"github.com/rs/zerolog/log"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+var _ Adapter = (*rustAnalyzer)(nil)
+
+type rustAnalyzer struct{}
+
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+}
+
+func (a *rustAnalyzer) Name() ServerName {
+	return "rust-analyzer"
+}
+
+func (a *rustAnalyzer) CheckIfUserInstalled(ctx context.Context, delegate Delegate) (*Binary, bool) {
+	return userBinary(ctx, delegate, a.Name(), "rust-analyzer")
+}
+
+func (a *rustAnalyzer) FetchLatestServerVersion(ctx context.Context, delegate Delegate) (interface{}, error) {
+	if pinned, ok := pinnedVersion(ctx, delegate, a.Name()); ok {
+		return pinned, nil
+	}
+
+	body, err := delegate.Get(ctx, "https://api.github.com/repos/rust-lang/rust-analyzer/releases/latest")
+	if err != nil {
+		return installedVersion(ctx, delegate, a.Name(), err)
+	}
+
+	var release githubRelease
+	if err := json.Unmarshal(body, &release); err != nil {
+		log.Error().Err(err).Msg("rust-analyzer: failed to unmarshal release")
+		return nil, err
+	}
+
+	log.Debug().Msgf("rust-analyzer: got version %s", release.TagName)
+	return release.TagName, nil
+}
+
+func (a *rustAnalyzer) FetchServerBinary(ctx context.Context, version interface{}, delegate Delegate) (*Binary, error) {
+	ver := versionString(version)
+
+	target, err := rustAnalyzerTarget()
+	if err != nil {
+		return nil, err
+	}
+
+	installDir, err := delegate.MakeInstallPath(ctx, a.Name(), ver)
+	if err != nil {
+		log.Error().Err(err).Msgf("rust-analyzer: failed to make install path %s", ver)
+		return nil, err
+	}
+
+	asset := fmt.Sprintf("rust-analyzer-%s.gz", target)
+	bin := &Binary{
+		Name: a.Name(),
+		Path: filepath.Join(installDir, fmt.Sprintf("rust-analyzer-%s", target)),
+	}
+
+	if delegate.Exist(ctx, bin.Path) {
+		log.Debug().Msgf("rust-analyzer: version already exists")
+		return bin, nil
+	}
+
+	uri := fmt.Sprintf("https://github.com/rust-lang/rust-analyzer/releases/latest/download/%s", asset)
+	if ver != "latest" {
+		uri = fmt.Sprintf("https://github.com/rust-lang/rust-analyzer/releases/download/%s/%s", ver, asset)
+	}
+
+	archive, err := delegate.DownloadFile(ctx, uri)
+	if err != nil {
+		return nil, fmt.Errorf("rust-analyzer: failed to download %s: %w", uri, err)
+	}
+
+	if err := delegate.Extract(ctx, archive, installDir); err != nil {
+		return nil, fmt.Errorf("rust-analyzer: failed to extract %s: %w", archive, err)
+	}
+
+	return bin, nil
+}
+
+func rustAnalyzerTarget() (string, error) {
+	switch runtime.GOOS + "/" + runtime.GOARCH {
+	case "linux/amd64":
+		return "x86_64-unknown-linux-gnu", nil
+	case "linux/arm64":
+		return "aarch64-unknown-linux-gnu", nil
+	case "darwin/amd64":
+		return "x86_64-apple-darwin", nil
+	case "darwin/arm64":
+		return "aarch64-apple-darwin", nil
+	default:
+		return "", fmt.Errorf("rust-analyzer: unsupported platform %s/%s", runtime.GOOS, runtime.GOARCH)
+	}
+}
+
+func (a *rustAnalyzer) InitializationOptions(ctx context.Context, delegate Delegate) json.RawMessage {
+	options := map[string]interface{}{
+		"cargo": map[string]interface{}{
+			"buildScripts": map[string]bool{"enable": true}, // Needed for code generated by build.rs
+		},
+		"procMacro":   map[string]bool{"enable": true},
+		"checkOnSave": true,
+		"check": map[string]string{
+			"command": "check", // clippy is not always installed
+		},
+	}
+
+	// should always marshal
+	out, _ := json.Marshal(options)
+	return out
+}
+
+func (a *rustAnalyzer) WorkspaceConfiguration(ctx context.Context, delegate Delegate) (json.RawMessage, error) {
+	return nil, nil
+}
+
+func (a *rustAnalyzer) CodeActions() ([]protocol.CodeActionKind, error) {
+	return nil, nil
+}
+
+func (a *rustAnalyzer) Languages() []LanguageID {
+	return []LanguageID{Rust}
+}
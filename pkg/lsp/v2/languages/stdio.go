@@ -0,0 +1,134 @@
+package lang
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// ServerConfig declares a language server that talks LSP over stdio and is installed by the user, e.g. an in-house
+// server. Such servers are not installed by hide.
+type ServerConfig struct {
+	// Command is an executable in PATH, an absolute path, or a path relative to the project root
+	Command string `json:"command"`
+	// Args are the command line arguments, e.g. ["--stdio"]
+	Args []string `json:"args,omitempty"`
+	// Env are additional environment variables of the server process
+	Env map[string]string `json:"env,omitempty"`
+	// Languages served by the server, e.g. ["Nix"]. See LanguageID
+	Languages []LanguageID `json:"languages"`
+	// FilePatterns assign files to the languages of the server, e.g. ["*.nix"]. Needed for languages not known to the
+	// language detector
+	FilePatterns []string `json:"filePatterns,omitempty"`
+	// InitializationOptions are sent with initialize
+	InitializationOptions json.RawMessage `json:"initializationOptions,omitempty"`
+	// Settings are returned for workspace/configuration
+	Settings json.RawMessage `json:"settings,omitempty"`
+}
+
+func (c ServerConfig) Validate() error {
+	if c.Command == "" {
+		return errors.New("command is required")
+	}
+
+	if len(c.Languages) == 0 {
+		return errors.New("at least one language is required")
+	}
+
+	if len(c.FilePatterns) > 0 && len(c.Languages) > 1 {
+		return errors.New("file patterns are ambiguous with more than one language")
+	}
+
+	return nil
+}
+
+var _ Adapter = (*stdioAdapter)(nil)
+
+// stdioAdapter runs a language server declared by ServerConfig.
+type stdioAdapter struct {
+	name   ServerName
+	config ServerConfig
+}
+
+// NewStdioAdapter returns an adapter for the language server declared by config.
+func NewStdioAdapter(name ServerName, config ServerConfig) (Adapter, error) {
+	if name == "" {
+		return nil, errors.New("language server name is required")
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid language server %s: %w", name, err)
+	}
+
+	return &stdioAdapter{name: name, config: config}, nil
+}
+
+func (a *stdioAdapter) Name() ServerName {
+	return a.name
+}
+
+func (a *stdioAdapter) CheckIfUserInstalled(ctx context.Context, delegate Delegate) (*Binary, bool) {
+	path, ok := a.command(ctx, delegate)
+	if !ok {
+		return nil, false
+	}
+
+	return &Binary{
+		Name:      a.name,
+		Path:      path,
+		Arguments: a.config.Args,
+		Env:       a.config.Env,
+	}, true
+}
+
+// command resolves the command like a shell would, paths with a separator are relative to the project root.
+func (a *stdioAdapter) command(ctx context.Context, delegate Delegate) (string, bool) {
+	command := a.config.Command
+	if !strings.ContainsRune(command, filepath.Separator) && !strings.ContainsRune(command, '/') {
+		return delegate.Which(ctx, command)
+	}
+
+	if !filepath.IsAbs(command) {
+		command = filepath.Join(delegate.ProjectRootPath(), command)
+	}
+
+	return command, delegate.Exist(ctx, command)
+}
+
+func (a *stdioAdapter) FetchLatestServerVersion(ctx context.Context, delegate Delegate) (interface{}, error) {
+	return nil, fmt.Errorf("%s: command %s not found, configured language servers are not installed by hide", a.name, a.config.Command)
+}
+
+func (a *stdioAdapter) FetchServerBinary(ctx context.Context, version interface{}, delegate Delegate) (*Binary, error) {
+	bin, ok := a.CheckIfUserInstalled(ctx, delegate)
+	if !ok {
+		return nil, fmt.Errorf("%s: command %s not found", a.name, a.config.Command)
+	}
+	return bin, nil
+}
+
+func (a *stdioAdapter) InitializationOptions(ctx context.Context, delegate Delegate) json.RawMessage {
+	return a.config.InitializationOptions
+}
+
+func (a *stdioAdapter) WorkspaceConfiguration(ctx context.Context, delegate Delegate) (json.RawMessage, error) {
+	return a.config.Settings, nil
+}
+
+func (a *stdioAdapter) CodeActions() ([]protocol.CodeActionKind, error) {
+	return nil, nil
+}
+
+func (a *stdioAdapter) Languages() []LanguageID {
+	return a.config.Languages
+}
+
+// FilePatterns returns the file patterns of the language served by the adapter.
+func (a *stdioAdapter) FilePatterns() []string {
+	return a.config.FilePatterns
+}
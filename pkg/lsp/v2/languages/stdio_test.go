@@ -0,0 +1,110 @@
+package lang
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseServerConfigs(t *testing.T) {
+	want := map[ServerName]ServerConfig{
+		"nil": {
+			Command:               "nil",
+			Args:                  []string{"--stdio"},
+			Languages:             []LanguageID{"Nix"},
+			FilePatterns:          []string{"*.nix"},
+			InitializationOptions: json.RawMessage(`{"formatting":{"command":["nixfmt"]}}`),
+		},
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    map[ServerName]ServerConfig
+		wantErr string
+	}{
+		{
+			name: "yaml",
+			content: `
+languageServers:
+  nil:
+    command: nil
+    args: [--stdio]
+    languages: [Nix]
+    filePatterns: ["*.nix"]
+    initializationOptions:
+      formatting:
+        command: [nixfmt]
+`,
+			want: want,
+		},
+		{
+			name:    "json",
+			content: `{"languageServers": {"nil": {"command": "nil", "args": ["--stdio"], "languages": ["Nix"], "filePatterns": ["*.nix"], "initializationOptions": {"formatting": {"command": ["nixfmt"]}}}}}`,
+			want:    want,
+		},
+		{
+			name:    "missing command",
+			content: `{"languageServers": {"nil": {"languages": ["Nix"]}}}`,
+			wantErr: "invalid language server nil: command is required",
+		},
+		{
+			name:    "ambiguous file patterns",
+			content: `{"languageServers": {"web": {"command": "web", "languages": ["HTML", "CSS"], "filePatterns": ["*.tpl"]}}}`,
+			wantErr: "file patterns are ambiguous",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseServerConfigs([]byte(tt.content))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRegisterAdapter(t *testing.T) {
+	builtIn := Adapters
+	defer func() { Adapters = builtIn }()
+
+	adapter, err := NewStdioAdapter("pylsp", ServerConfig{Command: "pylsp", Languages: []LanguageID{Python}})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []ServerName{"pyright"}, RegisterAdapter(adapter))
+	assert.Len(t, Adapters, len(builtIn))
+	assert.Equal(t, adapter, Adapters[len(Adapters)-1])
+
+	nix, err := NewStdioAdapter("nil", ServerConfig{Command: "nil", Languages: []LanguageID{"Nix"}})
+	assert.NoError(t, err)
+	assert.Empty(t, RegisterAdapter(nix))
+	assert.Len(t, Adapters, len(builtIn)+1)
+}
+
+func TestStdioAdapter_CheckIfUserInstalled(t *testing.T) {
+	ctx := context.Background()
+	fs := afero.NewMemMapFs()
+	d := NewDefaultDelegate(fs, http.Client{}, "/workspace", "/bin")
+
+	adapter, err := NewStdioAdapter("custom", ServerConfig{Command: "tools/lsp", Args: []string{"--stdio"}, Env: map[string]string{"LOG": "debug"}, Languages: []LanguageID{"Custom"}})
+	assert.NoError(t, err)
+
+	_, ok := adapter.CheckIfUserInstalled(ctx, d)
+	assert.False(t, ok)
+	_, err = adapter.FetchLatestServerVersion(ctx, d)
+	assert.ErrorContains(t, err, "not installed by hide")
+
+	assert.NoError(t, afero.WriteFile(fs, "/workspace/tools/lsp", []byte("#!/bin/sh"), 0o755))
+	bin, ok := adapter.CheckIfUserInstalled(ctx, d)
+	assert.True(t, ok)
+	assert.Equal(t, &Binary{Name: "custom", Path: "/workspace/tools/lsp", Arguments: []string{"--stdio"}, Env: map[string]string{"LOG": "debug"}}, bin)
+}
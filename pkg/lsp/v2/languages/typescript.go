@@ -0,0 +1,168 @@
+package lang
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+var _ Adapter = (*typescriptLanguageServer)(nil)
+
+type typescriptLanguageServer struct{}
+
+type typescriptVersion struct {
+	Server     string
+	TypeScript string
+}
+
+// String returns the version used for the install path, e.g. "4.3.3-ts5.6.3".
+func (v typescriptVersion) String() string {
+	return v.Server + "-ts" + v.TypeScript
+}
+
+func (a *typescriptLanguageServer) installedVersion(ctx context.Context, delegate Delegate, fetchErr error) (interface{}, error) {
+	installed, err := installedVersion(ctx, delegate, a.Name(), fetchErr)
+	if err != nil {
+		return nil, err
+	}
+
+	server, typescript, ok := strings.Cut(installed, "-ts")
+	if !ok {
+		return nil, fmt.Errorf("typescript-language-server: invalid installed version %s", installed)
+	}
+
+	return typescriptVersion{Server: server, TypeScript: typescript}, nil
+}
+
+// pinnedVersion parses a pinned version, either of the server alone, e.g. "4.3.3", or with typescript, e.g. "4.3.3-ts5.6.3".
+func (a *typescriptLanguageServer) pinnedVersion(ctx context.Context, delegate Delegate) (typescriptVersion, bool) {
+	pinned, ok := pinnedVersion(ctx, delegate, a.Name())
+	if !ok {
+		return typescriptVersion{}, false
+	}
+
+	server, typescript, ok := strings.Cut(pinned, "-ts")
+	if !ok || typescript == "" {
+		typescript = "latest"
+	}
+
+	return typescriptVersion{Server: server, TypeScript: typescript}, true
+}
+
+func (a *typescriptLanguageServer) Name() ServerName {
+	return "typescript-language-server"
+}
+
+func (a *typescriptLanguageServer) CheckIfUserInstalled(ctx context.Context, delegate Delegate) (*Binary, bool) {
+	return userBinary(ctx, delegate, a.Name(), "typescript-language-server", "--stdio")
+}
+
+func (a *typescriptLanguageServer) FetchLatestServerVersion(ctx context.Context, delegate Delegate) (interface{}, error) {
+	if pinned, ok := a.pinnedVersion(ctx, delegate); ok {
+		return pinned, nil
+	}
+
+	server, err := delegate.NpmPackageLatestVersion(ctx, "typescript-language-server")
+	if err != nil {
+		return a.installedVersion(ctx, delegate, err)
+	}
+
+	typescript, err := delegate.NpmPackageLatestVersion(ctx, "typescript")
+	if err != nil {
+		return a.installedVersion(ctx, delegate, err)
+	}
+
+	log.Debug().Msgf("typescript-language-server: got version %s, typescript %s", server, typescript)
+	return typescriptVersion{Server: server, TypeScript: typescript}, nil
+}
+
+func (a *typescriptLanguageServer) FetchServerBinary(ctx context.Context, version interface{}, delegate Delegate) (*Binary, error) {
+	if _, ok := delegate.Which(ctx, "node"); !ok {
+		return nil, errors.New("typescript-language-server: node not found in PATH")
+	}
+
+	v, ok := version.(typescriptVersion)
+	if !ok {
+		v = typescriptVersion{Server: "latest", TypeScript: "latest"}
+	}
+
+	// typescript is installed alongside, the server falls back to it when the project has none
+	ver := v.String()
+	packages := map[string]string{"typescript-language-server": v.Server, "typescript": v.TypeScript}
+
+	installDir, err := delegate.MakeInstallPath(ctx, a.Name(), ver)
+	if err != nil {
+		log.Error().Err(err).Msgf("typescript-language-server: failed to make install path %s", ver)
+		return nil, err
+	}
+
+	bin := &Binary{
+		Name:      a.Name(),
+		Path:      filepath.Join(installDir, "node_modules", ".bin", "typescript-language-server"),
+		Arguments: []string{"--stdio"},
+	}
+
+	if delegate.Exist(ctx, bin.Path) {
+		log.Debug().Msgf("typescript-language-server: version already exists")
+		return bin, nil
+	}
+
+	log.Debug().Msgf("typescript-language-server: will install to path: %s", installDir)
+	if err := delegate.NpmInstallPackages(ctx, installDir, packages); err != nil {
+		return nil, err
+	}
+
+	return bin, nil
+}
+
+func (a *typescriptLanguageServer) InitializationOptions(ctx context.Context, delegate Delegate) json.RawMessage {
+	options := map[string]interface{}{
+		"hostInfo": "hide",
+		"preferences": map[string]interface{}{
+			"includeCompletionsForModuleExports": true,
+			"includeCompletionsWithInsertText":   true,
+		},
+	}
+
+	// prefer the project's typescript so that diagnostics match the project's compiler
+	tsserver := filepath.Join(delegate.ProjectRootPath(), "node_modules", "typescript", "lib")
+	if delegate.Exist(ctx, tsserver) {
+		options["tsserver"] = map[string]string{"path": tsserver}
+	}
+
+	// should always marshal
+	out, _ := json.Marshal(options)
+	return out
+}
+
+func (a *typescriptLanguageServer) WorkspaceConfiguration(ctx context.Context, delegate Delegate) (json.RawMessage, error) {
+	inlayHints := map[string]interface{}{
+		"includeInlayParameterNameHints":         "none",
+		"includeInlayFunctionParameterTypeHints": false,
+	}
+
+	config := map[string]interface{}{
+		"typescript": map[string]interface{}{"inlayHints": inlayHints},
+		"javascript": map[string]interface{}{"inlayHints": inlayHints},
+	}
+
+	return json.Marshal(config)
+}
+
+func (a *typescriptLanguageServer) CodeActions() ([]protocol.CodeActionKind, error) {
+	return []protocol.CodeActionKind{
+		protocol.CodeActionKindQuickFix,
+		protocol.CodeActionKindRefactor,
+		protocol.CodeActionKindSourceOrganizeImports,
+	}, nil
+}
+
+func (a *typescriptLanguageServer) Languages() []LanguageID {
+	return []LanguageID{JavaScript, TypeScript, TSX}
+}
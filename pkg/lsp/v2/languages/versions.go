@@ -0,0 +1,78 @@
+package lang
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+)
+
+// pinnedVersion returns the version the language server is pinned to, if any.
+func pinnedVersion(ctx context.Context, delegate Delegate, lspName string) (string, bool) {
+	version, ok := delegate.PinnedVersion(ctx, lspName)
+	if ok {
+		log.Debug().Msgf("%s: using pinned version %s", lspName, version)
+	}
+	return version, ok
+}
+
+// installedVersion falls back to the installed version when the latest version cannot be fetched, e.g. offline. The
+// version recorded in the manifest wins over the most recently installed one.
+func installedVersion(ctx context.Context, delegate Delegate, lspName string, fetchErr error) (string, error) {
+	var version string
+	if _, recorded, ok := delegate.InstalledBinary(ctx, lspName); ok {
+		version = recorded
+	} else if versions := delegate.InstalledVersions(ctx, lspName); len(versions) > 0 {
+		version = versions[len(versions)-1]
+	} else {
+		return "", fmt.Errorf("%s: failed to fetch latest version and no installed version found: %w", lspName, fetchErr)
+	}
+
+	log.Warn().Err(fetchErr).Msgf("%s: failed to fetch latest version, using installed version %s", lspName, version)
+	return version, nil
+}
+
+// npmPackageVersion resolves the latest version of an npm package, falling back to an installed version.
+func npmPackageVersion(ctx context.Context, delegate Delegate, lspName string, pkg string) (string, error) {
+	if version, ok := pinnedVersion(ctx, delegate, lspName); ok {
+		return version, nil
+	}
+
+	version, err := delegate.NpmPackageLatestVersion(ctx, pkg)
+	if err != nil {
+		return installedVersion(ctx, delegate, lspName, err)
+	}
+
+	log.Debug().Msgf("%s: got version %s", lspName, version)
+	return version, nil
+}
+
+// versionString returns the version as resolved by FetchLatestServerVersion.
+func versionString(version interface{}) string {
+	if v, ok := version.(string); ok && v != "" {
+		return v
+	}
+	return "latest"
+}
+
+// VersionString returns the version resolved by any adapter's FetchLatestServerVersion as it is recorded in the manifest.
+func VersionString(version interface{}) string {
+	switch v := version.(type) {
+	case goplsVersion:
+		return versionString(v.Version)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return versionString(version)
+	}
+}
+
+// userBinary looks up the executable of the language server in PATH.
+func userBinary(ctx context.Context, delegate Delegate, lspName string, executable string, args ...string) (*Binary, bool) {
+	path, ok := delegate.Which(ctx, executable)
+	if !ok {
+		return nil, false
+	}
+
+	return &Binary{Name: lspName, Path: path, Arguments: args}, true
+}
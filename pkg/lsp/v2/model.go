@@ -1,13 +1,24 @@
 package lsp
 
 import (
+	"strings"
+
 	protocol "github.com/tliron/glsp/protocol_3_16"
 )
 
+// SymbolSource tells how a symbol was found. Symbols found by a parser have no type information and may be incomplete.
+type SymbolSource = string
+
+const (
+	SymbolSourceLanguageServer SymbolSource = "lsp"
+	SymbolSourceParser         SymbolSource = "parser"
+)
+
 type SymbolInfo struct {
-	Name     string   `json:"name"`
-	Kind     string   `json:"kind"`
-	Location Location `json:"location"`
+	Name     string       `json:"name"`
+	Kind     string       `json:"kind"`
+	Location Location     `json:"location"`
+	Source   SymbolSource `json:"source"`
 }
 
 type Location struct {
@@ -28,6 +39,7 @@ type Position struct {
 type DocumentOutline struct {
 	Path            string           `json:"path"`
 	DocumentSymbols []DocumentSymbol `json:"document_symbols"`
+	Source          SymbolSource     `json:"source"`
 }
 
 type DocumentSymbol struct {
@@ -38,10 +50,85 @@ type DocumentSymbol struct {
 	Children []DocumentSymbol `json:"children,omitempty"`
 }
 
+type CompletionItem struct {
+	Label         string `json:"label"`
+	Kind          string `json:"kind,omitempty"`
+	Detail        string `json:"detail,omitempty"`
+	Documentation string `json:"documentation,omitempty"`
+	// FilterText is matched against the typed prefix, defaults to the label
+	FilterText string `json:"-"`
+}
+
+type SignatureHelp struct {
+	Signatures      []Signature `json:"signatures"`
+	ActiveSignature int         `json:"active_signature"`
+	ActiveParameter int         `json:"active_parameter"`
+}
+
+type Signature struct {
+	Label         string      `json:"label"`
+	Documentation string      `json:"documentation,omitempty"`
+	Parameters    []Parameter `json:"parameters,omitempty"`
+}
+
+type Parameter struct {
+	Label         string `json:"label"`
+	Documentation string `json:"documentation,omitempty"`
+}
+
+type CallDirection = string
+
+const (
+	CallDirectionIncoming CallDirection = "incoming"
+	CallDirectionOutgoing CallDirection = "outgoing"
+)
+
+type CallHierarchyItem struct {
+	Name     string   `json:"name"`
+	Kind     string   `json:"kind"`
+	Detail   string   `json:"detail,omitempty"`
+	Location Location `json:"location"`
+	// CallSites are ranges of the calls, in the caller for incoming calls and in the parent item for outgoing calls
+	CallSites []Range `json:"call_sites,omitempty"`
+	// Calls are callers of the item for incoming calls and callees for outgoing calls
+	Calls []CallHierarchyItem `json:"calls,omitempty"`
+}
+
+func rangeFrom(src protocol.Range) Range {
+	return Range{
+		Start: Position{
+			Line:      int(src.Start.Line) + 1, // src is zero indexed
+			Character: int(src.Start.Character),
+		},
+		End: Position{
+			Line:      int(src.End.Line) + 1, // src is zero indexed
+			Character: int(src.End.Character),
+		},
+	}
+}
+
+// NewDocumentOutline converts document symbols found by source other than a language server, e.g. a parser.
+func NewDocumentOutline(path string, symbols []protocol.DocumentSymbol, source SymbolSource) DocumentOutline {
+	out := documentOutlineFrom(symbols, path)
+	out.Source = source
+	return out
+}
+
+// NewSymbolInfo converts a document symbol of the file at path.
+func NewSymbolInfo(path string, symbol protocol.DocumentSymbol, source SymbolSource) SymbolInfo {
+	return SymbolInfo{
+		Name:     symbol.Name,
+		Kind:     symbolKindToString(symbol.Kind),
+		Location: Location{Path: path, Range: rangeFrom(symbol.Range)},
+		Source:   source,
+	}
+}
+
 func documentOutlineFrom(symbols []protocol.DocumentSymbol, path string) DocumentOutline {
 	out := DocumentOutline{
 		Path:            path,
 		DocumentSymbols: make([]DocumentSymbol, 0, len(symbols)),
+		Source:          SymbolSourceLanguageServer,
 	}
 
 	for _, symbol := range symbols {
@@ -79,6 +166,16 @@ func parseDocumentSymbol(src protocol.DocumentSymbol) DocumentSymbol {
 	return out
 }
 
+// ParseSymbolKind returns the kind with the name, ignoring case, e.g. "function" for SymbolKindFunction.
+func ParseSymbolKind(name string) (protocol.SymbolKind, bool) {
+	for kind := protocol.SymbolKindFile; kind <= protocol.SymbolKindTypeParameter; kind++ {
+		if strings.EqualFold(symbolKindToString(kind), name) {
+			return kind, true
+		}
+	}
+	return 0, false
+}
+
 func symbolKindToString(kind protocol.SymbolKind) string {
 	switch kind {
 	case protocol.SymbolKindFile:
@@ -3,6 +3,7 @@ package lsp
 import (
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
 	"syscall"
 
@@ -23,6 +24,8 @@ type Process interface {
 	Stop() error
 	ReadWriteCloser() io.ReadWriteCloser
 	Wait() error
+	// Done is closed when the process exits.
+	Done() <-chan struct{}
 }
 
 type readWriteCloser struct {
@@ -40,14 +43,16 @@ func (rwc *readWriteCloser) Close() error {
 }
 
 type ProcessImpl struct {
-	cmd *exec.Cmd
-	rwc io.ReadWriteCloser
+	cmd  *exec.Cmd
+	rwc  io.ReadWriteCloser
+	done chan struct{}
+	err  error // exit error, set before done is closed
 }
 
 func NewProcess(bin lang.Binary) (Process, error) {
 	cmd := exec.Command(bin.Path, bin.Arguments...)
-	// TODO: check why this prevents LSP server from working
-	// cmd.Env = bin.EnvAsKeyVal()
+	// the env of the binary is added to the env of hide, servers need e.g. PATH and HOME
+	cmd.Env = append(os.Environ(), bin.EnvAsKeyVal()...)
 
 	// Set SysProcAttr to create a new process group
 	cmd.SysProcAttr = &syscall.SysProcAttr{
@@ -66,11 +71,21 @@ func NewProcess(bin lang.Binary) (Process, error) {
 
 	rwc := &readWriteCloser{stdout, stdin}
 
-	return &ProcessImpl{cmd: cmd, rwc: rwc}, nil
+	return &ProcessImpl{cmd: cmd, rwc: rwc, done: make(chan struct{})}, nil
 }
 
 func (p *ProcessImpl) Start() error {
-	return p.cmd.Start()
+	if err := p.cmd.Start(); err != nil {
+		return err
+	}
+
+	// cmd.Wait must be called only once, waiters use done instead
+	go func() {
+		p.err = p.cmd.Wait()
+		close(p.done)
+	}()
+
+	return nil
 }
 
 func (p *ProcessImpl) Stop() error {
@@ -85,5 +100,10 @@ func (p *ProcessImpl) ReadWriteCloser() io.ReadWriteCloser {
 }
 
 func (p *ProcessImpl) Wait() error {
-	return p.cmd.Wait()
+	<-p.done
+	return p.err
+}
+
+func (p *ProcessImpl) Done() <-chan struct{} {
+	return p.done
 }
@@ -0,0 +1,46 @@
+package lsp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+
+	lang "github.com/hide-org/hide/pkg/lsp/v2/languages"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHelperProcess is not a real test, it is the language server process started by TestProcess_Env. It prints its
+// environment variables and exits once stdin is closed.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("HIDE_TEST_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	fmt.Printf("%s %t\n", os.Getenv("HIDE_TEST_SERVER_ENV"), os.Getenv("PATH") != "")
+	io.Copy(io.Discard, os.Stdin)
+	os.Exit(0)
+}
+
+func TestProcess_Env(t *testing.T) {
+	process, err := NewProcess(lang.Binary{
+		Name:      "helper",
+		Path:      os.Args[0],
+		Arguments: []string{"-test.run=^TestHelperProcess$"},
+		Env:       map[string]string{"HIDE_TEST_HELPER_PROCESS": "1", "HIDE_TEST_SERVER_ENV": "debug"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, process.Start())
+	defer process.Stop()
+
+	line, err := bufio.NewReader(process.ReadWriteCloser()).ReadString('\n')
+	require.NoError(t, err)
+
+	// the process sees the env of the binary on top of the env of hide
+	assert.Equal(t, "debug true\n", line)
+
+	require.NoError(t, process.ReadWriteCloser().Close())
+	assert.NoError(t, process.Wait())
+}
@@ -0,0 +1,159 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type ProgressKind = string
+
+const (
+	ProgressKindBegin  ProgressKind = "begin"
+	ProgressKindReport ProgressKind = "report"
+	ProgressKindEnd    ProgressKind = "end"
+)
+
+// Progress is a work done progress reported by the server, e.g. indexing of the workspace.
+type Progress struct {
+	Title      string  `json:"title"`
+	Message    string  `json:"message,omitempty"`
+	Percentage *uint32 `json:"percentage,omitempty"`
+}
+
+type progressParams struct {
+	Token json.RawMessage `json:"token"`
+	Value struct {
+		Kind       ProgressKind `json:"kind"`
+		Title      string       `json:"title"`
+		Message    string       `json:"message"`
+		Percentage *uint32      `json:"percentage"`
+	} `json:"value"`
+}
+
+type workDoneProgressCreateParams struct {
+	Token json.RawMessage `json:"token"`
+}
+
+// progressTracker keeps track of work done progress. The server is ready when no progress is pending or active.
+type progressTracker struct {
+	mu      sync.Mutex
+	pending map[string]struct{} // created tokens that did not begin yet, keyed by raw token
+	active  map[string]Progress // keyed by raw token
+	idle    chan struct{}       // closed while no progress is pending or active
+}
+
+func newProgressTracker() *progressTracker {
+	idle := make(chan struct{})
+	close(idle)
+
+	return &progressTracker{
+		pending: make(map[string]struct{}),
+		active:  make(map[string]Progress),
+		idle:    idle,
+	}
+}
+
+// create records a token of window/workDoneProgress/create. The server is busy from then on, even if it begins the
+// progress only later.
+func (p *progressTracker) create(token json.RawMessage) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.busy() {
+		p.idle = make(chan struct{})
+	}
+	p.pending[string(token)] = struct{}{}
+}
+
+func (p *progressTracker) update(params progressParams) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	token := string(params.Token)
+	switch params.Value.Kind {
+	case ProgressKindBegin:
+		if !p.busy() {
+			p.idle = make(chan struct{})
+		}
+		delete(p.pending, token)
+		p.active[token] = Progress{Title: params.Value.Title, Message: params.Value.Message, Percentage: params.Value.Percentage}
+	case ProgressKindReport:
+		progress, ok := p.active[token]
+		if !ok {
+			return
+		}
+		if params.Value.Message != "" {
+			progress.Message = params.Value.Message
+		}
+		if params.Value.Percentage != nil {
+			progress.Percentage = params.Value.Percentage
+		}
+		p.active[token] = progress
+	case ProgressKindEnd:
+		_, active := p.active[token]
+		_, pending := p.pending[token]
+		if !active && !pending {
+			return
+		}
+		delete(p.active, token)
+		delete(p.pending, token)
+		if !p.busy() {
+			close(p.idle)
+		}
+	}
+}
+
+// busy reports whether progress is pending or active, p.mu must be held.
+func (p *progressTracker) busy() bool {
+	return len(p.pending) > 0 || len(p.active) > 0
+}
+
+// list returns active progress sorted by title.
+func (p *progressTracker) list() []Progress {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	out := make([]Progress, 0, len(p.active))
+	for _, progress := range p.active {
+		out = append(out, progress)
+	}
+
+	slices.SortFunc(out, func(a, b Progress) int {
+		return strings.Compare(a.Title, b.Title)
+	})
+
+	return out
+}
+
+func (p *progressTracker) ready() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return !p.busy()
+}
+
+// wait blocks until no progress is pending or active, or the context is done.
+func (p *progressTracker) wait(ctx context.Context) error {
+	for {
+		p.mu.Lock()
+		idle := p.idle
+		p.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-idle:
+		}
+
+		// progress may have started again while we were woken up
+		p.mu.Lock()
+		ok := !p.busy()
+		p.mu.Unlock()
+		if ok {
+			return nil
+		}
+	}
+}
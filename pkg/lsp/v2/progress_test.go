@@ -0,0 +1,84 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func progress(token string, kind ProgressKind, title string) progressParams {
+	var params progressParams
+	params.Token = json.RawMessage(token)
+	params.Value.Kind = kind
+	params.Value.Title = title
+	return params
+}
+
+func TestProgressTracker(t *testing.T) {
+	tracker := newProgressTracker()
+	assert.True(t, tracker.ready())
+
+	tracker.update(progress(`"load"`, ProgressKindBegin, "Loading packages"))
+	tracker.update(progress(`1`, ProgressKindBegin, "Indexing"))
+	assert.False(t, tracker.ready())
+	assert.Equal(t, []Progress{{Title: "Indexing"}, {Title: "Loading packages"}}, tracker.list())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, tracker.wait(ctx), context.DeadlineExceeded)
+
+	// unknown tokens are ignored
+	tracker.update(progress(`"other"`, ProgressKindEnd, ""))
+	tracker.update(progress(`"load"`, ProgressKindEnd, ""))
+	assert.False(t, tracker.ready())
+
+	done := make(chan error)
+	go func() { done <- tracker.wait(context.Background()) }()
+
+	tracker.update(progress(`1`, ProgressKindEnd, ""))
+	assert.NoError(t, <-done)
+	assert.True(t, tracker.ready())
+	assert.Empty(t, tracker.list())
+}
+
+func TestProgressTracker_CreatedToken(t *testing.T) {
+	tracker := newProgressTracker()
+
+	done := make(chan error, 1)
+	tracker.create(json.RawMessage(`"index"`))
+	go func() { done <- tracker.wait(context.Background()) }()
+
+	// the server is busy from the creation of the token, even if it begins the progress much later
+	time.Sleep(50 * time.Millisecond)
+	assert.False(t, tracker.ready())
+	assert.Empty(t, tracker.list())
+	assert.Empty(t, done)
+
+	tracker.update(progress(`"index"`, ProgressKindBegin, "Indexing"))
+	assert.False(t, tracker.ready())
+	assert.Equal(t, []Progress{{Title: "Indexing"}}, tracker.list())
+	assert.Empty(t, done)
+
+	tracker.update(progress(`"index"`, ProgressKindEnd, ""))
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("wait did not return after the progress ended")
+	}
+	assert.True(t, tracker.ready())
+}
+
+func TestProgressTracker_CreatedTokenEndsWithoutBegin(t *testing.T) {
+	tracker := newProgressTracker()
+
+	tracker.create(json.RawMessage(`1`))
+	assert.False(t, tracker.ready())
+
+	tracker.update(progress(`1`, ProgressKindEnd, ""))
+	assert.True(t, tracker.ready())
+	assert.NoError(t, tracker.wait(context.Background()))
+}
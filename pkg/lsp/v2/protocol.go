@@ -0,0 +1,95 @@
+package lsp
+
+import (
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// Types from LSP 3.17 that are missing in protocol_3_16.
+// For reference see https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/
+
+// InitializeParams extends protocol.InitializeParams with 3.17 client capabilities.
+type InitializeParams struct {
+	protocol.InitializeParams
+	Capabilities ClientCapabilities `json:"capabilities"`
+}
+
+type ClientCapabilities struct {
+	protocol.ClientCapabilities
+	Workspace    *WorkspaceClientCapabilities    `json:"workspace,omitempty"`
+	TextDocument *TextDocumentClientCapabilities `json:"textDocument,omitempty"`
+	Window       *WindowClientCapabilities       `json:"window,omitempty"`
+}
+
+// WindowClientCapabilities names the anonymous window struct of protocol.ClientCapabilities, so that it can be constructed.
+type WindowClientCapabilities struct {
+	WorkDoneProgress *bool `json:"workDoneProgress,omitempty"`
+}
+
+// WorkspaceClientCapabilities names the anonymous workspace struct of protocol.ClientCapabilities, so that it can be constructed.
+type WorkspaceClientCapabilities struct {
+	WorkspaceFolders       *bool                                              `json:"workspaceFolders,omitempty"`
+	Configuration          *bool                                              `json:"configuration,omitempty"`
+	DidChangeConfiguration *protocol.DidChangeConfigurationClientCapabilities `json:"didChangeConfiguration,omitempty"`
+}
+
+type TextDocumentClientCapabilities struct {
+	protocol.TextDocumentClientCapabilities
+	Diagnostic *DiagnosticClientCapabilities `json:"diagnostic,omitempty"`
+}
+
+type DiagnosticClientCapabilities struct {
+	DynamicRegistration    *bool `json:"dynamicRegistration,omitempty"`
+	RelatedDocumentSupport *bool `json:"relatedDocumentSupport,omitempty"`
+}
+
+// ServerCapabilities holds the 3.17 server capabilities that protocol.ServerCapabilities drops when unmarshalling.
+type ServerCapabilities struct {
+	DiagnosticProvider *DiagnosticOptions `json:"diagnosticProvider,omitempty"`
+}
+
+type DiagnosticOptions struct {
+	Identifier            *string `json:"identifier,omitempty"`
+	InterFileDependencies bool    `json:"interFileDependencies"`
+	WorkspaceDiagnostics  bool    `json:"workspaceDiagnostics"`
+}
+
+type DocumentDiagnosticParams struct {
+	TextDocument     protocol.TextDocumentIdentifier `json:"textDocument"`
+	Identifier       *string                         `json:"identifier,omitempty"`
+	PreviousResultID *string                         `json:"previousResultId,omitempty"`
+}
+
+type DocumentDiagnosticReportKind = string
+
+const (
+	DocumentDiagnosticReportKindFull      DocumentDiagnosticReportKind = "full"
+	DocumentDiagnosticReportKindUnchanged DocumentDiagnosticReportKind = "unchanged"
+)
+
+// DocumentDiagnosticReport covers both full and unchanged reports, distinguished by Kind.
+type DocumentDiagnosticReport struct {
+	Kind             DocumentDiagnosticReportKind                      `json:"kind"`
+	ResultID         *string                                           `json:"resultId,omitempty"`
+	Items            []protocol.Diagnostic                             `json:"items,omitempty"`
+	RelatedDocuments map[protocol.DocumentUri]DocumentDiagnosticReport `json:"relatedDocuments,omitempty"`
+}
+
+type PreviousResultID struct {
+	URI   protocol.DocumentUri `json:"uri"`
+	Value string               `json:"value"`
+}
+
+type WorkspaceDiagnosticParams struct {
+	Identifier        *string            `json:"identifier,omitempty"`
+	PreviousResultIDs []PreviousResultID `json:"previousResultIds"`
+}
+
+type WorkspaceDiagnosticReport struct {
+	Items []WorkspaceDocumentDiagnosticReport `json:"items"`
+}
+
+type WorkspaceDocumentDiagnosticReport struct {
+	DocumentDiagnosticReport
+	URI     protocol.DocumentUri `json:"uri"`
+	Version *protocol.Integer    `json:"version"`
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 
 	"golang.org/x/sync/errgroup"
@@ -13,27 +14,39 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-func SetupServers(ctx context.Context, delegate lang.Delegate) error {
-	var g errgroup.Group
+// SetupServers installs language servers for the given languages, or for all supported languages if none are given.
+// A server that fails to install is skipped, its error is returned after all other servers are set up.
+func SetupServers(ctx context.Context, delegate lang.Delegate, languages ...lang.LanguageID) error {
+	var (
+		g    errgroup.Group
+		mu   sync.Mutex
+		errs []error
+	)
 	for _, adapter := range lang.Adapters {
 		adapter := adapter // capture loop variable
+		if len(languages) > 0 && !slices.ContainsFunc(adapter.Languages(), func(l lang.LanguageID) bool { return slices.Contains(languages, l) }) {
+			continue
+		}
+
 		g.Go(func() error {
 			err := runtime.setupServer(ctx, adapter, delegate)
 			if err != nil {
-				log.Error().Err(err).Msgf("Failed to setup server")
+				log.Error().Err(err).Str("server", adapter.Name()).Msgf("Failed to setup server")
+
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("failed to setup %s: %w", adapter.Name(), err))
+				mu.Unlock()
 			}
-			return err
+			return nil
 		})
 	}
 
-	if err := g.Wait(); err != nil {
-		return err
-	}
+	g.Wait()
 
 	// TODO: check concurrency safety
 	runtime.delegate = delegate
 
-	return nil
+	return errors.Join(errs...)
 }
 
 var runtime = run{
@@ -61,12 +74,7 @@ func (r *run) setupServer(ctx context.Context, adapter lang.Adapter, delegate la
 		return nil
 	}
 
-	version, err := adapter.FetchLatestServerVersion(ctx, delegate)
-	if err != nil {
-		return err
-	}
-
-	bin, err := adapter.FetchServerBinary(ctx, version, delegate)
+	bin, err := r.resolveBinary(ctx, adapter, delegate)
 	if err != nil {
 		return err
 	}
@@ -82,6 +90,41 @@ func (r *run) setupServer(ctx context.Context, adapter lang.Adapter, delegate la
 	return nil
 }
 
+// resolveBinary prefers a binary installed by the user unless the server is pinned to a version. Otherwise it installs the
+// latest or pinned version and falls back to the binary recorded in the manifest when installing fails, e.g. offline.
+func (r *run) resolveBinary(ctx context.Context, adapter lang.Adapter, delegate lang.Delegate) (*lang.Binary, error) {
+	srv := adapter.Name()
+
+	if _, pinned := delegate.PinnedVersion(ctx, srv); !pinned {
+		if bin, ok := adapter.CheckIfUserInstalled(ctx, delegate); ok {
+			log.Info().Str("server", srv).Msgf("Using language server installed at %s", bin.Path)
+			return bin, nil
+		}
+	}
+
+	version, err := adapter.FetchLatestServerVersion(ctx, delegate)
+	var bin *lang.Binary
+	if err == nil {
+		bin, err = adapter.FetchServerBinary(ctx, version, delegate)
+	}
+
+	if err != nil {
+		installed, installedVersion, ok := delegate.InstalledBinary(ctx, srv)
+		if !ok {
+			return nil, err
+		}
+
+		log.Warn().Err(err).Str("server", srv).Msgf("Failed to install language server, using installed version %s", installedVersion)
+		return installed, nil
+	}
+
+	if err := delegate.RecordInstall(ctx, srv, lang.VersionString(version), bin); err != nil {
+		log.Warn().Err(err).Str("server", srv).Msg("Failed to record language server install")
+	}
+
+	return bin, nil
+}
+
 func (r *run) startServer(_ context.Context, language lang.LanguageID) (Process, error) {
 	// TODO: check if process already running and if so return is running error
 	command, err := r.getBin(language)
@@ -155,6 +198,32 @@ func (r *run) registerSupport(srv lang.ServerName, adapter lang.Adapter) error {
 	return nil
 }
 
+// serverName returns the name of the server for the language, or the language itself if it is not supported.
+func (r *run) serverName(language lang.LanguageID) lang.ServerName {
+	r.RLock()
+	defer r.RUnlock()
+
+	adapter, ok := r.support[language]
+	if !ok {
+		return language
+	}
+
+	return adapter.Name()
+}
+
+// languages returns all languages served by the same server as the given language.
+func (r *run) languages(language lang.LanguageID) []lang.LanguageID {
+	r.RLock()
+	defer r.RUnlock()
+
+	adapter, ok := r.support[language]
+	if !ok {
+		return nil
+	}
+
+	return adapter.Languages()
+}
+
 func (r *run) isReady(srv lang.ServerName) bool {
 	r.RLock()
 	defer r.RUnlock()
@@ -174,3 +243,15 @@ func (r *run) serverInitOptions(ctx context.Context, lang lang.LanguageID) (json
 
 	return adapter.InitializationOptions(ctx, r.delegate), nil
 }
+
+func (r *run) workspaceConfiguration(ctx context.Context, lang lang.LanguageID) (json.RawMessage, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	adapter, ok := r.support[lang]
+	if !ok {
+		return nil, nil
+	}
+
+	return adapter.WorkspaceConfiguration(ctx, r.delegate)
+}
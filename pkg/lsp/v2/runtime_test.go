@@ -0,0 +1,111 @@
+package lsp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	lang "github.com/hide-org/hide/pkg/lsp/v2/languages"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeAdapter struct {
+	lang.Adapter
+	user       *lang.Binary
+	fetched    *lang.Binary
+	installErr error
+}
+
+func (a *fakeAdapter) Name() lang.ServerName { return "fake" }
+
+func (a *fakeAdapter) CheckIfUserInstalled(ctx context.Context, delegate lang.Delegate) (*lang.Binary, bool) {
+	return a.user, a.user != nil
+}
+
+func (a *fakeAdapter) FetchLatestServerVersion(ctx context.Context, delegate lang.Delegate) (interface{}, error) {
+	if a.installErr != nil {
+		return nil, a.installErr
+	}
+	return "1.0.0", nil
+}
+
+func (a *fakeAdapter) FetchServerBinary(ctx context.Context, version interface{}, delegate lang.Delegate) (*lang.Binary, error) {
+	return a.fetched, nil
+}
+
+type fakeDelegate struct {
+	lang.Delegate
+	pinned    string
+	installed *lang.Binary
+	recorded  map[lang.ServerName]string
+}
+
+func (d *fakeDelegate) PinnedVersion(ctx context.Context, lspName string) (string, bool) {
+	return d.pinned, d.pinned != ""
+}
+
+func (d *fakeDelegate) InstalledBinary(ctx context.Context, lspName string) (*lang.Binary, string, bool) {
+	return d.installed, "0.9.0", d.installed != nil
+}
+
+func (d *fakeDelegate) RecordInstall(ctx context.Context, lspName string, version string, bin *lang.Binary) error {
+	d.recorded[lspName] = version
+	return nil
+}
+
+func TestRun_ResolveBinary(t *testing.T) {
+	user := &lang.Binary{Name: "fake", Path: "/usr/bin/fake"}
+	fetched := &lang.Binary{Name: "fake", Path: "/bin/fake/1.0.0/fake"}
+	installed := &lang.Binary{Name: "fake", Path: "/bin/fake/0.9.0/fake"}
+	offline := errors.New("offline")
+
+	tests := []struct {
+		name         string
+		adapter      *fakeAdapter
+		delegate     *fakeDelegate
+		want         *lang.Binary
+		wantErr      error
+		wantRecorded map[lang.ServerName]string
+	}{
+		{
+			name:         "user installed",
+			adapter:      &fakeAdapter{user: user, fetched: fetched},
+			delegate:     &fakeDelegate{},
+			want:         user,
+			wantRecorded: map[lang.ServerName]string{},
+		},
+		{
+			name:         "pinned ignores user installed",
+			adapter:      &fakeAdapter{user: user, fetched: fetched},
+			delegate:     &fakeDelegate{pinned: "1.0.0"},
+			want:         fetched,
+			wantRecorded: map[lang.ServerName]string{"fake": "1.0.0"},
+		},
+		{
+			name:         "offline falls back to installed",
+			adapter:      &fakeAdapter{installErr: offline},
+			delegate:     &fakeDelegate{installed: installed},
+			want:         installed,
+			wantRecorded: map[lang.ServerName]string{},
+		},
+		{
+			name:         "offline without installed",
+			adapter:      &fakeAdapter{installErr: offline},
+			delegate:     &fakeDelegate{},
+			wantErr:      offline,
+			wantRecorded: map[lang.ServerName]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.delegate.recorded = make(map[lang.ServerName]string)
+			r := run{}
+
+			got, err := r.resolveBinary(context.Background(), tt.adapter, tt.delegate)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantRecorded, tt.delegate.recorded)
+		})
+	}
+}
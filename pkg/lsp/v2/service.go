@@ -2,15 +2,22 @@ package lsp
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	lang "github.com/hide-org/hide/pkg/lsp/v2/languages"
 	"github.com/hide-org/hide/pkg/model"
 	"github.com/rs/zerolog/log"
 	protocol "github.com/tliron/glsp/protocol_3_16"
+	"golang.org/x/sync/errgroup"
 )
 
 type (
@@ -24,63 +31,155 @@ type Service interface {
 	StopServer(ctx context.Context, languageId lang.LanguageID) error
 	GetWorkspaceSymbols(ctx context.Context, query string, symbolFilter SymbolFilter) ([]SymbolInfo, error)
 	GetDocumentOutline(ctx context.Context, file model.File) (DocumentOutline, error)
+	// GetCompletions returns completion items at position ranked by the server. The file must have content.
+	GetCompletions(ctx context.Context, file model.File, position protocol.Position) ([]CompletionItem, error)
+	// GetSignatureHelp returns signatures of the call at position. The file must have content.
+	GetSignatureHelp(ctx context.Context, file model.File, position protocol.Position) (SignatureHelp, error)
+	// GetCallHierarchy returns the symbol at position with its callers or callees, followed up to depth levels. The file
+	// must have content.
+	GetCallHierarchy(ctx context.Context, file model.File, position protocol.Position, direction CallDirection, depth int) ([]CallHierarchyItem, error)
+	// GetImplementations returns locations of implementations of the symbol at position, e.g. types implementing an
+	// interface. The file must have content.
+	GetImplementations(ctx context.Context, file model.File, position protocol.Position) ([]Location, error)
 	NotifyDidOpen(ctx context.Context, file model.File) error
 	NotifyDidClose(ctx context.Context, file model.File) error
-	// TODO: check if any LSP server supports this
-	// PullDiagnostics(ctx context.Context, params DocumentDiagnosticParams) (DocumentDiagnosticReport, error)
+	// PullDiagnostics requests diagnostics for the file with textDocument/diagnostic. Returns PullDiagnosticsNotSupportedError
+	// if the language server only publishes diagnostics, in which case use GetDiagnostics.
+	PullDiagnostics(ctx context.Context, file model.File) ([]protocol.Diagnostic, error)
+	// PullWorkspaceDiagnostics refreshes the diagnostics store with workspace/diagnostic from every server that supports it.
+	PullWorkspaceDiagnostics(ctx context.Context) error
+	// GetDiagnostics returns the diagnostics stored for the file, either published by the server or pulled before.
 	GetDiagnostics(ctx context.Context, file model.File) ([]protocol.Diagnostic, error)
+	// GetWorkspaceDiagnostics returns all stored diagnostics keyed by document URI.
+	GetWorkspaceDiagnostics(ctx context.Context) (LspDiagnostics, error)
+	// Health reports the state of running language servers.
+	Health(ctx context.Context) []ServerHealth
 	Cleanup(ctx context.Context) error
 }
 
+// how long symbol queries wait for the server to finish indexing
+const readyTimeout = 30 * time.Second
+
+type openDocument struct {
+	languageId lang.LanguageID
+	item       protocol.TextDocumentItem
+}
+
 type ServiceImpl struct {
 	languageDetector LanguageDetector
 	clientPool       ClientPool
 	diagnosticsStore *DiagnosticsStore
 	// TODO: can we pass the root URI as url.URL?
 	rootURI string // example: "file:///workspace"
+
+	serverOverrides map[lang.ServerName]ServerSettings
+
+	mu            sync.Mutex
+	supervisors   map[lang.ServerName]*supervisor
+	openDocuments map[protocol.DocumentUri]openDocument // replayed when a server restarts
 }
 
 // StartServer implements Service.
 func (s *ServiceImpl) StartServer(ctx context.Context, languageId lang.LanguageID) error {
+	if _, ok := s.getClient(ctx, languageId); ok {
+		return NewLanguageServerAlreadyExistsError(languageId)
+	}
+
+	server := runtime.serverName(languageId)
+
+	// one server can handle several languages, e.g. JavaScript and TypeScript
+	for _, other := range runtime.languages(languageId) {
+		if client, ok := s.getClient(ctx, other); ok {
+			log.Debug().Str("languageId", languageId).Str("sharedWith", other).Msg("Reusing running language server")
+			s.clientPool.Set(languageId, client)
+			if sv, ok := s.getSupervisor(server); ok {
+				sv.addLanguage(languageId)
+			}
+			return nil
+		}
+	}
+
+	client, err := s.launch(ctx, languageId)
+	if err != nil {
+		return err
+	}
+
+	s.clientPool.Set(languageId, client)
+
+	sv := newSupervisor(server, languageId, client)
+	s.mu.Lock()
+	s.supervisors[server] = sv
+	s.mu.Unlock()
+
+	go s.supervise(sv)
+	return nil
+}
+
+// launch starts the server process and performs the initialize handshake.
+func (s *ServiceImpl) launch(ctx context.Context, languageId lang.LanguageID) (Client, error) {
 	process, err := runtime.startServer(ctx, languageId)
 	if err != nil {
 		log.Error().Str("languageId", languageId).Err(err).Msg("Failed to start language server process")
-		return err
+		return nil, err
 	}
 
-	// _, err = runtime.serverInitOptions(ctx, languageId)
-	// if err != nil {
-	// 	return err
-	// }
+	opts, settings, err := s.serverSettings(ctx, languageId)
+	if err != nil {
+		log.Error().Str("languageId", languageId).Err(err).Msg("Failed to get language server settings")
+		process.Stop()
+		return nil, err
+	}
+
+	root := DocumentURI(s.rootURI)
+	folders := []protocol.WorkspaceFolder{
+		{
+			URI: root,
+			// Name: project.Id, TODO: remove or use root
+		},
+	}
 
 	// Create a client for the language server
-	client, diagnostics := NewClient(process)
+	client, diagnostics := NewClient(process, ClientConfig{Settings: settings, WorkspaceFolders: folders})
 
 	// Initialize the language server
-	root := DocumentURI(s.rootURI)
-	initResult, err := client.Initialize(ctx, protocol.InitializeParams{
-		RootURI: &root,
-		Capabilities: protocol.ClientCapabilities{
-			TextDocument: &protocol.TextDocumentClientCapabilities{
-				Synchronization: &protocol.TextDocumentSyncClientCapabilities{
-					DynamicRegistration: boolPointer(true),
-				},
-				DocumentSymbol: &protocol.DocumentSymbolClientCapabilities{
-					HierarchicalDocumentSymbolSupport: boolPointer(true),
+	initResult, err := client.Initialize(ctx, InitializeParams{
+		InitializeParams: protocol.InitializeParams{
+			RootURI:               &root,
+			WorkspaceFolders:      folders,
+			InitializationOptions: opts,
+		},
+		Capabilities: ClientCapabilities{
+			Window: &WindowClientCapabilities{
+				WorkDoneProgress: boolPointer(true),
+			},
+			Workspace: &WorkspaceClientCapabilities{
+				WorkspaceFolders: boolPointer(true),
+				Configuration:    boolPointer(true),
+				DidChangeConfiguration: &protocol.DidChangeConfigurationClientCapabilities{
+					DynamicRegistration: boolPointer(false),
 				},
 			},
-		},
-		WorkspaceFolders: []protocol.WorkspaceFolder{
-			{
-				URI: root,
-				// Name: project.Id, TODO: remove or use root
+			TextDocument: &TextDocumentClientCapabilities{
+				TextDocumentClientCapabilities: protocol.TextDocumentClientCapabilities{
+					Synchronization: &protocol.TextDocumentSyncClientCapabilities{
+						DynamicRegistration: boolPointer(true),
+					},
+					DocumentSymbol: &protocol.DocumentSymbolClientCapabilities{
+						HierarchicalDocumentSymbolSupport: boolPointer(true),
+					},
+					Completion:    &protocol.CompletionClientCapabilities{},
+					SignatureHelp: &protocol.SignatureHelpClientCapabilities{},
+				},
+				Diagnostic: &DiagnosticClientCapabilities{
+					RelatedDocumentSupport: boolPointer(true),
+				},
 			},
 		},
-		// InitializationOptions: opts,
 	})
 	if err != nil {
 		log.Error().Str("languageId", languageId).Err(err).Msg("Failed to initialize language server")
-		return fmt.Errorf("failed to initialize language server: %w", err)
+		client.Kill()
+		return nil, fmt.Errorf("failed to initialize language server: %w", err)
 	}
 
 	log.Debug().Str("languageId", languageId).Msg("Initialized language server")
@@ -90,18 +189,54 @@ func (s *ServiceImpl) StartServer(ctx context.Context, languageId lang.LanguageI
 		log.Debug().Str("languageId", languageId).Msgf("LSP server supports open/close file: %t", *opt.OpenClose)
 		log.Debug().Str("languageId", languageId).Msgf("LSP server supports change notifications: %v", *opt.Change)
 	}
+	if opt, ok := client.DiagnosticProvider(); ok {
+		log.Debug().Str("languageId", languageId).Msgf("LSP server supports pull diagnostics, workspace diagnostics: %t", opt.WorkspaceDiagnostics)
+	}
 
 	// Notify that initialized
 	if err := client.NotifyInitialized(ctx); err != nil {
 		log.Error().Err(err).Str("languageId", languageId).Msg("Failed to notify initialized")
-		return fmt.Errorf("Failed to notify initialized: %w", err)
+		client.Kill()
+		return nil, fmt.Errorf("Failed to notify initialized: %w", err)
 	}
 
-	s.clientPool.Set(languageId, client)
+	// servers that don't pull settings with workspace/configuration read them from this notification
+	if !isEmptySettings(settings) {
+		if err := client.NotifyDidChangeConfiguration(ctx, protocol.DidChangeConfigurationParams{Settings: settings}); err != nil {
+			log.Warn().Err(err).Str("languageId", languageId).Msg("Failed to notify configuration change")
+		}
+	}
 
-	// TODO: kill this goroutine when the project is deleted
-	go s.listenForDiagnostics(diagnostics)
-	return nil
+	go s.listenForDiagnostics(client, diagnostics)
+	return client, nil
+}
+
+// serverSettings returns initialization options and workspace settings of the adapter, with user overrides applied.
+func (s *ServiceImpl) serverSettings(ctx context.Context, languageId lang.LanguageID) (opts json.RawMessage, settings json.RawMessage, err error) {
+	opts, err = runtime.serverInitOptions(ctx, languageId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	settings, err = runtime.workspaceConfiguration(ctx, languageId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	override, ok := s.serverOverrides[runtime.serverName(languageId)]
+	if !ok {
+		return opts, settings, nil
+	}
+
+	if opts, err = mergeSettings(opts, override.InitializationOptions); err != nil {
+		return nil, nil, fmt.Errorf("invalid initialization options: %w", err)
+	}
+
+	if settings, err = mergeSettings(settings, override.Settings); err != nil {
+		return nil, nil, fmt.Errorf("invalid settings: %w", err)
+	}
+
+	return opts, settings, nil
 }
 
 func (s *ServiceImpl) StopServer(ctx context.Context, languageId lang.LanguageID) error {
@@ -112,13 +247,29 @@ func (s *ServiceImpl) StopServer(ctx context.Context, languageId lang.LanguageID
 		return nil
 	}
 
+	s.clientPool.Delete(languageId)
+
+	server := runtime.serverName(languageId)
+	if sv, ok := s.getSupervisor(server); ok && sv.removeLanguage(languageId) == 0 {
+		sv.close()
+
+		s.mu.Lock()
+		delete(s.supervisors, server)
+		s.mu.Unlock()
+	}
+
+	// keep the server running while other languages still use it
+	for _, other := range s.clientPool.GetAll() {
+		if other == client {
+			return nil
+		}
+	}
+
 	if err := client.Shutdown(ctx); err != nil {
 		log.Error().Err(err).Str("languageId", languageId).Msg("Failed to stop language server")
 		return fmt.Errorf("Failed to stop language server: %w", err)
 	}
 
-	s.clientPool.Delete(languageId)
-
 	return nil
 }
 
@@ -129,19 +280,51 @@ func (s *ServiceImpl) GetWorkspaceSymbols(ctx context.Context, query string, sym
 		return nil, nil
 	}
 
-	symbols := []protocol.SymbolInformation{}
-	for _, client := range clients {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		default:
-		}
+	// query all servers in parallel, results are merged in client order
+	results := make([][]protocol.SymbolInformation, len(clients))
+	errs := make([]error, len(clients))
+	g, gctx := errgroup.WithContext(ctx)
+	for i, client := range clients {
+		i, client := i, client // capture loop variables
+		g.Go(func() error {
+			select {
+			case <-gctx.Done():
+				return gctx.Err()
+			default:
+			}
 
-		result, err := client.GetWorkspaceSymbols(ctx, protocol.WorkspaceSymbolParams{Query: query})
-		if err != nil {
-			return nil, err
-		}
+			// the index is incomplete while the server is still loading the workspace
+			if err := s.waitReady(gctx, client); err != nil {
+				return err
+			}
+
+			result, err := client.GetWorkspaceSymbols(gctx, protocol.WorkspaceSymbolParams{Query: query})
+			if err != nil {
+				if gctx.Err() != nil {
+					return gctx.Err()
+				}
+
+				// one failing server does not fail the query, the others still answer
+				log.Error().Err(err).Strs("languages", s.clientLanguages(client)).Msg("Failed to get workspace symbols")
+				errs[i] = err
+				return nil
+			}
+
+			results[i] = result
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(errs, nil) {
+		return nil, fmt.Errorf("failed to get workspace symbols: %w", errors.Join(errs...))
+	}
 
+	symbols := []protocol.SymbolInformation{}
+	for _, result := range results {
 		for _, symbol := range result {
 			if symbolFilter.shouldExcludeSymbol(symbol) {
 				continue
@@ -161,29 +344,12 @@ func (s *ServiceImpl) GetWorkspaceSymbols(ctx context.Context, query string, sym
 		default:
 		}
 
-		symbolPath, err := removeFilePrefix(symbol.Location.URI)
-		if err != nil {
-			log.Error().Err(err).Str("URI", symbol.Location.URI).Msg("failed to remove file prefix from URI")
-			return nil, fmt.Errorf("failed to remove file prefix from URI: %w", err)
-		}
-
-		url, err := url.Parse(symbolPath)
-		if err != nil {
-			log.Error().Err(err).Str("path", symbolPath).Msg("failed to parse path")
-			return nil, fmt.Errorf("failed to parse path: %w", err)
-		}
-
-		relativePath, err := filepath.Rel(url.Path, symbolPath)
-		if err != nil {
-			log.Error().Err(err).Str("base", url.Path).Str("path", symbolPath).Msg("failed to get relative path of file")
-			return nil, fmt.Errorf("failed to get relative path of file: %w", err)
-		}
-
 		result = append(result, SymbolInfo{
 			Name: symbol.Name,
 			Kind: symbolKindToString(symbol.Kind),
 			// NOTE: LSP uses 0-based line numbers, but Hide uses 1-based. Characters remain 0-based.
-			Location: Location{Path: relativePath, Range: Range{Start: Position{Line: int(symbol.Location.Range.Start.Line) + 1, Character: int(symbol.Location.Range.Start.Character)}, End: Position{Line: int(symbol.Location.Range.End.Line) + 1, Character: int(symbol.Location.Range.End.Character)}}},
+			Location: Location{Path: s.workspacePath(symbol.Location.URI), Range: Range{Start: Position{Line: int(symbol.Location.Range.Start.Line) + 1, Character: int(symbol.Location.Range.Start.Character)}, End: Position{Line: int(symbol.Location.Range.End.Line) + 1, Character: int(symbol.Location.Range.End.Character)}}},
+			Source:   SymbolSourceLanguageServer,
 		})
 	}
 
@@ -198,6 +364,10 @@ func (s *ServiceImpl) GetDocumentOutline(ctx context.Context, file model.File) (
 		return DocumentOutline{}, NewLanguageServerNotFoundError(lang)
 	}
 
+	if err := s.waitReady(ctx, cli); err != nil {
+		return DocumentOutline{}, err
+	}
+
 	symbols, err := cli.GetDocumentSymbols(ctx, protocol.DocumentSymbolParams{
 		TextDocument: protocol.TextDocumentIdentifier{
 			URI: DocumentURI(file.Path),
@@ -220,9 +390,15 @@ func (s *ServiceImpl) NotifyDidClose(ctx context.Context, file model.File) error
 		return NewLanguageServerNotFoundError(languageId)
 	}
 
+	uri := DocumentURI(file.Path)
+
+	s.mu.Lock()
+	delete(s.openDocuments, uri)
+	s.mu.Unlock()
+
 	err := client.NotifyDidClose(ctx, protocol.DidCloseTextDocumentParams{
 		TextDocument: protocol.TextDocumentIdentifier{
-			URI: DocumentURI(file.Path),
+			URI: uri,
 		},
 	})
 
@@ -239,15 +415,92 @@ func (s *ServiceImpl) NotifyDidOpen(ctx context.Context, file model.File) error
 		return NewLanguageServerNotFoundError(languageId)
 	}
 
-	err := client.NotifyDidOpen(ctx, protocol.DidOpenTextDocumentParams{
-		TextDocument: protocol.TextDocumentItem{
-			URI:     DocumentURI(file.Path),
-			Version: 1,
-			Text:    file.GetContent(),
-		},
-	})
+	item := protocol.TextDocumentItem{
+		URI:     DocumentURI(file.Path),
+		Version: 1,
+		Text:    file.GetContent(),
+	}
 
-	return err
+	if err := client.NotifyDidOpen(ctx, protocol.DidOpenTextDocumentParams{TextDocument: item}); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.openDocuments[item.URI] = openDocument{languageId: languageId, item: item}
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *ServiceImpl) PullDiagnostics(ctx context.Context, file model.File) ([]protocol.Diagnostic, error) {
+	languageId := s.languageDetector.DetectLanguage(&file)
+	client, ok := s.getClient(ctx, languageId)
+	if !ok {
+		log.Warn().Str("languageId", languageId).Msg("LSP client not found")
+		return nil, NewLanguageServerNotFoundError(languageId)
+	}
+
+	opts, ok := client.DiagnosticProvider()
+	if !ok {
+		return nil, NewPullDiagnosticsNotSupportedError(languageId)
+	}
+
+	uri := DocumentURI(file.Path)
+	params := DocumentDiagnosticParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		Identifier:   opts.Identifier,
+	}
+	if resultID, ok := s.diagnosticsStore.GetResultID(client, uri); ok {
+		params.PreviousResultID = &resultID
+	}
+
+	report, err := client.PullDiagnostics(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull diagnostics for %s: %w", file.Path, err)
+	}
+
+	for relatedURI, related := range report.RelatedDocuments {
+		s.diagnosticsStore.SetReport(client, relatedURI, related)
+	}
+
+	return s.diagnosticsStore.SetReport(client, uri, report), nil
+}
+
+func (s *ServiceImpl) PullWorkspaceDiagnostics(ctx context.Context) error {
+	var pulled int
+	var errs []error
+	for _, client := range s.getClients(ctx) {
+		opts, ok := client.DiagnosticProvider()
+		if !ok || !opts.WorkspaceDiagnostics {
+			continue
+		}
+
+		pulled++
+		report, err := client.PullWorkspaceDiagnostics(ctx, WorkspaceDiagnosticParams{
+			Identifier:        opts.Identifier,
+			PreviousResultIDs: s.diagnosticsStore.GetResultIDs(client),
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			// one failing server does not fail the pull, the diagnostics of the others are still updated
+			log.Error().Err(err).Strs("languages", s.clientLanguages(client)).Msg("Failed to pull workspace diagnostics")
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, item := range report.Items {
+			s.diagnosticsStore.SetReport(client, item.URI, item.DocumentDiagnosticReport)
+		}
+	}
+
+	if pulled > 0 && len(errs) == pulled {
+		return fmt.Errorf("failed to pull workspace diagnostics: %w", errors.Join(errs...))
+	}
+
+	return nil
 }
 
 func (s *ServiceImpl) GetDiagnostics(ctx context.Context, file model.File) ([]protocol.Diagnostic, error) {
@@ -259,8 +512,19 @@ func (s *ServiceImpl) GetDiagnostics(ctx context.Context, file model.File) ([]pr
 	return nil, nil
 }
 
+func (s *ServiceImpl) GetWorkspaceDiagnostics(ctx context.Context) (LspDiagnostics, error) {
+	return s.diagnosticsStore.GetAll(), nil
+}
+
 func (s *ServiceImpl) Cleanup(ctx context.Context) error {
-	for _, client := range s.clientPool.GetAll() {
+	s.mu.Lock()
+	for server, sv := range s.supervisors {
+		sv.close()
+		delete(s.supervisors, server)
+	}
+	s.mu.Unlock()
+
+	for _, client := range s.getClients(ctx) {
 		if err := client.Shutdown(ctx); err != nil {
 			return err
 		}
@@ -274,27 +538,78 @@ func (s *ServiceImpl) getClient(ctx context.Context, languageId lang.LanguageID)
 	return client, ok
 }
 
+// getClients returns every running client once, ordered by language for determinism.
 func (s *ServiceImpl) getClients(ctx context.Context) []Client {
-	clients := make([]Client, 0)
-	for _, client := range s.clientPool.GetAll() {
-		clients = append(clients, client)
+	all := s.clientPool.GetAll()
+
+	languages := make([]lang.LanguageID, 0, len(all))
+	for languageId := range all {
+		languages = append(languages, languageId)
+	}
+	sort.Strings(languages)
+
+	clients := make([]Client, 0, len(all))
+	for _, languageId := range languages {
+		// clients are shared between languages of the same server
+		if !slices.Contains(clients, all[languageId]) {
+			clients = append(clients, all[languageId])
+		}
 	}
 
 	return clients
 }
 
-func (s *ServiceImpl) listenForDiagnostics(channel <-chan protocol.PublishDiagnosticsParams) {
-	log.Debug().Msg("Start listening")
+// clientLanguages returns the languages served by the client, for logging.
+func (s *ServiceImpl) clientLanguages(client Client) []lang.LanguageID {
+	var languages []lang.LanguageID
+	for languageId, c := range s.clientPool.GetAll() {
+		if c == client {
+			languages = append(languages, languageId)
+		}
+	}
+	sort.Strings(languages)
+	return languages
+}
 
-	// reads fro chanel util it is closed
-	for diagnostics := range channel {
-		log.Debug().Str("uri", diagnostics.URI).Msg("Received diagnostics")
-		log.Debug().Str("uri", diagnostics.URI).Msgf("Diagnostics: %+v", diagnostics.Diagnostics)
+// waitReady waits up to readyTimeout for the server to finish its work in progress. Servers that stay busy are queried anyway.
+func (s *ServiceImpl) waitReady(ctx context.Context, client Client) error {
+	wctx, cancel := context.WithTimeout(ctx, readyTimeout)
+	defer cancel()
 
-		s.updateDiagnostics(diagnostics)
+	if err := client.WaitReady(wctx); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.Warn().Interface("progress", client.Progress()).Msg("Language server is still busy, querying anyway")
 	}
 
-	log.Debug().Msg("Done listening")
+	return nil
+}
+
+func (s *ServiceImpl) getSupervisor(server lang.ServerName) (*supervisor, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sv, ok := s.supervisors[server]
+	return sv, ok
+}
+
+func (s *ServiceImpl) listenForDiagnostics(client Client, channel Diagnostics) {
+	log.Debug().Msg("Start listening")
+
+	// reads from channel until the client is done
+	for {
+		select {
+		case <-client.Done():
+			log.Debug().Msg("Done listening")
+			return
+		case diagnostics := <-channel:
+			log.Debug().Str("uri", diagnostics.URI).Msg("Received diagnostics")
+			log.Debug().Str("uri", diagnostics.URI).Msgf("Diagnostics: %+v", diagnostics.Diagnostics)
+
+			s.updateDiagnostics(diagnostics)
+		}
+	}
 }
 
 func (s *ServiceImpl) updateDiagnostics(diagnostics protocol.PublishDiagnosticsParams) {
@@ -308,19 +623,40 @@ func DocumentURI(pathURI string) protocol.DocumentUri {
 	return protocol.DocumentUri("file://" + pathURI)
 }
 
-func NewService(languageDetector LanguageDetector, diagnosticsStore *DiagnosticsStore, clientPool ClientPool, rootURI string) Service {
-	return &ServiceImpl{
+type ServiceOption func(s *ServiceImpl)
+
+// ServiceWithServerSettings overrides adapter defaults of the servers, keyed by server name.
+func ServiceWithServerSettings(settings map[lang.ServerName]ServerSettings) ServiceOption {
+	return func(s *ServiceImpl) {
+		s.serverOverrides = settings
+	}
+}
+
+func NewService(languageDetector LanguageDetector, diagnosticsStore *DiagnosticsStore, clientPool ClientPool, rootURI string, opts ...ServiceOption) Service {
+	s := &ServiceImpl{
 		languageDetector: languageDetector,
 		clientPool:       clientPool,
 		diagnosticsStore: diagnosticsStore,
 		rootURI:          rootURI,
+		supervisors:      make(map[lang.ServerName]*supervisor),
+		openDocuments:    make(map[protocol.DocumentUri]openDocument),
 	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
 }
 
 func boolPointer(b bool) *bool {
 	return &b
 }
 
+func stringPointer(s string) *string {
+	return &s
+}
+
 func removeFilePrefix(fileURL string) (string, error) {
 	u, err := url.Parse(fileURL)
 	if err != nil {
@@ -0,0 +1,289 @@
+package lsp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+type fakeClient struct {
+	Client
+	symbols            []protocol.SymbolInformation
+	symbolsErr         error
+	diagnosticProvider *DiagnosticOptions
+	reports            []DocumentDiagnosticReport
+	workspaceReport    WorkspaceDiagnosticReport
+	workspaceErr       error
+	implementations    []protocol.Location
+
+	// requests on documents in order, e.g. didOpen file:///workspace/main.go
+	requests        []string
+	opened          []protocol.TextDocumentItem
+	pulled          []DocumentDiagnosticParams
+	pulledWorkspace []WorkspaceDiagnosticParams
+}
+
+func (c *fakeClient) WaitReady(ctx context.Context) error {
+	return nil
+}
+
+func (c *fakeClient) GetWorkspaceSymbols(ctx context.Context, params protocol.WorkspaceSymbolParams) ([]protocol.SymbolInformation, error) {
+	return c.symbols, c.symbolsErr
+}
+
+func (c *fakeClient) NotifyDidOpen(ctx context.Context, params protocol.DidOpenTextDocumentParams) error {
+	c.requests = append(c.requests, "didOpen "+params.TextDocument.URI)
+	c.opened = append(c.opened, params.TextDocument)
+	return nil
+}
+
+func (c *fakeClient) NotifyDidClose(ctx context.Context, params protocol.DidCloseTextDocumentParams) error {
+	c.requests = append(c.requests, "didClose "+params.TextDocument.URI)
+	return nil
+}
+
+func (c *fakeClient) GetImplementations(ctx context.Context, params protocol.ImplementationParams) ([]protocol.Location, error) {
+	c.requests = append(c.requests, "implementation "+params.TextDocument.URI)
+	return c.implementations, nil
+}
+
+func (c *fakeClient) DiagnosticProvider() (DiagnosticOptions, bool) {
+	if c.diagnosticProvider == nil {
+		return DiagnosticOptions{}, false
+	}
+	return *c.diagnosticProvider, true
+}
+
+func (c *fakeClient) PullDiagnostics(ctx context.Context, params DocumentDiagnosticParams) (DocumentDiagnosticReport, error) {
+	c.pulled = append(c.pulled, params)
+	report := c.reports[0]
+	c.reports = c.reports[1:]
+	return report, nil
+}
+
+func (c *fakeClient) PullWorkspaceDiagnostics(ctx context.Context, params WorkspaceDiagnosticParams) (WorkspaceDiagnosticReport, error) {
+	c.pulledWorkspace = append(c.pulledWorkspace, params)
+	return c.workspaceReport, c.workspaceErr
+}
+
+func newTestService(clients map[string]Client) *ServiceImpl {
+	pool := NewClientPool()
+	for languageId, client := range clients {
+		pool.Set(languageId, client)
+	}
+	return NewService(NewLanguageDetector(), NewDiagnosticsStore(), pool, "file:///workspace").(*ServiceImpl)
+}
+
+func symbolInformation(name string, kind protocol.SymbolKind, uri protocol.DocumentUri, line protocol.UInteger) protocol.SymbolInformation {
+	return protocol.SymbolInformation{
+		Name:     name,
+		Kind:     kind,
+		Location: protocol.Location{URI: uri, Range: protocol.Range{Start: protocol.Position{Line: line}, End: protocol.Position{Line: line}}},
+	}
+}
+
+func symbolNames(symbols []SymbolInfo) []string {
+	var names []string
+	for _, symbol := range symbols {
+		names = append(names, symbol.Name)
+	}
+	return names
+}
+
+func TestService_GetWorkspaceSymbols(t *testing.T) {
+	client := &fakeClient{symbols: []protocol.SymbolInformation{
+		symbolInformation("Server", protocol.SymbolKindStruct, "file:///workspace/pkg/server/server.go", 2),
+		symbolInformation("Server", protocol.SymbolKindStruct, "file:///go/pkg/mod/example.com/server.go", 0),
+		symbolInformation("serverField", protocol.SymbolKindField, "file:///workspace/main.go", 4),
+	}}
+	s := newTestService(map[string]Client{"Go": client})
+
+	symbols, err := s.GetWorkspaceSymbols(context.Background(), "Server", NewExcludeSymbolFilter(protocol.SymbolKindField))
+	require.NoError(t, err)
+
+	// paths are relative to the workspace, unless the symbol is outside of it
+	assert.Equal(t, []SymbolInfo{
+		{Name: "Server", Kind: "Struct", Location: Location{Path: "pkg/server/server.go", Range: Range{Start: Position{Line: 3}, End: Position{Line: 3}}}, Source: SymbolSourceLanguageServer},
+		{Name: "Server", Kind: "Struct", Location: Location{Path: "/go/pkg/mod/example.com/server.go", Range: Range{Start: Position{Line: 1}, End: Position{Line: 1}}}, Source: SymbolSourceLanguageServer},
+	}, symbols)
+}
+
+func TestService_GetWorkspaceSymbols_FailingServer(t *testing.T) {
+	goClient := &fakeClient{symbols: []protocol.SymbolInformation{
+		symbolInformation("Server", protocol.SymbolKindStruct, "file:///workspace/server.go", 2),
+	}}
+	pyClient := &fakeClient{symbolsErr: errors.New("request failed")}
+	tsClient := &fakeClient{symbols: []protocol.SymbolInformation{
+		symbolInformation("ServerOptions", protocol.SymbolKindInterface, "file:///workspace/web/server.ts", 0),
+	}}
+
+	s := newTestService(map[string]Client{"Go": goClient, "Python": pyClient, "TypeScript": tsClient})
+	symbols, err := s.GetWorkspaceSymbols(context.Background(), "Server", NewSymbolFilter(nil, nil))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Server", "ServerOptions"}, symbolNames(symbols))
+
+	// the query fails only if every server fails
+	s = newTestService(map[string]Client{"Python": pyClient})
+	_, err = s.GetWorkspaceSymbols(context.Background(), "Server", NewSymbolFilter(nil, nil))
+	assert.ErrorContains(t, err, "request failed")
+}
+
+func TestService_GetImplementations(t *testing.T) {
+	client := &fakeClient{implementations: []protocol.Location{
+		{URI: "file:///workspace/server/http.go", Range: protocol.Range{Start: protocol.Position{Line: 4, Character: 5}, End: protocol.Position{Line: 4, Character: 15}}},
+		{URI: "file:///go/pkg/mod/example.com/server.go"},
+	}}
+	s := newTestService(map[string]Client{"Go": client})
+
+	file := *model.NewFile("file:///workspace/server.go", "package server\n\ntype Server interface{}\n")
+	locations, err := s.GetImplementations(context.Background(), file, protocol.Position{Line: 2, Character: 5})
+	require.NoError(t, err)
+
+	assert.Equal(t, []Location{
+		{Path: "server/http.go", Range: Range{Start: Position{Line: 5, Character: 5}, End: Position{Line: 5, Character: 15}}},
+		{Path: "/go/pkg/mod/example.com/server.go", Range: Range{Start: Position{Line: 1}, End: Position{Line: 1}}},
+	}, locations)
+
+	// the document is opened with its content for the request and closed afterwards
+	assert.Equal(t, []string{
+		"didOpen file:///workspace/server.go",
+		"implementation file:///workspace/server.go",
+		"didClose file:///workspace/server.go",
+	}, client.requests)
+	require.Len(t, client.opened, 1)
+	assert.Equal(t, "package server\n\ntype Server interface{}\n", client.opened[0].Text)
+}
+
+func TestService_GetImplementations_OpenDocument(t *testing.T) {
+	client := &fakeClient{}
+	s := newTestService(map[string]Client{"Go": client})
+
+	file := *model.NewFile("file:///workspace/server.go", "package server\n")
+	require.NoError(t, s.NotifyDidOpen(context.Background(), file))
+
+	_, err := s.GetImplementations(context.Background(), file, protocol.Position{})
+	require.NoError(t, err)
+
+	// documents opened by the caller stay open
+	assert.Equal(t, []string{
+		"didOpen file:///workspace/server.go",
+		"implementation file:///workspace/server.go",
+	}, client.requests)
+}
+
+func diagnostic(message string) protocol.Diagnostic {
+	return protocol.Diagnostic{Message: message}
+}
+
+func TestService_PullDiagnostics(t *testing.T) {
+	first, second := "result-1", "result-2"
+	client := &fakeClient{
+		diagnosticProvider: &DiagnosticOptions{},
+		reports: []DocumentDiagnosticReport{
+			{Kind: DocumentDiagnosticReportKindFull, ResultID: &first, Items: []protocol.Diagnostic{diagnostic("unused variable")}},
+			{Kind: DocumentDiagnosticReportKindUnchanged, ResultID: &second},
+		},
+	}
+	s := newTestService(map[string]Client{"Go": client})
+	file := model.File{Path: "/workspace/main.go"}
+
+	diagnostics, err := s.PullDiagnostics(context.Background(), file)
+	require.NoError(t, err)
+	assert.Equal(t, []protocol.Diagnostic{diagnostic("unused variable")}, diagnostics)
+
+	// the unchanged report keeps the diagnostics of the previous result
+	diagnostics, err = s.PullDiagnostics(context.Background(), file)
+	require.NoError(t, err)
+	assert.Equal(t, []protocol.Diagnostic{diagnostic("unused variable")}, diagnostics)
+
+	require.Len(t, client.pulled, 2)
+	assert.Nil(t, client.pulled[0].PreviousResultID)
+	assert.Equal(t, &first, client.pulled[1].PreviousResultID)
+	assert.Equal(t, protocol.DocumentUri("file:///workspace/main.go"), client.pulled[1].TextDocument.URI)
+
+	stored, err := s.GetDiagnostics(context.Background(), file)
+	require.NoError(t, err)
+	assert.Equal(t, []protocol.Diagnostic{diagnostic("unused variable")}, stored)
+}
+
+func TestService_PullDiagnostics_FallbackToPublished(t *testing.T) {
+	s := newTestService(map[string]Client{"Go": &fakeClient{}})
+	file := model.File{Path: "/workspace/main.go"}
+
+	_, err := s.PullDiagnostics(context.Background(), file)
+	var notSupported *PullDiagnosticsNotSupportedError
+	require.ErrorAs(t, err, &notSupported)
+	assert.Equal(t, "Go", notSupported.LanguageID)
+
+	s.updateDiagnostics(protocol.PublishDiagnosticsParams{URI: "file:///workspace/main.go", Diagnostics: []protocol.Diagnostic{diagnostic("undefined: x")}})
+
+	diagnostics, err := s.GetDiagnostics(context.Background(), file)
+	require.NoError(t, err)
+	assert.Equal(t, []protocol.Diagnostic{diagnostic("undefined: x")}, diagnostics)
+}
+
+func TestService_PullWorkspaceDiagnostics(t *testing.T) {
+	goResult, pyResult := "go-1", "py-1"
+	goClient := &fakeClient{
+		diagnosticProvider: &DiagnosticOptions{WorkspaceDiagnostics: true},
+		workspaceReport: WorkspaceDiagnosticReport{Items: []WorkspaceDocumentDiagnosticReport{
+			{URI: "file:///workspace/main.go", DocumentDiagnosticReport: DocumentDiagnosticReport{Kind: DocumentDiagnosticReportKindFull, ResultID: &goResult, Items: []protocol.Diagnostic{diagnostic("unused import")}}},
+		}},
+	}
+	pyClient := &fakeClient{
+		diagnosticProvider: &DiagnosticOptions{WorkspaceDiagnostics: true},
+		workspaceReport: WorkspaceDiagnosticReport{Items: []WorkspaceDocumentDiagnosticReport{
+			{URI: "file:///workspace/main.py", DocumentDiagnosticReport: DocumentDiagnosticReport{Kind: DocumentDiagnosticReportKindFull, ResultID: &pyResult, Items: []protocol.Diagnostic{diagnostic("undefined name")}}},
+		}},
+	}
+	s := newTestService(map[string]Client{"Go": goClient, "Python": pyClient})
+
+	require.NoError(t, s.PullWorkspaceDiagnostics(context.Background()))
+	require.NoError(t, s.PullWorkspaceDiagnostics(context.Background()))
+
+	// every server gets back only the result ids it reported
+	require.Len(t, goClient.pulledWorkspace, 2)
+	assert.Empty(t, goClient.pulledWorkspace[0].PreviousResultIDs)
+	assert.Equal(t, []PreviousResultID{{URI: "file:///workspace/main.go", Value: goResult}}, goClient.pulledWorkspace[1].PreviousResultIDs)
+	require.Len(t, pyClient.pulledWorkspace, 2)
+	assert.Equal(t, []PreviousResultID{{URI: "file:///workspace/main.py", Value: pyResult}}, pyClient.pulledWorkspace[1].PreviousResultIDs)
+
+	diagnostics, err := s.GetWorkspaceDiagnostics(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, LspDiagnostics{
+		"file:///workspace/main.go": {diagnostic("unused import")},
+		"file:///workspace/main.py": {diagnostic("undefined name")},
+	}, diagnostics)
+}
+
+func TestService_PullWorkspaceDiagnostics_FailingServer(t *testing.T) {
+	goClient := &fakeClient{
+		diagnosticProvider: &DiagnosticOptions{WorkspaceDiagnostics: true},
+		workspaceErr:       errors.New("request failed"),
+	}
+	pyClient := &fakeClient{
+		diagnosticProvider: &DiagnosticOptions{WorkspaceDiagnostics: true},
+		workspaceReport: WorkspaceDiagnosticReport{Items: []WorkspaceDocumentDiagnosticReport{
+			{URI: "file:///workspace/main.py", DocumentDiagnosticReport: DocumentDiagnosticReport{Kind: DocumentDiagnosticReportKindFull, Items: []protocol.Diagnostic{diagnostic("undefined name")}}},
+		}},
+	}
+
+	// the failing Go server is pulled first, the Python server is still pulled
+	s := newTestService(map[string]Client{"Go": goClient, "Python": pyClient})
+	require.NoError(t, s.PullWorkspaceDiagnostics(context.Background()))
+	assert.Len(t, goClient.pulledWorkspace, 1)
+	assert.Len(t, pyClient.pulledWorkspace, 1)
+
+	diagnostics, err := s.GetWorkspaceDiagnostics(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, LspDiagnostics{"file:///workspace/main.py": {diagnostic("undefined name")}}, diagnostics)
+
+	// the pull fails only if every server fails
+	s = newTestService(map[string]Client{"Go": goClient})
+	assert.ErrorContains(t, s.PullWorkspaceDiagnostics(context.Background()), "request failed")
+}
@@ -0,0 +1,89 @@
+package lsp
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ServerSettings overrides the defaults provided by a language server adapter.
+type ServerSettings struct {
+	// InitializationOptions are merged into the adapter's initialization options
+	InitializationOptions json.RawMessage
+	// Settings are merged into the adapter's workspace configuration
+	Settings json.RawMessage
+}
+
+// mergeSettings merges override into base. Objects are merged recursively, any other value in override replaces the one in base.
+func mergeSettings(base, override json.RawMessage) (json.RawMessage, error) {
+	if isEmptySettings(override) {
+		return base, nil
+	}
+	if isEmptySettings(base) {
+		return override, nil
+	}
+
+	var b, o interface{}
+	if err := json.Unmarshal(base, &b); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(override, &o); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(mergeValues(b, o))
+}
+
+func mergeValues(base, override interface{}) interface{} {
+	b, ok := base.(map[string]interface{})
+	if !ok {
+		return override
+	}
+	o, ok := override.(map[string]interface{})
+	if !ok {
+		return override
+	}
+
+	for key, value := range o {
+		if existing, ok := b[key]; ok {
+			b[key] = mergeValues(existing, value)
+		} else {
+			b[key] = value
+		}
+	}
+
+	return b
+}
+
+// settingsSection returns the value of a dotted section, e.g. "python.analysis", or all settings if section is empty.
+// Returns nil if the section does not exist, as expected by workspace/configuration.
+func settingsSection(settings json.RawMessage, section string) (interface{}, error) {
+	if isEmptySettings(settings) {
+		return nil, nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(settings, &value); err != nil {
+		return nil, err
+	}
+
+	if section == "" {
+		return value, nil
+	}
+
+	for _, key := range strings.Split(section, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+
+		if value, ok = object[key]; !ok {
+			return nil, nil
+		}
+	}
+
+	return value, nil
+}
+
+func isEmptySettings(settings json.RawMessage) bool {
+	return len(settings) == 0 || string(settings) == "null"
+}
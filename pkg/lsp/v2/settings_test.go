@@ -0,0 +1,89 @@
+package lsp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		override string
+		expected string
+	}{
+		{
+			name:     "no override",
+			base:     `{"staticcheck":true}`,
+			override: ``,
+			expected: `{"staticcheck":true}`,
+		},
+		{
+			name:     "no base",
+			base:     `null`,
+			override: `{"staticcheck":false}`,
+			expected: `{"staticcheck":false}`,
+		},
+		{
+			name:     "nested objects are merged",
+			base:     `{"analyses":{"shadow":true,"useany":true},"memoryMode":"DegradeClosed"}`,
+			override: `{"analyses":{"shadow":false},"gofumpt":true}`,
+			expected: `{"analyses":{"shadow":false,"useany":true},"gofumpt":true,"memoryMode":"DegradeClosed"}`,
+		},
+		{
+			name:     "arrays are replaced",
+			base:     `{"templateExtensions":[".tmpl",".gotmpl"]}`,
+			override: `{"templateExtensions":[".html"]}`,
+			expected: `{"templateExtensions":[".html"]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := mergeSettings(json.RawMessage(tt.base), json.RawMessage(tt.override))
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(result))
+		})
+	}
+}
+
+func TestSettingsSection(t *testing.T) {
+	settings := json.RawMessage(`{"python":{"analysis":{"diagnosticMode":"openFilesOnly"}}}`)
+
+	tests := []struct {
+		name     string
+		section  string
+		expected interface{}
+	}{
+		{
+			name:     "all settings",
+			section:  "",
+			expected: map[string]interface{}{"python": map[string]interface{}{"analysis": map[string]interface{}{"diagnosticMode": "openFilesOnly"}}},
+		},
+		{
+			name:     "nested section",
+			section:  "python.analysis",
+			expected: map[string]interface{}{"diagnosticMode": "openFilesOnly"},
+		},
+		{
+			name:     "missing section",
+			section:  "python.linting",
+			expected: nil,
+		},
+		{
+			name:     "section below a value",
+			section:  "python.analysis.diagnosticMode.other",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := settingsSection(settings, tt.section)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package lsp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	lang "github.com/hide-org/hide/pkg/lsp/v2/languages"
+	"github.com/rs/zerolog/log"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+const (
+	pingInterval       = 30 * time.Second
+	pingTimeout        = 10 * time.Second
+	maxMissedPings     = 3
+	restartBackoff     = time.Second
+	maxRestartBackoff  = time.Minute
+	maxRestartAttempts = 5
+)
+
+type ServerStatus = string
+
+const (
+	ServerStatusRunning    ServerStatus = "running"
+	ServerStatusRestarting ServerStatus = "restarting"
+	ServerStatusFailed     ServerStatus = "failed"
+)
+
+type ServerHealth struct {
+	Server    lang.ServerName   `json:"server"`
+	Languages []lang.LanguageID `json:"languages"`
+	Status    ServerStatus      `json:"status"`
+	Restarts  int               `json:"restarts"`
+	LastError string            `json:"last_error,omitempty"`
+	StartedAt time.Time         `json:"started_at"`
+	// Ready is false while the server reports work in progress, e.g. indexing
+	Ready    bool       `json:"ready"`
+	Progress []Progress `json:"progress,omitempty"`
+}
+
+// supervisor watches a running language server and restarts it when it crashes or stops responding.
+type supervisor struct {
+	mu        sync.Mutex
+	server    lang.ServerName
+	languages []lang.LanguageID // first language is used to start the server
+	client    Client
+	status    ServerStatus
+	restarts  int
+	lastError error
+	startedAt time.Time
+	stopped   bool
+	stop      chan struct{}
+}
+
+func newSupervisor(server lang.ServerName, languageId lang.LanguageID, client Client) *supervisor {
+	return &supervisor{
+		server:    server,
+		languages: []lang.LanguageID{languageId},
+		client:    client,
+		status:    ServerStatusRunning,
+		startedAt: time.Now(),
+		stop:      make(chan struct{}),
+	}
+}
+
+func (sv *supervisor) getClient() Client {
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+
+	return sv.client
+}
+
+func (sv *supervisor) addLanguage(languageId lang.LanguageID) {
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+
+	if !slices.Contains(sv.languages, languageId) {
+		sv.languages = append(sv.languages, languageId)
+	}
+}
+
+// removeLanguage returns the number of languages still served.
+func (sv *supervisor) removeLanguage(languageId lang.LanguageID) int {
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+
+	sv.languages = slices.DeleteFunc(sv.languages, func(l lang.LanguageID) bool { return l == languageId })
+	return len(sv.languages)
+}
+
+func (sv *supervisor) getLanguages() []lang.LanguageID {
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+
+	return slices.Clone(sv.languages)
+}
+
+func (sv *supervisor) setStatus(status ServerStatus, err error) {
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+
+	sv.status = status
+	if err != nil {
+		sv.lastError = err
+	}
+}
+
+func (sv *supervisor) close() {
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+
+	if !sv.stopped {
+		sv.stopped = true
+		close(sv.stop)
+	}
+}
+
+func (sv *supervisor) health() ServerHealth {
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+
+	out := ServerHealth{
+		Server:    sv.server,
+		Languages: slices.Clone(sv.languages),
+		Status:    sv.status,
+		Restarts:  sv.restarts,
+		StartedAt: sv.startedAt,
+	}
+	if sv.status == ServerStatusRunning {
+		out.Ready = sv.client.Ready()
+		out.Progress = sv.client.Progress()
+	}
+	if sv.lastError != nil {
+		out.LastError = sv.lastError.Error()
+	}
+	return out
+}
+
+// supervise blocks until the supervisor is stopped or the server cannot be restarted.
+func (s *ServiceImpl) supervise(sv *supervisor) {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	missed := 0
+	for {
+		client := sv.getClient()
+
+		var reason error
+		select {
+		case <-sv.stop:
+			return
+		case <-client.Done():
+			reason = errors.New("language server exited")
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+			err := client.Ping(ctx)
+			cancel()
+
+			if err == nil {
+				missed = 0
+				continue
+			}
+
+			missed++
+			log.Warn().Err(err).Str("server", sv.server).Msgf("Language server did not respond to ping (%d/%d)", missed, maxMissedPings)
+			if missed < maxMissedPings {
+				continue
+			}
+
+			reason = fmt.Errorf("language server is unresponsive: %w", err)
+			if err := client.Kill(); err != nil {
+				log.Warn().Err(err).Str("server", sv.server).Msg("Failed to kill unresponsive language server")
+			}
+		}
+
+		missed = 0
+		if !s.restart(sv, reason) {
+			return
+		}
+	}
+}
+
+// restart relaunches the server with exponential backoff. Returns false if the server is stopped or failed for good.
+func (s *ServiceImpl) restart(sv *supervisor, reason error) bool {
+	log.Error().Err(reason).Str("server", sv.server).Msg("Restarting language server")
+	sv.setStatus(ServerStatusRestarting, reason)
+
+	backoff := restartBackoff
+	for attempt := 1; attempt <= maxRestartAttempts; attempt++ {
+		select {
+		case <-sv.stop:
+			return false
+		case <-time.After(backoff):
+		}
+
+		languages := sv.getLanguages()
+		if len(languages) == 0 {
+			return false
+		}
+
+		client, err := s.launch(context.Background(), languages[0])
+		if err != nil {
+			log.Error().Err(err).Str("server", sv.server).Msgf("Failed to restart language server (%d/%d)", attempt, maxRestartAttempts)
+			sv.setStatus(ServerStatusRestarting, err)
+			backoff = min(backoff*2, maxRestartBackoff)
+			continue
+		}
+
+		sv.mu.Lock()
+		if sv.stopped {
+			sv.mu.Unlock()
+			client.Kill()
+			return false
+		}
+
+		for _, languageId := range sv.languages {
+			s.clientPool.Set(languageId, client)
+		}
+		sv.client = client
+		sv.status = ServerStatusRunning
+		sv.restarts++
+		sv.startedAt = time.Now()
+		sv.mu.Unlock()
+
+		s.replayOpenDocuments(client, languages)
+
+		log.Info().Str("server", sv.server).Msg("Restarted language server")
+		return true
+	}
+
+	sv.setStatus(ServerStatusFailed, nil)
+	for _, languageId := range sv.getLanguages() {
+		s.clientPool.Delete(languageId)
+	}
+
+	log.Error().Str("server", sv.server).Msg("Giving up restarting language server")
+	return false
+}
+
+func (s *ServiceImpl) replayOpenDocuments(client Client, languages []lang.LanguageID) {
+	s.mu.Lock()
+	documents := make([]protocol.TextDocumentItem, 0, len(s.openDocuments))
+	for _, doc := range s.openDocuments {
+		if slices.Contains(languages, doc.languageId) {
+			documents = append(documents, doc.item)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, item := range documents {
+		if err := client.NotifyDidOpen(context.Background(), protocol.DidOpenTextDocumentParams{TextDocument: item}); err != nil {
+			log.Warn().Err(err).Str("uri", item.URI).Msg("Failed to reopen document after restart")
+		}
+	}
+}
+
+func (s *ServiceImpl) Health(ctx context.Context) []ServerHealth {
+	s.mu.Lock()
+	supervisors := make([]*supervisor, 0, len(s.supervisors))
+	for _, sv := range s.supervisors {
+		supervisors = append(supervisors, sv)
+	}
+	s.mu.Unlock()
+
+	out := make([]ServerHealth, 0, len(supervisors))
+	for _, sv := range supervisors {
+		out = append(out, sv.health())
+	}
+
+	slices.SortFunc(out, func(a, b ServerHealth) int {
+		return strings.Compare(a.Server, b.Server)
+	})
+
+	return out
+}
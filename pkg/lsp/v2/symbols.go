@@ -11,6 +11,10 @@ type SymbolFilter struct {
 	exclude []protocol.SymbolKind
 }
 
+func NewSymbolFilter(include, exclude []protocol.SymbolKind) SymbolFilter {
+	return SymbolFilter{include: include, exclude: exclude}
+}
+
 func NewIncludeSymbolFilter(include ...protocol.SymbolKind) SymbolFilter {
 	return SymbolFilter{include: include}
 }
@@ -18,6 +22,12 @@ func NewIncludeSymbolFilter(include ...protocol.SymbolKind) SymbolFilter {
 func NewExcludeSymbolFilter(exclude ...protocol.SymbolKind) SymbolFilter {
 	return SymbolFilter{exclude: exclude}
 }
+
+// Keep reports whether symbols of the kind pass the filter.
+func (f *SymbolFilter) Keep(kind protocol.SymbolKind) bool {
+	return !slices.Contains(f.exclude, kind) && (len(f.include) == 0 || slices.Contains(f.include, kind))
+}
+
 func (f *SymbolFilter) shouldExcludeSymbol(symbol protocol.SymbolInformation) bool {
 	return slices.Contains(f.exclude, symbol.Kind)
 }
@@ -2,8 +2,11 @@ package lsp
 
 import (
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/go-enry/go-enry/v2"
+	"github.com/gobwas/glob"
 	lang "github.com/hide-org/hide/pkg/lsp/v2/languages"
 	"github.com/hide-org/hide/pkg/model"
 	"github.com/rs/zerolog/log"
@@ -13,23 +16,68 @@ type LanguageDetector interface {
 	DetectLanguage(file *model.File) string
 	DetectLanguages(files []*model.File) map[string]int
 	DetectMainLanguage(files []*model.File) string
+	// DetectMainLanguages returns languages that make up at least threshold (0..1) of the source code, largest first.
+	DetectMainLanguages(files []*model.File, threshold float64) []lang.LanguageID
 }
 
 // LanguageDetectorImpl implements LanguageDetector using https://github.com/go-enry/go-enry
-type LanguageDetectorImpl struct{}
+type LanguageDetectorImpl struct {
+	patterns []filePattern
+}
+
+// filePattern assigns matching files to a language, e.g. of a configured language server.
+type filePattern struct {
+	glob     glob.Glob
+	language lang.LanguageID
+}
+
+type LanguageDetectorOption func(ld *LanguageDetectorImpl)
+
+// LanguageDetectorWithFilePatterns assigns files matching the patterns to the language. Patterns without a slash match
+// the file name, e.g. "*.nix", others match the path relative to the workspace, e.g. "templates/**/*.html".
+func LanguageDetectorWithFilePatterns(language lang.LanguageID, patterns ...string) LanguageDetectorOption {
+	return func(ld *LanguageDetectorImpl) {
+		for _, pattern := range patterns {
+			g, err := glob.Compile(pattern, '/')
+			if err != nil {
+				log.Warn().Err(err).Str("pattern", pattern).Msgf("Invalid file pattern of language %s", language)
+				continue
+			}
+			ld.patterns = append(ld.patterns, filePattern{glob: g, language: language})
+		}
+	}
+}
 
-func NewLanguageDetector() LanguageDetector {
-	return &LanguageDetectorImpl{}
+func NewLanguageDetector(opts ...LanguageDetectorOption) LanguageDetector {
+	ld := &LanguageDetectorImpl{}
+	for _, opt := range opts {
+		opt(ld)
+	}
+	return ld
 }
 
 func (ld LanguageDetectorImpl) DetectLanguage(file *model.File) lang.LanguageID {
+	if language, ok := ld.matchPattern(file.Path); ok {
+		return language
+	}
 	return enry.GetLanguage(filepath.Base(file.Path), file.GetContentBytes())
 }
 
+func (ld LanguageDetectorImpl) matchPattern(path string) (lang.LanguageID, bool) {
+	path = strings.TrimPrefix(filepath.ToSlash(path), "/")
+	for _, p := range ld.patterns {
+		if p.glob.Match(path) || p.glob.Match(filepath.Base(path)) {
+			return p.language, true
+		}
+	}
+	return "", false
+}
+
 func (ld LanguageDetectorImpl) DetectLanguages(files []*model.File) map[string]int {
 	languages := make(map[string]int)
 	for _, file := range files {
-		if skipFile(file.Path, file.GetContentBytes()) {
+		// files of configured languages are counted, even if they look like e.g. configuration
+		if _, ok := ld.matchPattern(file.Path); !ok && skipFile(file.Path, file.GetContentBytes()) {
 			continue
 		}
 		language := ld.DetectLanguage(file)
@@ -52,6 +100,38 @@ func (ld LanguageDetectorImpl) DetectMainLanguage(files []*model.File) string {
 	return maxLanguage
 }
 
+func (ld LanguageDetectorImpl) DetectMainLanguages(files []*model.File, threshold float64) []lang.LanguageID {
+	languages := ld.DetectLanguages(files)
+	log.Debug().Any("languages", languages).Msg("Detected languages")
+
+	total := 0
+	for language, count := range languages {
+		if language == "" {
+			continue
+		}
+		total += count
+	}
+
+	out := make([]lang.LanguageID, 0, len(languages))
+	for language, count := range languages {
+		if language == "" || total == 0 {
+			continue
+		}
+		if float64(count)/float64(total) >= threshold {
+			out = append(out, language)
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if languages[out[i]] != languages[out[j]] {
+			return languages[out[i]] > languages[out[j]]
+		}
+		return out[i] < out[j]
+	})
+
+	return out
+}
+
 func skipFile(filename string, content []byte) bool {
 	return enry.IsBinary(content) ||
 		enry.IsVendor(filename) ||
@@ -0,0 +1,64 @@
+package lsp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLanguageDetector_FilePatterns(t *testing.T) {
+	ld := NewLanguageDetector(
+		LanguageDetectorWithFilePatterns("Nix", "*.nix"),
+		LanguageDetectorWithFilePatterns("Jinja", "templates/**/*.html"),
+	)
+
+	assert.Equal(t, "Nix", ld.DetectLanguage(model.NewFile("nix/shell.nix", "{ pkgs }: pkgs.mkShell {}")))
+	assert.Equal(t, "Jinja", ld.DetectLanguage(model.NewFile("templates/pages/index.html", "{{ title }}")))
+	assert.Equal(t, "HTML", ld.DetectLanguage(model.NewFile("static/index.html", "<html></html>")))
+
+	// files of configured languages count even if they look like configuration
+	assert.Equal(t, []string{"Nix", "Go"}, ld.DetectMainLanguages([]*model.File{
+		model.NewFile(".config.nix", "{ pkgs }: pkgs.mkShell { buildInputs = [ pkgs.go ]; }"),
+		model.NewFile("main.go", "package main"),
+	}, 0.1))
+}
+
+func TestLanguageDetector_DetectMainLanguages(t *testing.T) {
+	files := []*model.File{
+		model.NewFile("main.go", strings.Repeat("a", 600)),
+		model.NewFile("server.go", strings.Repeat("a", 100)),
+		model.NewFile("scripts/build.py", strings.Repeat("a", 200)),
+		model.NewFile("web/app.ts", strings.Repeat("a", 100)),
+		model.NewFile("README.md", strings.Repeat("a", 5000)),
+	}
+
+	tests := []struct {
+		name      string
+		threshold float64
+		want      []string
+	}{
+		{name: "all languages", threshold: 0, want: []string{"Go", "Python", "TypeScript"}},
+		{name: "threshold is inclusive", threshold: 0.2, want: []string{"Go", "Python"}},
+		{name: "main language", threshold: 0.5, want: []string{"Go"}},
+		{name: "none above threshold", threshold: 0.8, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewLanguageDetector().DetectMainLanguages(files, tt.threshold))
+		})
+	}
+}
+
+func TestLanguageDetector_DetectMainLanguages_Ties(t *testing.T) {
+	files := []*model.File{
+		model.NewFile("web/app.ts", strings.Repeat("a", 100)),
+		model.NewFile("main.go", strings.Repeat("a", 100)),
+		model.NewFile("build.py", strings.Repeat("a", 100)),
+	}
+
+	// languages of the same size are ordered by name
+	assert.Equal(t, []string{"Go", "Python", "TypeScript"}, NewLanguageDetector().DetectMainLanguages(files, 0.1))
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/hide-org/hide/pkg/devcontainer"
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
 )
 
 type Config struct {
@@ -24,6 +24,8 @@ type Project struct {
 	Config      Config    `json:"config"`
 	Repository  Repository `json:"repository"`
 	ContainerId string
+	// Port is the host port the hide server of the project is published on
+	Port int `json:"port,omitempty"`
 }
 
 func NewProject(id ProjectId, path string, config Config, containerId string, repository Repository) Project {
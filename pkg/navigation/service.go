@@ -0,0 +1,82 @@
+package navigation
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/lsp/v2"
+	"github.com/hide-org/hide/pkg/model"
+)
+
+type Service interface {
+	// CallHierarchy returns callers or callees of the symbol at position, followed up to depth levels.
+	CallHierarchy(ctx context.Context, path string, position lsp.Position, direction lsp.CallDirection, depth int) ([]lsp.CallHierarchyItem, error)
+	// Implementations returns locations of implementations of the symbol at position.
+	Implementations(ctx context.Context, path string, position lsp.Position) ([]lsp.Location, error)
+}
+
+type ServiceImpl struct {
+	workspaceDir string
+	files        files.Service
+	lsp          lsp.Service
+}
+
+func NewService(files files.Service, lsp lsp.Service, workspaceDir string) Service {
+	return &ServiceImpl{files: files, lsp: lsp, workspaceDir: workspaceDir}
+}
+
+func (s *ServiceImpl) CallHierarchy(ctx context.Context, path string, position lsp.Position, direction lsp.CallDirection, depth int) ([]lsp.CallHierarchyItem, error) {
+	log.Debug().Str("path", path).Str("direction", direction).Int("depth", depth).Msg("Getting call hierarchy")
+
+	file, err := s.readFile(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.lsp.GetCallHierarchy(ctx, file, toProtocolPosition(position), direction, depth)
+	if err != nil {
+		log.Error().Err(err).Str("path", path).Msg("failed to get call hierarchy")
+		return nil, fmt.Errorf("failed to get call hierarchy: %w", err)
+	}
+
+	return items, nil
+}
+
+func (s *ServiceImpl) Implementations(ctx context.Context, path string, position lsp.Position) ([]lsp.Location, error) {
+	log.Debug().Str("path", path).Msg("Getting implementations")
+
+	file, err := s.readFile(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	locations, err := s.lsp.GetImplementations(ctx, file, toProtocolPosition(position))
+	if err != nil {
+		log.Error().Err(err).Str("path", path).Msg("failed to get implementations")
+		return nil, fmt.Errorf("failed to get implementations: %w", err)
+	}
+
+	return locations, nil
+}
+
+// readFile reads the file content, language servers need it to open the document.
+func (s *ServiceImpl) readFile(ctx context.Context, path string) (model.File, error) {
+	file, err := s.files.ReadFile(ctx, path)
+	if err != nil {
+		return model.File{}, err
+	}
+
+	return *file.WithPath("file://" + filepath.Join(s.workspaceDir, path)), nil
+}
+
+func toProtocolPosition(position lsp.Position) protocol.Position {
+	return protocol.Position{
+		Line:      protocol.UInteger(position.Line - 1), // Hide uses 1-based lines
+		Character: protocol.UInteger(position.Character),
+	}
+}
@@ -0,0 +1,87 @@
+package navigation_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/lsp/v2"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/hide-org/hide/pkg/navigation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+type fakeFiles struct {
+	files.Service
+	files map[string]string
+}
+
+func (f fakeFiles) ReadFile(ctx context.Context, path string) (*model.File, error) {
+	content, ok := f.files[path]
+	if !ok {
+		return nil, files.NewFileNotFoundError(path)
+	}
+	return model.NewFile(path, content), nil
+}
+
+type fakeLsp struct {
+	lsp.Service
+	file      model.File
+	position  protocol.Position
+	direction lsp.CallDirection
+	depth     int
+}
+
+func (f *fakeLsp) GetCallHierarchy(ctx context.Context, file model.File, position protocol.Position, direction lsp.CallDirection, depth int) ([]lsp.CallHierarchyItem, error) {
+	f.file, f.position, f.direction, f.depth = file, position, direction, depth
+	return []lsp.CallHierarchyItem{{Name: "Start", Kind: "Method"}}, nil
+}
+
+func (f *fakeLsp) GetImplementations(ctx context.Context, file model.File, position protocol.Position) ([]lsp.Location, error) {
+	f.file, f.position = file, position
+	return []lsp.Location{{Path: "server/http.go"}}, nil
+}
+
+func newTestService(fake *fakeLsp) navigation.Service {
+	return navigation.NewService(fakeFiles{files: map[string]string{"server.go": "package server\n\ntype Server interface{}\n"}}, fake, "/workspace")
+}
+
+func TestService_CallHierarchy(t *testing.T) {
+	fake := &fakeLsp{}
+
+	items, err := newTestService(fake).CallHierarchy(context.Background(), "server.go", lsp.Position{Line: 3, Character: 5}, lsp.CallDirectionIncoming, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []lsp.CallHierarchyItem{{Name: "Start", Kind: "Method"}}, items)
+
+	// the language server gets the document with its content and a 0-based line
+	assert.Equal(t, "file:///workspace/server.go", fake.file.Path)
+	assert.Equal(t, "package server\n\ntype Server interface{}\n", fake.file.GetContent())
+	assert.Equal(t, protocol.Position{Line: 2, Character: 5}, fake.position)
+	assert.Equal(t, lsp.CallDirectionIncoming, fake.direction)
+	assert.Equal(t, 2, fake.depth)
+}
+
+func TestService_Implementations(t *testing.T) {
+	fake := &fakeLsp{}
+
+	locations, err := newTestService(fake).Implementations(context.Background(), "server.go", lsp.Position{Line: 3, Character: 5})
+	require.NoError(t, err)
+	assert.Equal(t, []lsp.Location{{Path: "server/http.go"}}, locations)
+
+	assert.Equal(t, "file:///workspace/server.go", fake.file.Path)
+	assert.Equal(t, "package server\n\ntype Server interface{}\n", fake.file.GetContent())
+	assert.Equal(t, protocol.Position{Line: 2, Character: 5}, fake.position)
+}
+
+func TestService_FileNotFound(t *testing.T) {
+	service := newTestService(&fakeLsp{})
+	var notFound *files.FileNotFoundError
+
+	_, err := service.CallHierarchy(context.Background(), "missing.go", lsp.Position{Line: 1}, lsp.CallDirectionOutgoing, 1)
+	assert.ErrorAs(t, err, &notFound)
+
+	_, err = service.Implementations(context.Background(), "missing.go", lsp.Position{Line: 1})
+	assert.ErrorAs(t, err, &notFound)
+}
@@ -0,0 +1,30 @@
+package outline
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hide-org/hide/pkg/lsp/v2"
+)
+
+// Format renders outlines as compact text, one symbol per line with its kind and lines, e.g.
+//
+//	pkg/server.go
+//	  Struct Server 10-14
+//	    Method Start 16-30
+func Format(outlines []lsp.DocumentOutline) string {
+	var sb strings.Builder
+	for _, outline := range outlines {
+		sb.WriteString(outline.Path)
+		sb.WriteString("\n")
+		formatSymbols(&sb, outline.DocumentSymbols, "  ")
+	}
+	return sb.String()
+}
+
+func formatSymbols(sb *strings.Builder, symbols []lsp.DocumentSymbol, indent string) {
+	for _, symbol := range symbols {
+		fmt.Fprintf(sb, "%s%s %s %d-%d\n", indent, symbol.Kind, symbol.Name, symbol.Range.Start.Line, symbol.Range.End.Line)
+		formatSymbols(sb, symbol.Children, indent+"  ")
+	}
+}
@@ -0,0 +1,50 @@
+package outline
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/hide-org/hide/pkg/lsp/v2"
+)
+
+type ListOptions struct {
+	// Depth limits the nesting of symbols, 1 keeps top-level symbols only and 0 means no limit
+	Depth int
+	// Kinds of symbols to keep, e.g. Class or Function. Matching symbols nested in other kinds are kept at their level.
+	Kinds []string
+}
+
+type ListOption func(opts *ListOptions)
+
+func ListWithDepth(depth int) ListOption {
+	return func(opts *ListOptions) {
+		opts.Depth = depth
+	}
+}
+
+func ListWithKinds(kinds ...string) ListOption {
+	return func(opts *ListOptions) {
+		opts.Kinds = append(opts.Kinds, kinds...)
+	}
+}
+
+// filter returns symbols of the given kinds up to the given depth.
+func (o *ListOptions) filter(symbols []lsp.DocumentSymbol, depth int) []lsp.DocumentSymbol {
+	if o.Depth > 0 && depth > o.Depth {
+		return nil
+	}
+
+	out := make([]lsp.DocumentSymbol, 0, len(symbols))
+	for _, symbol := range symbols {
+		if len(o.Kinds) > 0 && !slices.ContainsFunc(o.Kinds, func(kind string) bool { return strings.EqualFold(kind, symbol.Kind) }) {
+			// lift matching children, e.g. methods of a class when filtering by Method
+			out = append(out, o.filter(symbol.Children, depth)...)
+			continue
+		}
+
+		symbol.Children = o.filter(symbol.Children, depth+1)
+		out = append(out, symbol)
+	}
+
+	return out
+}
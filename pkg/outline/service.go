@@ -2,26 +2,135 @@ package outline
 
 import (
 	"context"
+	"errors"
 	"path/filepath"
+	"strings"
 
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/hide-org/hide/pkg/files"
 	"github.com/hide-org/hide/pkg/lsp/v2"
 	"github.com/hide-org/hide/pkg/model"
+	"github.com/hide-org/hide/pkg/parser"
 )
 
+// how many files are outlined at once
+const maxConcurrentOutlines = 8
+
 type Service interface {
 	// TODO: can we move model here?
 	Get(ctx context.Context, path string) (lsp.DocumentOutline, error)
+	// List returns outlines of files matching the pattern, which is a file, a directory or a glob such as
+	// "pkg/**/*.go". Files without symbols are left out.
+	List(ctx context.Context, pattern string, opts ...ListOption) ([]lsp.DocumentOutline, error)
 }
 
 type ServiceImpl struct {
 	workspaceDir string
 	lsp          lsp.Service
+	files        files.Service
 }
 
-func NewService(lsp lsp.Service, workspaceDir string) Service {
-	return &ServiceImpl{lsp: lsp, workspaceDir: workspaceDir}
+func NewService(lsp lsp.Service, files files.Service, workspaceDir string) Service {
+	return &ServiceImpl{lsp: lsp, files: files, workspaceDir: workspaceDir}
 }
 
 func (s *ServiceImpl) Get(ctx context.Context, path string) (lsp.DocumentOutline, error) {
-	return s.lsp.GetDocumentOutline(ctx, model.File{Path: "file://" + filepath.Join(s.workspaceDir, path)})
+	file, err := s.files.ReadFile(ctx, path)
+	if err != nil {
+		return lsp.DocumentOutline{}, err
+	}
+
+	return s.outline(ctx, file)
+}
+
+func (s *ServiceImpl) List(ctx context.Context, pattern string, opts ...ListOption) ([]lsp.DocumentOutline, error) {
+	opt := &ListOptions{}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	workspaceFiles, err := s.files.ListFiles(ctx, files.ListFilesWithContent(), files.ListFilesWithFilter(files.PatternFilter{Include: includePatterns(pattern)}))
+	if err != nil {
+		return nil, err
+	}
+
+	outlines := make([]lsp.DocumentOutline, len(workspaceFiles))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentOutlines)
+	for i, file := range workspaceFiles {
+		i, file := i, file // capture loop variables
+		g.Go(func() error {
+			outline, err := s.outline(gctx, file)
+			if err != nil {
+				var notFound *lsp.LanguageServerNotFoundError
+				if errors.As(err, &notFound) {
+					// neither a language server nor a parser for the file
+					return nil
+				}
+				return err
+			}
+
+			outline.DocumentSymbols = opt.filter(outline.DocumentSymbols, 1)
+			outlines[i] = outline
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	result := make([]lsp.DocumentOutline, 0, len(outlines))
+	for _, outline := range outlines {
+		if len(outline.DocumentSymbols) > 0 {
+			result = append(result, outline)
+		}
+	}
+
+	return result, nil
+}
+
+// outline returns the outline of the file from the language server. If there is no language server for the file,
+// or it returns nothing, e.g. while indexing, the outline is parsed instead.
+func (s *ServiceImpl) outline(ctx context.Context, file *model.File) (lsp.DocumentOutline, error) {
+	outline, err := s.lsp.GetDocumentOutline(ctx, *file.WithPath("file://" + filepath.Join(s.workspaceDir, file.Path)))
+
+	var notFound *lsp.LanguageServerNotFoundError
+	switch {
+	case errors.As(err, &notFound):
+	case err != nil:
+		return lsp.DocumentOutline{}, err
+	case len(outline.DocumentSymbols) > 0:
+		outline.Path = file.Path
+		return outline, nil
+	}
+
+	parsed, perr := parser.Outline(file)
+	if perr != nil {
+		log.Debug().Err(perr).Str("path", file.Path).Msg("Failed to parse outline")
+		// the language server error tells more
+		outline.Path = file.Path
+		return outline, err
+	}
+
+	log.Debug().Str("path", file.Path).Msg("Outline parsed without language server")
+	return parsed, nil
+}
+
+// includePatterns matches the file or all files in the directory at path, or files matching the glob.
+func includePatterns(pattern string) []string {
+	pattern = strings.Trim(filepath.ToSlash(pattern), "/")
+	if pattern == "" || pattern == "." {
+		return nil
+	}
+
+	// listed paths start at the workspace root
+	pattern = "/" + pattern
+	if strings.ContainsAny(pattern, "*?[{") {
+		return []string{pattern}
+	}
+
+	return []string{pattern, pattern + "/*"}
 }
@@ -0,0 +1,87 @@
+package outline
+
+import (
+	"testing"
+
+	"github.com/hide-org/hide/pkg/lsp/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func symbol(kind, name string, line int, children ...lsp.DocumentSymbol) lsp.DocumentSymbol {
+	return lsp.DocumentSymbol{Name: name, Kind: kind, Range: lsp.Range{Start: lsp.Position{Line: line}, End: lsp.Position{Line: line + 1}}, Children: children}
+}
+
+func TestListOptionsFilter(t *testing.T) {
+	symbols := []lsp.DocumentSymbol{
+		symbol("Class", "Server", 1,
+			symbol("Method", "Start", 2, symbol("Variable", "err", 3)),
+			symbol("Field", "addr", 4),
+		),
+		symbol("Function", "main", 5),
+	}
+
+	tests := []struct {
+		name string
+		opts []ListOption
+		want []lsp.DocumentSymbol
+	}{
+		{
+			name: "no limit",
+			want: symbols,
+		},
+		{
+			name: "depth",
+			opts: []ListOption{ListWithDepth(1)},
+			want: []lsp.DocumentSymbol{symbol("Class", "Server", 1), symbol("Function", "main", 5)},
+		},
+		{
+			name: "kinds lift nested symbols",
+			opts: []ListOption{ListWithKinds("method", "Function"), ListWithDepth(1)},
+			want: []lsp.DocumentSymbol{symbol("Method", "Start", 2), symbol("Function", "main", 5)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt := &ListOptions{}
+			for _, o := range tt.opts {
+				o(opt)
+			}
+
+			got := opt.filter(symbols, 1)
+			assert.Equal(t, format(tt.want), format(got))
+		})
+	}
+}
+
+func format(symbols []lsp.DocumentSymbol) string {
+	return Format([]lsp.DocumentOutline{{Path: "main.go", DocumentSymbols: symbols}})
+}
+
+func TestFormat(t *testing.T) {
+	outlines := []lsp.DocumentOutline{
+		{Path: "cmd/main.go", DocumentSymbols: []lsp.DocumentSymbol{symbol("Function", "main", 3)}},
+		{Path: "pkg/server.go", DocumentSymbols: []lsp.DocumentSymbol{symbol("Struct", "Server", 10, symbol("Field", "addr", 11))}},
+	}
+
+	assert.Equal(t, "cmd/main.go\n  Function main 3-4\npkg/server.go\n  Struct Server 10-11\n    Field addr 11-12\n", Format(outlines))
+}
+
+func TestIncludePatterns(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{pattern: "", want: nil},
+		{pattern: ".", want: nil},
+		{pattern: "pkg/lsp/", want: []string{"/pkg/lsp", "/pkg/lsp/*"}},
+		{pattern: "main.go", want: []string{"/main.go", "/main.go/*"}},
+		{pattern: "pkg/**/*.go", want: []string{"/pkg/**/*.go"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			assert.Equal(t, tt.want, includePatterns(tt.pattern))
+		})
+	}
+}
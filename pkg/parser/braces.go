@@ -0,0 +1,272 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// how many lines a declaration may take before its body starts, e.g. parameters on separate lines
+const maxSignatureLines = 8
+
+// rule matches a declaration at the start of a line. The name is the submatch named "name".
+type rule struct {
+	pattern *regexp.Regexp
+	kind    protocol.SymbolKind
+	// memberKind replaces kind inside of a container, e.g. functions of a Rust impl are methods
+	memberKind protocol.SymbolKind
+	// member rules only match directly in a container, e.g. methods of a class
+	member bool
+	// declarations of container bodies are outlined as children
+	container bool
+	// body is false for declarations without a body in braces, e.g. type aliases
+	body bool
+}
+
+// braceParser finds declarations of languages with C-like syntax. Bodies are matched by braces, so only
+// declarations of the file and of containers such as classes are outlined, never the ones inside of functions.
+type braceParser struct {
+	rules []rule
+	// single quotes delimit characters rather than strings, e.g. in Java and Rust
+	charQuotes bool
+	// constructor reports whether a method is a constructor of the container
+	constructor func(name, container string) bool
+}
+
+func (p braceParser) Parse(content []byte) ([]protocol.DocumentSymbol, error) {
+	type scope struct {
+		node      *node // nil for blocks without a declaration
+		container bool
+	}
+
+	type declaration struct {
+		node      *node
+		container bool
+		line, col int // braces and parentheses count from the end of the name
+		parens    int
+	}
+
+	root := &node{}
+	var stack []scope // one scope per open brace
+	var pending *declaration
+
+	lines := strings.Split(string(content), "\n")
+	cleaner := lineCleaner{charQuotes: p.charQuotes}
+
+	endAtLine := func(d *declaration) {
+		line := strings.TrimRight(lines[d.line], " \t\r")
+		d.node.symbol.Range.End = position(d.line, len(line))
+	}
+
+	for i, raw := range lines {
+		line := cleaner.clean(raw)
+
+		parent, container := root, true
+		if len(stack) > 0 {
+			top := stack[len(stack)-1]
+			container = top.container
+			if top.node != nil {
+				parent = top.node
+			}
+		}
+
+		if container {
+			if n, r, end := p.match(line, i, parent, len(stack) > 0); n != nil {
+				if pending != nil {
+					// the previous declaration has no body
+					endAtLine(pending)
+				}
+
+				parent.add(n)
+				pending = &declaration{node: n, container: r.container, line: i, col: end}
+				if !r.body {
+					endAtLine(pending)
+					pending = nil
+				}
+			}
+		}
+
+		for j := 0; j < len(line); j++ {
+			after := pending != nil && (i > pending.line || j >= pending.col)
+			switch line[j] {
+			case '(':
+				if after {
+					pending.parens++
+				}
+			case ')':
+				if after {
+					pending.parens--
+				}
+			case '{':
+				if after && pending.parens <= 0 {
+					stack = append(stack, scope{node: pending.node, container: pending.container})
+					pending = nil
+				} else {
+					stack = append(stack, scope{})
+				}
+			case '}':
+				if len(stack) == 0 {
+					continue
+				}
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				if top.node != nil {
+					top.node.symbol.Range.End = position(i, j+1)
+				}
+			case ';':
+				if after && pending.parens <= 0 {
+					pending.node.symbol.Range.End = position(i, j+1)
+					pending = nil
+				}
+			}
+		}
+
+		if pending != nil && i-pending.line >= maxSignatureLines {
+			endAtLine(pending)
+			pending = nil
+		}
+	}
+
+	// unbalanced braces, e.g. while the file is being edited
+	if pending != nil {
+		endAtLine(pending)
+	}
+	last := len(lines) - 1
+	for _, s := range stack {
+		if s.node != nil {
+			s.node.symbol.Range.End = position(last, len(lines[last]))
+		}
+	}
+
+	return root.symbols(), nil
+}
+
+// match returns the declaration starting at the line and the index its signature starts at.
+func (p braceParser) match(line string, lineNumber int, parent *node, inContainer bool) (*node, rule, int) {
+	for _, r := range p.rules {
+		if r.member && !inContainer {
+			continue
+		}
+
+		m := r.pattern.FindStringSubmatchIndex(line)
+		if m == nil {
+			continue
+		}
+
+		i := r.pattern.SubexpIndex("name")
+		nameStart, nameEnd := m[2*i], m[2*i+1]
+		// declarations such as Rust impl blocks may have a where clause
+		if idx := strings.Index(line[nameStart:nameEnd], " where "); idx >= 0 {
+			nameEnd = nameStart + idx
+		}
+		nameEnd = nameStart + len(strings.TrimRight(line[nameStart:nameEnd], " \t"))
+
+		kind := r.kind
+		if inContainer && r.memberKind != 0 {
+			kind = r.memberKind
+		}
+
+		symbol := lineSymbol(line, lineNumber, nameStart, nameEnd, kind)
+		if kind == protocol.SymbolKindMethod && p.constructor != nil && p.constructor(symbol.Name, parent.symbol.Name) {
+			symbol.Kind = protocol.SymbolKindConstructor
+		}
+
+		return &node{symbol: symbol}, r, nameEnd
+	}
+
+	return nil, rule{}, 0
+}
+
+// lineCleaner blanks comments and string literals so that braces in them are not counted. Columns are kept.
+type lineCleaner struct {
+	charQuotes bool
+	// state carried over to the next line
+	inComment  bool
+	inTemplate bool // JavaScript template literals may span lines
+}
+
+func (c *lineCleaner) clean(line string) string {
+	out := []byte(line)
+	blank := func(from, to int) {
+		for k := from; k < to && k < len(out); k++ {
+			if out[k] != '\t' {
+				out[k] = ' '
+			}
+		}
+	}
+
+	for i := 0; i < len(line); {
+		switch {
+		case c.inComment:
+			end := strings.Index(line[i:], "*/")
+			if end < 0 {
+				blank(i, len(line))
+				return string(out)
+			}
+			blank(i, i+end+2)
+			i += end + 2
+			c.inComment = false
+
+		case c.inTemplate:
+			end := closingQuote(line, i, '`')
+			if end < 0 {
+				blank(i, len(line))
+				return string(out)
+			}
+			blank(i, end+1)
+			i = end + 1
+			c.inTemplate = false
+
+		case strings.HasPrefix(line[i:], "//"):
+			blank(i, len(line))
+			return string(out)
+
+		case strings.HasPrefix(line[i:], "/*"):
+			c.inComment = true
+			blank(i, i+2)
+			i += 2
+
+		case line[i] == '`' && !c.charQuotes:
+			c.inTemplate = true
+			blank(i, i+1)
+			i++
+
+		case line[i] == '"' || (line[i] == '\'' && !c.charQuotes):
+			end := closingQuote(line, i+1, line[i])
+			if end < 0 {
+				end = len(line) - 1
+			}
+			blank(i+1, end)
+			i = end + 1
+
+		case line[i] == '\'':
+			// a character literal such as 'x' or '\n', anything else is e.g. a Rust lifetime
+			end := closingQuote(line, i+1, '\'')
+			if end >= 0 && end-i <= 3 {
+				blank(i+1, end)
+				i = end + 1
+			} else {
+				i++
+			}
+
+		default:
+			i++
+		}
+	}
+
+	return string(out)
+}
+
+// closingQuote returns the index of the unescaped quote at or after from, or -1.
+func closingQuote(line string, from int, quote byte) int {
+	for i := from; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case quote:
+			return i
+		}
+	}
+	return -1
+}
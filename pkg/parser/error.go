@@ -0,0 +1,15 @@
+package parser
+
+import "fmt"
+
+type UnsupportedFileError struct {
+	Path string
+}
+
+func (e UnsupportedFileError) Error() string {
+	return fmt.Sprintf("no parser for file %s", e.Path)
+}
+
+func NewUnsupportedFileError(path string) *UnsupportedFileError {
+	return &UnsupportedFileError{Path: path}
+}
@@ -0,0 +1,123 @@
+package parser
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// goParser uses the parser of the standard library.
+type goParser struct{}
+
+func (p goParser) Parse(content []byte) ([]protocol.DocumentSymbol, error) {
+	fset := token.NewFileSet()
+	// the file is still usable if it has syntax errors, e.g. while being edited
+	file, err := parser.ParseFile(fset, "", content, parser.SkipObjectResolution)
+	if file == nil {
+		return nil, err
+	}
+
+	symbols := []protocol.DocumentSymbol{}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			symbol := goSymbol(fset, d.Name, d, protocol.SymbolKindFunction)
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				symbol.Kind = protocol.SymbolKindMethod
+				symbol.Detail = stringPointer(goExpr(fset, d.Recv.List[0].Type))
+			}
+			symbols = append(symbols, symbol)
+
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					symbols = append(symbols, goTypeSymbol(fset, s, d))
+				case *ast.ValueSpec:
+					kind := protocol.SymbolKindVariable
+					if d.Tok == token.CONST {
+						kind = protocol.SymbolKindConstant
+					}
+					for _, name := range s.Names {
+						if name.Name == "_" {
+							continue
+						}
+						symbols = append(symbols, goSymbol(fset, name, s, kind))
+					}
+				}
+			}
+		}
+	}
+
+	return symbols, nil
+}
+
+func goTypeSymbol(fset *token.FileSet, spec *ast.TypeSpec, decl *ast.GenDecl) protocol.DocumentSymbol {
+	// a single type declaration covers the type keyword
+	var node ast.Node = spec
+	if len(decl.Specs) == 1 {
+		node = decl
+	}
+
+	switch t := spec.Type.(type) {
+	case *ast.StructType:
+		symbol := goSymbol(fset, spec.Name, node, protocol.SymbolKindStruct)
+		for _, field := range t.Fields.List {
+			for _, name := range field.Names {
+				child := goSymbol(fset, name, field, protocol.SymbolKindField)
+				child.Detail = stringPointer(goExpr(fset, field.Type))
+				symbol.Children = append(symbol.Children, child)
+			}
+		}
+		return symbol
+
+	case *ast.InterfaceType:
+		symbol := goSymbol(fset, spec.Name, node, protocol.SymbolKindInterface)
+		for _, method := range t.Methods.List {
+			for _, name := range method.Names {
+				symbol.Children = append(symbol.Children, goSymbol(fset, name, method, protocol.SymbolKindMethod))
+			}
+		}
+		return symbol
+
+	default:
+		symbol := goSymbol(fset, spec.Name, node, protocol.SymbolKindClass)
+		symbol.Detail = stringPointer(goExpr(fset, spec.Type))
+		return symbol
+	}
+}
+
+func goSymbol(fset *token.FileSet, name *ast.Ident, node ast.Node, kind protocol.SymbolKind) protocol.DocumentSymbol {
+	return protocol.DocumentSymbol{
+		Name:           name.Name,
+		Kind:           kind,
+		Range:          goRange(fset, node.Pos(), node.End()),
+		SelectionRange: goRange(fset, name.Pos(), name.End()),
+	}
+}
+
+func goRange(fset *token.FileSet, start, end token.Pos) protocol.Range {
+	return protocol.Range{Start: goPosition(fset, start), End: goPosition(fset, end)}
+}
+
+// goPosition converts 1-based token positions to 0-based protocol positions.
+func goPosition(fset *token.FileSet, pos token.Pos) protocol.Position {
+	p := fset.Position(pos)
+	return protocol.Position{Line: protocol.UInteger(max(p.Line-1, 0)), Character: protocol.UInteger(max(p.Column-1, 0))}
+}
+
+func goExpr(fset *token.FileSet, expr ast.Expr) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, expr); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+func stringPointer(s string) *string {
+	return &s
+}
@@ -0,0 +1,159 @@
+package parser
+
+import (
+	"regexp"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+var javaParser = braceParser{
+	charQuotes: true,
+	rules: []rule{
+		javaType("class", protocol.SymbolKindClass),
+		javaType("interface", protocol.SymbolKindInterface),
+		javaType("@interface", protocol.SymbolKindInterface),
+		javaType("enum", protocol.SymbolKindEnum),
+		javaType("record", protocol.SymbolKindStruct),
+		{
+			// at least a return type or a modifier precedes the name, which leaves out enum constants
+			pattern: regexp.MustCompile(`^\s*(?:@[\w.]+(?:\([^)]*\))?\s+)*(?:[\w$.<>\[\]?,]+\s+)+(?P<name>[\w$]+)\s*\(`),
+			kind:    protocol.SymbolKindMethod,
+			member:  true,
+			body:    true,
+		},
+	},
+	constructor: func(name, container string) bool { return name == container },
+}
+
+func javaType(keyword string, kind protocol.SymbolKind) rule {
+	return rule{
+		pattern:   regexp.MustCompile(`^\s*(?:@[\w.]+(?:\([^)]*\))?\s+)*(?:(?:public|protected|private|static|abstract|final|sealed|non-sealed|strictfp)\s+)*` + regexp.QuoteMeta(keyword) + `\s+(?P<name>[\w$]+)`),
+		kind:      kind,
+		container: true,
+		body:      true,
+	}
+}
+
+const rustVisibility = `^\s*(?:pub(?:\([^)]*\))?\s+)?`
+
+var rustParser = braceParser{
+	charQuotes: true,
+	rules: []rule{
+		{
+			pattern:    regexp.MustCompile(rustVisibility + `(?:(?:default|const|async|unsafe|extern(?:\s+"[^"]*")?)\s+)*fn\s+(?P<name>\w+)`),
+			kind:       protocol.SymbolKindFunction,
+			memberKind: protocol.SymbolKindMethod,
+			body:       true,
+		},
+		{
+			pattern: regexp.MustCompile(rustVisibility + `(?:struct|union)\s+(?P<name>\w+)`),
+			kind:    protocol.SymbolKindStruct,
+			body:    true,
+		},
+		{
+			pattern: regexp.MustCompile(rustVisibility + `enum\s+(?P<name>\w+)`),
+			kind:    protocol.SymbolKindEnum,
+			body:    true,
+		},
+		{
+			pattern:   regexp.MustCompile(rustVisibility + `(?:unsafe\s+)?trait\s+(?P<name>\w+)`),
+			kind:      protocol.SymbolKindInterface,
+			container: true,
+			body:      true,
+		},
+		{
+			// named like rust-analyzer does, e.g. "impl Display for Point"
+			pattern:   regexp.MustCompile(`^\s*(?:unsafe\s+)?(?P<name>impl\b[^{;]*)`),
+			kind:      protocol.SymbolKindObject,
+			container: true,
+			body:      true,
+		},
+		{
+			pattern:   regexp.MustCompile(rustVisibility + `mod\s+(?P<name>\w+)`),
+			kind:      protocol.SymbolKindModule,
+			container: true,
+			body:      true,
+		},
+		{
+			pattern: regexp.MustCompile(rustVisibility + `(?:const|static(?:\s+mut)?)\s+(?P<name>\w+)\s*:`),
+			kind:    protocol.SymbolKindConstant,
+		},
+		{
+			pattern: regexp.MustCompile(rustVisibility + `type\s+(?P<name>\w+)`),
+			kind:    protocol.SymbolKindTypeParameter,
+		},
+		{
+			pattern: regexp.MustCompile(`^\s*macro_rules!\s*(?P<name>\w+)`),
+			kind:    protocol.SymbolKindFunction,
+			body:    true,
+		},
+	},
+}
+
+const jsExport = `^\s*(?:export\s+)?(?:default\s+)?(?:declare\s+)?`
+
+// javaScriptRules are shared with TypeScript
+var javaScriptRules = []rule{
+	{
+		pattern: regexp.MustCompile(jsExport + `(?:async\s+)?function\s*\*?\s*(?P<name>[\w$]+)`),
+		kind:    protocol.SymbolKindFunction,
+		body:    true,
+	},
+	{
+		pattern:   regexp.MustCompile(jsExport + `(?:abstract\s+)?class\s+(?P<name>[\w$]+)`),
+		kind:      protocol.SymbolKindClass,
+		container: true,
+		body:      true,
+	},
+	{
+		// functions assigned to variables, e.g. const handler = async (req) => {
+		pattern: regexp.MustCompile(jsExport + `(?:const|let|var)\s+(?P<name>[\w$]+)\s*(?::[^=]+)?=\s*(?:async\s+)?(?:function\b|(?:\([^)]*\)|[\w$]+)\s*(?::[^=]+)?=>)`),
+		kind:    protocol.SymbolKindFunction,
+		body:    true,
+	},
+	{
+		pattern: regexp.MustCompile(jsExport + `const\s+(?P<name>[\w$]+)`),
+		kind:    protocol.SymbolKindConstant,
+	},
+	{
+		pattern: regexp.MustCompile(jsExport + `(?:let|var)\s+(?P<name>[\w$]+)`),
+		kind:    protocol.SymbolKindVariable,
+	},
+	{
+		pattern: regexp.MustCompile(`^\s*(?:(?:public|private|protected|static|readonly|abstract|override|async|get|set|declare)\s+)*\*?\s*(?P<name>#?[\w$]+)\s*(?:<[^>]*>)?\s*\(`),
+		kind:    protocol.SymbolKindMethod,
+		member:  true,
+		body:    true,
+	},
+}
+
+var javaScriptParser = braceParser{
+	rules:       javaScriptRules,
+	constructor: func(name, _ string) bool { return name == "constructor" },
+}
+
+var typeScriptParser = braceParser{
+	rules: append([]rule{
+		{
+			pattern: regexp.MustCompile(jsExport + `interface\s+(?P<name>[\w$]+)`),
+			kind:    protocol.SymbolKindInterface,
+			body:    true,
+		},
+		{
+			pattern: regexp.MustCompile(jsExport + `(?:const\s+)?enum\s+(?P<name>[\w$]+)`),
+			kind:    protocol.SymbolKindEnum,
+			body:    true,
+		},
+		{
+			pattern: regexp.MustCompile(jsExport + `type\s+(?P<name>[\w$]+)\s*(?:<[^=]*>)?\s*=`),
+			kind:    protocol.SymbolKindTypeParameter,
+		},
+		{
+			pattern:   regexp.MustCompile(jsExport + `(?:namespace|module)\s+(?P<name>[\w$.]+)`),
+			kind:      protocol.SymbolKindModule,
+			container: true,
+			body:      true,
+		},
+	}, javaScriptRules...),
+	constructor: func(name, _ string) bool { return name == "constructor" },
+}
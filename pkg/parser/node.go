@@ -0,0 +1,44 @@
+package parser
+
+import (
+	"strings"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// node builds the symbol tree while ranges are still open.
+type node struct {
+	symbol   protocol.DocumentSymbol
+	children []*node
+}
+
+func (n *node) add(child *node) {
+	n.children = append(n.children, child)
+}
+
+func (n *node) symbols() []protocol.DocumentSymbol {
+	out := make([]protocol.DocumentSymbol, 0, len(n.children))
+	for _, child := range n.children {
+		symbol := child.symbol
+		if len(child.children) > 0 {
+			symbol.Children = child.symbols()
+		}
+		out = append(out, symbol)
+	}
+	return out
+}
+
+// lineSymbol returns a symbol spanning the line from its first non-blank character, named by line[nameStart:nameEnd].
+func lineSymbol(line string, lineNumber, nameStart, nameEnd int, kind protocol.SymbolKind) protocol.DocumentSymbol {
+	start := len(line) - len(strings.TrimLeft(line, " \t"))
+	return protocol.DocumentSymbol{
+		Name:           line[nameStart:nameEnd],
+		Kind:           kind,
+		Range:          protocol.Range{Start: position(lineNumber, start), End: position(lineNumber, len(strings.TrimRight(line, " \t\r")))},
+		SelectionRange: protocol.Range{Start: position(lineNumber, nameStart), End: position(lineNumber, nameEnd)},
+	}
+}
+
+func position(line, character int) protocol.Position {
+	return protocol.Position{Line: protocol.UInteger(line), Character: protocol.UInteger(character)}
+}
@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hide-org/hide/pkg/lsp/v2"
+	"github.com/hide-org/hide/pkg/model"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// Parser finds declarations in source code without a language server. Declarations are found by syntax only, so
+// results are less precise than the ones of a language server.
+type Parser interface {
+	// Parse returns declarations of the file with members, e.g. methods of a class, as children.
+	Parse(content []byte) ([]protocol.DocumentSymbol, error)
+}
+
+// parsers by file extension
+var parsers = map[string]Parser{
+	".go":   goParser{},
+	".py":   pythonParser{},
+	".pyi":  pythonParser{},
+	".java": javaParser,
+	".rs":   rustParser,
+	".js":   javaScriptParser,
+	".jsx":  javaScriptParser,
+	".mjs":  javaScriptParser,
+	".cjs":  javaScriptParser,
+	".ts":   typeScriptParser,
+	".tsx":  typeScriptParser,
+	".mts":  typeScriptParser,
+	".cts":  typeScriptParser,
+}
+
+// ForPath returns the parser for the file at path.
+func ForPath(path string) (Parser, bool) {
+	p, ok := parsers[strings.ToLower(filepath.Ext(path))]
+	return p, ok
+}
+
+// Patterns returns glob patterns of files that can be parsed, e.g. for files.PatternFilter.
+func Patterns() []string {
+	patterns := make([]string, 0, len(parsers))
+	for ext := range parsers {
+		patterns = append(patterns, "*"+ext)
+	}
+	sort.Strings(patterns)
+	return patterns
+}
+
+// Outline returns the outline of the file. Returns UnsupportedFileError if there is no parser for the file.
+func Outline(file *model.File) (lsp.DocumentOutline, error) {
+	p, ok := ForPath(file.Path)
+	if !ok {
+		return lsp.DocumentOutline{}, NewUnsupportedFileError(file.Path)
+	}
+
+	symbols, err := p.Parse(file.GetContentBytes())
+	if err != nil {
+		return lsp.DocumentOutline{}, err
+	}
+
+	return lsp.NewDocumentOutline(file.Path, symbols, lsp.SymbolSourceParser), nil
+}
+
+// Symbols returns symbols of the files whose name contains query, ignoring case. Files without a parser are skipped.
+func Symbols(files []*model.File, query string, symbolFilter lsp.SymbolFilter) []lsp.SymbolInfo {
+	query = strings.ToLower(query)
+
+	result := []lsp.SymbolInfo{}
+	for _, file := range files {
+		p, ok := ForPath(file.Path)
+		if !ok {
+			continue
+		}
+
+		symbols, err := p.Parse(file.GetContentBytes())
+		if err != nil {
+			continue
+		}
+
+		walk(symbols, func(symbol protocol.DocumentSymbol) {
+			if !symbolFilter.Keep(symbol.Kind) || !strings.Contains(strings.ToLower(symbol.Name), query) {
+				return
+			}
+			result = append(result, lsp.NewSymbolInfo(file.Path, symbol, lsp.SymbolSourceParser))
+		})
+	}
+
+	return result
+}
+
+func walk(symbols []protocol.DocumentSymbol, fn func(symbol protocol.DocumentSymbol)) {
+	for _, symbol := range symbols {
+		fn(symbol)
+		walk(symbol.Children, fn)
+	}
+}
@@ -0,0 +1,233 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/lsp/v2"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/stretchr/testify/assert"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// outline renders symbols as "Kind Name start-end" lines with 1-based line numbers, children indented
+func outline(symbols []protocol.DocumentSymbol, indent string) []string {
+	var out []string
+	for _, s := range symbols {
+		out = append(out, fmt.Sprintf("%s%d %s %d-%d", indent, s.Kind, s.Name, s.Range.Start.Line+1, s.Range.End.Line+1))
+		out = append(out, outline(s.Children, indent+"  ")...)
+	}
+	return out
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    []string
+	}{
+		{
+			name: "go",
+			path: "main.go",
+			content: `package main
+
+const Version = "1"
+
+type Server struct {
+	Addr string
+}
+
+type Handler interface {
+	Handle()
+}
+
+type ID int
+
+func (s *Server) Start() error {
+	return nil
+}
+
+func main() {}`,
+			want: []string{
+				fmt.Sprintf("%d Version 3-3", protocol.SymbolKindConstant),
+				fmt.Sprintf("%d Server 5-7", protocol.SymbolKindStruct),
+				fmt.Sprintf("  %d Addr 6-6", protocol.SymbolKindField),
+				fmt.Sprintf("%d Handler 9-11", protocol.SymbolKindInterface),
+				fmt.Sprintf("  %d Handle 10-10", protocol.SymbolKindMethod),
+				fmt.Sprintf("%d ID 13-13", protocol.SymbolKindClass),
+				fmt.Sprintf("%d Start 15-17", protocol.SymbolKindMethod),
+				fmt.Sprintf("%d main 19-19", protocol.SymbolKindFunction),
+			},
+		},
+		{
+			name: "python",
+			path: "app.py",
+			content: `MAX_SIZE = 10
+
+class Store:
+    """Keeps items.
+
+    def not_a_method(self):
+    """
+
+    def __init__(self):
+        def helper():
+            pass
+
+    async def get(self, key):
+        return key
+
+def main():
+    pass
+`,
+			want: []string{
+				fmt.Sprintf("%d MAX_SIZE 1-1", protocol.SymbolKindConstant),
+				fmt.Sprintf("%d Store 3-14", protocol.SymbolKindClass),
+				fmt.Sprintf("  %d __init__ 9-11", protocol.SymbolKindConstructor),
+				fmt.Sprintf("  %d get 13-14", protocol.SymbolKindMethod),
+				fmt.Sprintf("%d main 16-17", protocol.SymbolKindFunction),
+			},
+		},
+		{
+			name: "typescript",
+			path: "src/app.ts",
+			content: `export interface Options {
+  port: number;
+}
+
+export type Handler = (req: Request) => void;
+
+export const handle = async (req: Request) => {
+  if (req) { return "}"; }
+};
+
+/* class Commented { */
+export class Server {
+  constructor(private options: Options = {}) {
+    this.options = options;
+  }
+
+  async start(): Promise<void> {
+    const x = { a: 1 };
+  }
+}
+
+function main() {}`,
+			want: []string{
+				fmt.Sprintf("%d Options 1-3", protocol.SymbolKindInterface),
+				fmt.Sprintf("%d Handler 5-5", protocol.SymbolKindTypeParameter),
+				fmt.Sprintf("%d handle 7-9", protocol.SymbolKindFunction),
+				fmt.Sprintf("%d Server 12-20", protocol.SymbolKindClass),
+				fmt.Sprintf("  %d constructor 13-15", protocol.SymbolKindConstructor),
+				fmt.Sprintf("  %d start 17-19", protocol.SymbolKindMethod),
+				fmt.Sprintf("%d main 22-22", protocol.SymbolKindFunction),
+			},
+		},
+		{
+			name: "java",
+			path: "Server.java",
+			content: `package app;
+
+@Service
+public class Server implements Runnable {
+    private final Map<String, Integer> counts = new HashMap<>();
+
+    public Server() {}
+
+    @Override
+    public void run() {
+        char c = '{';
+    }
+
+    interface Listener {
+        void onStart(String name);
+    }
+}`,
+			want: []string{
+				fmt.Sprintf("%d Server 4-17", protocol.SymbolKindClass),
+				fmt.Sprintf("  %d Server 7-7", protocol.SymbolKindConstructor),
+				fmt.Sprintf("  %d run 10-12", protocol.SymbolKindMethod),
+				fmt.Sprintf("  %d Listener 14-16", protocol.SymbolKindInterface),
+				fmt.Sprintf("    %d onStart 15-15", protocol.SymbolKindMethod),
+			},
+		},
+		{
+			name: "rust",
+			path: "src/lib.rs",
+			content: `pub struct Point<'a> {
+    name: &'a str,
+}
+
+impl<'a> Display for Point<'a> {
+    fn fmt(&self, f: &mut Formatter) -> Result {
+        write!(f, "{}", self.name)
+    }
+}
+
+pub trait Shape {
+    fn area(&self) -> f64;
+}
+
+pub const ORIGIN: i32 = 0;
+
+pub async fn run(
+    config: Config,
+) -> Result<()> {
+    Ok(())
+}`,
+			want: []string{
+				fmt.Sprintf("%d Point 1-3", protocol.SymbolKindStruct),
+				fmt.Sprintf("%d impl<'a> Display for Point<'a> 5-9", protocol.SymbolKindObject),
+				fmt.Sprintf("  %d fmt 6-8", protocol.SymbolKindMethod),
+				fmt.Sprintf("%d Shape 11-13", protocol.SymbolKindInterface),
+				fmt.Sprintf("  %d area 12-12", protocol.SymbolKindMethod),
+				fmt.Sprintf("%d ORIGIN 15-15", protocol.SymbolKindConstant),
+				fmt.Sprintf("%d run 17-21", protocol.SymbolKindFunction),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, ok := ForPath(tt.path)
+			assert.True(t, ok)
+
+			symbols, err := p.Parse([]byte(tt.content))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, outline(symbols, ""), strings.Join(outline(symbols, ""), "\n"))
+		})
+	}
+}
+
+func TestOutline(t *testing.T) {
+	_, err := Outline(model.NewFile("README.md", "# Hide"))
+	var unsupported *UnsupportedFileError
+	assert.ErrorAs(t, err, &unsupported)
+
+	got, err := Outline(model.NewFile("main.go", "package main\n\nfunc main() {}"))
+	assert.NoError(t, err)
+	assert.Equal(t, lsp.SymbolSourceParser, got.Source)
+	assert.Equal(t, "main.go", got.Path)
+	assert.Equal(t, []lsp.DocumentSymbol{{
+		Name:     "main",
+		Kind:     "Function",
+		Range:    lsp.Range{Start: lsp.Position{Line: 3, Character: 0}, End: lsp.Position{Line: 3, Character: 14}},
+		Children: []lsp.DocumentSymbol{},
+	}}, got.DocumentSymbols)
+}
+
+func TestSymbols(t *testing.T) {
+	files := []*model.File{
+		model.NewFile("main.go", "package main\n\ntype Server struct{}\n\nfunc NewServer() *Server { return nil }"),
+		model.NewFile("server.py", "class ServerError(Exception):\n    pass"),
+		model.NewFile("README.md", "Server"),
+	}
+
+	got := Symbols(files, "server", lsp.NewExcludeSymbolFilter(protocol.SymbolKindStruct))
+	assert.Equal(t, []lsp.SymbolInfo{
+		{Name: "NewServer", Kind: "Function", Location: lsp.Location{Path: "main.go", Range: lsp.Range{Start: lsp.Position{Line: 5, Character: 0}, End: lsp.Position{Line: 5, Character: 39}}}, Source: lsp.SymbolSourceParser},
+		{Name: "ServerError", Kind: "Class", Location: lsp.Location{Path: "server.py", Range: lsp.Range{Start: lsp.Position{Line: 1, Character: 0}, End: lsp.Position{Line: 2, Character: 8}}}, Source: lsp.SymbolSourceParser},
+	}, got)
+}
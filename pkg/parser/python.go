@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+var (
+	pythonDef      = regexp.MustCompile(`^(\s*)(?:async\s+)?def\s+(\w+)`)
+	pythonClass    = regexp.MustCompile(`^(\s*)class\s+(\w+)`)
+	pythonConstant = regexp.MustCompile(`^([A-Z_][A-Z0-9_]*)\s*(?::[^=]+)?=[^=]`)
+)
+
+// pythonParser finds classes, functions and module constants by indentation. Functions nested in functions are
+// left out.
+type pythonParser struct{}
+
+func (p pythonParser) Parse(content []byte) ([]protocol.DocumentSymbol, error) {
+	type scope struct {
+		node   *node
+		indent int
+	}
+
+	root := &node{}
+	var stack []scope
+	lastCode := 0 // last line with code, closed scopes end there
+	inString := false
+
+	lines := strings.Split(string(content), "\n")
+	closeScopes := func(indent int) {
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			top := stack[len(stack)-1]
+			top.node.symbol.Range.End = protocol.Position{Line: protocol.UInteger(lastCode), Character: protocol.UInteger(len(lines[lastCode]))}
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		startsInString := inString
+		// docstrings and other multi-line strings
+		if n := strings.Count(line, `"""`) + strings.Count(line, `'''`); n%2 == 1 {
+			inString = !inString
+		}
+		if startsInString || trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			if startsInString && trimmed != "" {
+				lastCode = i
+			}
+			continue
+		}
+
+		indent := indentation(line)
+		closeScopes(indent)
+		lastCode = i
+
+		var parent *node
+		if len(stack) > 0 {
+			parent = stack[len(stack)-1].node
+		}
+
+		var match []int
+		kind := protocol.SymbolKindFunction
+		if match = pythonClass.FindStringSubmatchIndex(line); match != nil {
+			kind = protocol.SymbolKindClass
+		} else if match = pythonDef.FindStringSubmatchIndex(line); match != nil {
+			if parent != nil && parent.symbol.Kind == protocol.SymbolKindClass {
+				kind = protocol.SymbolKindMethod
+				if line[match[4]:match[5]] == "__init__" {
+					kind = protocol.SymbolKindConstructor
+				}
+			}
+		} else if m := pythonConstant.FindStringSubmatchIndex(line); m != nil && parent == nil {
+			root.add(&node{symbol: lineSymbol(line, i, m[2], m[3], protocol.SymbolKindConstant)})
+			continue
+		}
+
+		if match == nil {
+			continue
+		}
+
+		// bodies of functions are not outlined
+		if parent != nil && parent.symbol.Kind != protocol.SymbolKindClass {
+			continue
+		}
+
+		n := &node{symbol: lineSymbol(line, i, match[4], match[5], kind)}
+		if parent != nil {
+			parent.add(n)
+		} else {
+			root.add(n)
+		}
+		stack = append(stack, scope{node: n, indent: indent})
+	}
+
+	closeScopes(0)
+	return root.symbols(), nil
+}
+
+func indentation(line string) int {
+	width := 0
+	for _, c := range line {
+		switch c {
+		case ' ':
+			width++
+		case '\t':
+			width += 8 - width%8
+		default:
+			return width
+		}
+	}
+	return width
+}
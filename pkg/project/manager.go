@@ -10,7 +10,7 @@ import (
 	"sync"
 	"time"
 
-	"github.com/hide-org/hide/pkg/devcontainer"
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
 	"github.com/hide-org/hide/pkg/files"
 	"github.com/hide-org/hide/pkg/git"
 	"github.com/hide-org/hide/pkg/lsp"
@@ -50,10 +50,14 @@ type Manager interface {
 	DeleteProject(ctx context.Context, projectId model.ProjectId) error
 	GetProject(ctx context.Context, projectId model.ProjectId) (model.Project, error)
 	GetProjects(ctx context.Context) ([]*model.Project, error)
+	// LifecycleLogs returns the output of the lifecycle commands of the project's devcontainer.
+	LifecycleLogs(ctx context.Context, projectId model.ProjectId) ([]devcontainer.LifecycleLog, error)
 	// Deprecated: use files.Service instead
 	ListFiles(ctx context.Context, projectId string, opts ...files.ListFileOption) (model.Files, error)
 	// Deprecated: use files.Service instead
 	ReadFile(ctx context.Context, projectId, path string) (*model.File, error)
+	// PortMappings returns the ports of the project's devcontainer published on the host, e.g. the app's HTTP endpoints.
+	PortMappings(ctx context.Context, projectId model.ProjectId) ([]devcontainer.PortMapping, error)
 	// Deprecated: use tasks.Service instead
 	ResolveTaskAlias(ctx context.Context, projectId model.ProjectId, alias string) (devcontainer.Task, error)
 	// Deprecated: use symbol.Service instead
@@ -149,7 +153,18 @@ func (pm ManagerImpl) CreateProject(ctx context.Context, request CreateProjectRe
 		return nil, fmt.Errorf("Failed to launch devcontainer: %w", err)
 	}
 
+	port, err := pm.devContainerRunner.HostPort(ctx, containerId, devcontainer.ServerPort)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get host port of the hide server")
+		if err := pm.devContainerRunner.Stop(context.WithoutCancel(ctx), containerId); err != nil {
+			log.Error().Err(err).Msgf("Failed to stop container %s", containerId)
+		}
+		removeProjectDir(projectPath)
+		return nil, fmt.Errorf("Failed to get host port of the hide server: %w", err)
+	}
+
 	project := model.NewProject(projectId, projectPath, model.Config{DevContainerConfig: devContainerConfig}, containerId, request.Repository)
+	project.Port = port
 
 	languages := request.Languages
 	if len(languages) == 0 {
@@ -228,6 +243,30 @@ func (pm ManagerImpl) DeleteProject(ctx context.Context, projectId string) error
 	return nil
 }
 
+func (pm ManagerImpl) PortMappings(ctx context.Context, projectId string) ([]devcontainer.PortMapping, error) {
+	project, err := pm.GetProject(ctx, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get project with id %s: %w", projectId, err)
+	}
+
+	mappings, err := pm.devContainerRunner.PortMappings(ctx, project.ContainerId, project.Config.DevContainerConfig)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to get port mappings of container %s", project.ContainerId)
+		return nil, fmt.Errorf("Failed to get port mappings: %w", err)
+	}
+
+	return mappings, nil
+}
+
+func (pm ManagerImpl) LifecycleLogs(ctx context.Context, projectId string) ([]devcontainer.LifecycleLog, error) {
+	project, err := pm.GetProject(ctx, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get project with id %s: %w", projectId, err)
+	}
+
+	return pm.devContainerRunner.LifecycleLogs(project.ContainerId), nil
+}
+
 func (pm ManagerImpl) ResolveTaskAlias(ctx context.Context, projectId string, alias string) (devcontainer.Task, error) {
 	log.Debug().Msgf("Resolving task alias %s for project %s", alias, projectId)
 
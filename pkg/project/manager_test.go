@@ -3,17 +3,21 @@ package project_test
 import (
 	"context"
 	"errors"
+	"path/filepath"
 	"reflect"
 	"testing"
 
-	"github.com/hide-org/hide/pkg/devcontainer"
-	dc_mocks "github.com/hide-org/hide/pkg/devcontainer/mocks"
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
+	dc_mocks "github.com/hide-org/hide/pkg/devcontainer/v2/mocks"
+	"github.com/hide-org/hide/pkg/git"
+	git_mocks "github.com/hide-org/hide/pkg/git/mocks"
 	"github.com/hide-org/hide/pkg/lsp"
 	lsp_mocks "github.com/hide-org/hide/pkg/lsp/mocks"
 	"github.com/hide-org/hide/pkg/model"
 	"github.com/hide-org/hide/pkg/project"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestProject_findTaskByAlias(t *testing.T) {
@@ -72,6 +76,75 @@ func TestManagerImpl_CreateProject(t *testing.T) {
 	t.Skip("Skipping test because it depends on external shell command `git` and file system")
 }
 
+func TestManagerImpl_CreateProject_ServerPort(t *testing.T) {
+	projectsRoot := t.TempDir()
+	config := &devcontainer.Config{DockerImageProps: devcontainer.DockerImageProps{Image: "test-image"}}
+
+	gitClient := git_mocks.NewMockClient()
+	gitClient.On("Clone", "https://github.com/example/repo.git", filepath.Join(projectsRoot, "project-id")).Return(&git.Repository{}, nil)
+
+	lspService := &lsp_mocks.MockLspService{}
+	lspService.On("StartServer", mock.Anything, lsp.LanguageId("Go")).Return(nil)
+
+	devContainerRunner := &dc_mocks.MockDevContainerRunner{
+		RunFunc: func(ctx context.Context, projectPath string, c devcontainer.Config) (string, error) {
+			return "test-container", nil
+		},
+		HostPortFunc: func(ctx context.Context, containerId string, port int) (int, error) {
+			assert.Equal(t, "test-container", containerId)
+			assert.Equal(t, devcontainer.ServerPort, port)
+			return 49153, nil
+		},
+	}
+
+	randomString := func(int) string { return "project-id" }
+	pm := project.NewProjectManager(devContainerRunner, project.NewInMemoryStore(map[string]*model.Project{}), projectsRoot, nil, lspService, nil, randomString, gitClient)
+
+	p, err := pm.CreateProject(context.Background(), project.CreateProjectRequest{
+		Repository:   model.Repository{Url: "https://github.com/example/repo.git"},
+		DevContainer: config,
+		Languages:    []lsp.LanguageId{"Go"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "test-container", p.ContainerId)
+	// the project records the host port Docker allocated for the hide server
+	assert.Equal(t, 49153, p.Port)
+}
+
+func TestManagerImpl_CreateProject_ServerPortNotPublished(t *testing.T) {
+	projectsRoot := t.TempDir()
+
+	gitClient := git_mocks.NewMockClient()
+	gitClient.On("Clone", mock.Anything, mock.Anything).Return(&git.Repository{}, nil)
+
+	var stopped string
+	devContainerRunner := &dc_mocks.MockDevContainerRunner{
+		RunFunc: func(ctx context.Context, projectPath string, c devcontainer.Config) (string, error) {
+			return "test-container", nil
+		},
+		HostPortFunc: func(ctx context.Context, containerId string, port int) (int, error) {
+			return 0, errors.New("port 8081 of container test-container is not published")
+		},
+		StopFunc: func(ctx context.Context, containerId string) error {
+			stopped = containerId
+			return nil
+		},
+	}
+
+	randomString := func(int) string { return "project-id" }
+	pm := project.NewProjectManager(devContainerRunner, project.NewInMemoryStore(map[string]*model.Project{}), projectsRoot, nil, nil, nil, randomString, gitClient)
+
+	_, err := pm.CreateProject(context.Background(), project.CreateProjectRequest{
+		Repository:   model.Repository{Url: "https://github.com/example/repo.git"},
+		DevContainer: &devcontainer.Config{},
+	})
+
+	assert.ErrorContains(t, err, "Failed to get host port of the hide server")
+	assert.Equal(t, "test-container", stopped)
+	assert.NoDirExists(t, filepath.Join(projectsRoot, "project-id"))
+}
+
 func TestManagerImpl_GetProject_Succeeds(t *testing.T) {
 	_project := model.Project{ID: "test-project", Path: "/tmp/test-project", Config: model.Config{}}
 	pm := project.NewProjectManager(nil, project.NewInMemoryStore(map[string]*model.Project{"test-project": &_project}), "/tmp", nil, nil, nil, nil, nil)
@@ -191,6 +264,55 @@ func TestManagerImpl_CreateTask_ExecError(t *testing.T) {
 	}
 }
 
+func TestManagerImpl_PortMappings(t *testing.T) {
+	const projectId = "test-project"
+	config := devcontainer.Config{GeneralProperties: devcontainer.GeneralProperties{
+		PortsAttributes: map[string]devcontainer.PortAttributes{"3000": {Label: "Application"}},
+	}}
+	_project := model.NewProject(projectId, "/tmp/test-project", model.Config{DevContainerConfig: config}, "test-container", model.Repository{})
+	mappings := []devcontainer.PortMapping{{ContainerPort: 3000, HostIP: "127.0.0.1", HostPort: 49153, Label: "Application"}}
+	devContainerRunner := &dc_mocks.MockDevContainerRunner{
+		PortMappingsFunc: func(ctx context.Context, containerId string, c devcontainer.Config) ([]devcontainer.PortMapping, error) {
+			// the labels come from the config stored with the project
+			assert.Equal(t, "test-container", containerId)
+			assert.Equal(t, config, c)
+			return mappings, nil
+		},
+	}
+	pm := project.NewProjectManager(devContainerRunner, project.NewInMemoryStore(map[string]*model.Project{projectId: &_project}), "/tmp", nil, nil, nil, nil, nil)
+
+	actual, err := pm.PortMappings(context.Background(), projectId)
+
+	assert.NoError(t, err)
+	assert.Equal(t, mappings, actual)
+}
+
+func TestManagerImpl_PortMappings_ProjectNotFound(t *testing.T) {
+	pm := project.NewProjectManager(nil, project.NewInMemoryStore(map[string]*model.Project{}), "/tmp", nil, nil, nil, nil, nil)
+	_, err := pm.PortMappings(context.Background(), "missing-project")
+
+	var projectNotFoundError *project.ProjectNotFoundError
+	assert.ErrorAs(t, err, &projectNotFoundError)
+}
+
+func TestManagerImpl_LifecycleLogs(t *testing.T) {
+	const projectId = "test-project"
+	_project := model.NewProject(projectId, "/tmp/test-project", model.Config{}, "test-container", model.Repository{})
+	logs := []devcontainer.LifecycleLog{{Stage: devcontainer.LifecyclePostCreate, Command: []string{"make", "setup"}}}
+	devContainerRunner := &dc_mocks.MockDevContainerRunner{
+		LifecycleLogsFunc: func(containerId string) []devcontainer.LifecycleLog {
+			assert.Equal(t, "test-container", containerId)
+			return logs
+		},
+	}
+	pm := project.NewProjectManager(devContainerRunner, project.NewInMemoryStore(map[string]*model.Project{projectId: &_project}), "/tmp", nil, nil, nil, nil, nil)
+
+	actual, err := pm.LifecycleLogs(context.Background(), projectId)
+
+	assert.NoError(t, err)
+	assert.Equal(t, logs, actual)
+}
+
 func TestManagerImpl_SearchSymbols(t *testing.T) {
 	symbols := []lsp.SymbolInfo{
 		{Name: "symbol1", Kind: "kind1", Location: lsp.Location{Path: "path1", Range: lsp.Range{Start: lsp.Position{Line: 1, Character: 1}, End: lsp.Position{Line: 2, Character: 2}}}},
@@ -3,7 +3,7 @@ package mocks
 import (
 	"context"
 
-	"github.com/hide-org/hide/pkg/devcontainer"
+	"github.com/hide-org/hide/pkg/devcontainer/v2"
 	"github.com/hide-org/hide/pkg/files"
 	"github.com/hide-org/hide/pkg/lsp"
 	"github.com/hide-org/hide/pkg/model"
@@ -21,7 +21,9 @@ type MockProjectManager struct {
 	DeleteProjectFunc    func(ctx context.Context, projectId string) error
 	GetProjectFunc       func(ctx context.Context, projectId string) (model.Project, error)
 	GetProjectsFunc      func(ctx context.Context) ([]*model.Project, error)
+	LifecycleLogsFunc    func(ctx context.Context, projectId string) ([]devcontainer.LifecycleLog, error)
 	ListFilesFunc        func(ctx context.Context, projectId string, opts ...files.ListFileOption) (model.Files, error)
+	PortMappingsFunc     func(ctx context.Context, projectId string) ([]devcontainer.PortMapping, error)
 	ReadFileFunc         func(ctx context.Context, projectId, path string) (*model.File, error)
 	ResolveTaskAliasFunc func(ctx context.Context, projectId string, alias string) (devcontainer.Task, error)
 	SearchSymbolsFunc    func(ctx context.Context, projectId model.ProjectId, query string, symbolFilter lsp.SymbolFilter) ([]lsp.SymbolInfo, error)
@@ -46,6 +48,14 @@ func (m *MockProjectManager) DeleteProject(ctx context.Context, projectId string
 	return m.DeleteProjectFunc(ctx, projectId)
 }
 
+func (m *MockProjectManager) LifecycleLogs(ctx context.Context, projectId string) ([]devcontainer.LifecycleLog, error) {
+	return m.LifecycleLogsFunc(ctx, projectId)
+}
+
+func (m *MockProjectManager) PortMappings(ctx context.Context, projectId string) ([]devcontainer.PortMapping, error) {
+	return m.PortMappingsFunc(ctx, projectId)
+}
+
 func (m *MockProjectManager) ResolveTaskAlias(ctx context.Context, projectId string, alias string) (devcontainer.Task, error) {
 	return m.ResolveTaskAliasFunc(ctx, projectId, alias)
 }
@@ -15,8 +15,6 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-const HideBin = "/go/bin/hide_linux_arm64"
-
 type Repository struct {
 	Url    string  `json:"url" validate:"required,url"`
 	Commit *string `json:"commit,omitempty"`
@@ -97,6 +95,7 @@ func (pm ManagerImpl) CreateProject(ctx context.Context, request CreateProjectRe
 	project := model.Project{
 		ID:          workspace.ID(),
 		ContainerId: workspace.ID(),
+		Port:        port,
 	}
 
 	// Save project in store
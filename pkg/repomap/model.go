@@ -0,0 +1,67 @@
+package repomap
+
+import (
+	"fmt"
+	"strings"
+)
+
+type RepoMap struct {
+	Files []File `json:"files"`
+	// Tokens is the estimated number of tokens of the rendered map
+	Tokens int `json:"tokens"`
+	// Omitted is the number of files left out to stay within the token budget
+	Omitted int `json:"omitted"`
+}
+
+type File struct {
+	Path string `json:"path"`
+	// Rank is the sum of references to the symbols of the file
+	Rank     int      `json:"rank"`
+	Relevant bool     `json:"relevant,omitempty"`
+	Symbols  []Symbol `json:"symbols"`
+}
+
+type Symbol struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	Line int    `json:"line"`
+	// References counts occurrences of the name in other files
+	References int      `json:"references"`
+	Children   []Symbol `json:"children,omitempty"`
+}
+
+// String renders the map as compact text, e.g.
+//
+//	pkg/server.go
+//	  Struct Server :10 (12 refs)
+//	    Method Start :16 (3 refs)
+func (m RepoMap) String() string {
+	var sb strings.Builder
+	for _, file := range m.Files {
+		sb.WriteString(file.String())
+	}
+	if m.Omitted > 0 {
+		fmt.Fprintf(&sb, "... %d more files\n", m.Omitted)
+	}
+	return sb.String()
+}
+
+func (f File) String() string {
+	var sb strings.Builder
+	sb.WriteString(f.Path)
+	sb.WriteString("\n")
+	writeSymbols(&sb, f.Symbols, "  ")
+	return sb.String()
+}
+
+func writeSymbols(sb *strings.Builder, symbols []Symbol, indent string) {
+	for _, symbol := range symbols {
+		fmt.Fprintf(sb, "%s%s %s :%d (%d refs)\n", indent, symbol.Kind, symbol.Name, symbol.Line, symbol.References)
+		writeSymbols(sb, symbol.Children, indent+"  ")
+	}
+}
+
+// estimateTokens approximates the number of tokens of text, about 4 characters per token.
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
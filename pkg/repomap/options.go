@@ -0,0 +1,25 @@
+package repomap
+
+// budget of the map when none is given, enough for a first overview
+const defaultTokenBudget = 1024
+
+type MapOptions struct {
+	// Query biases the map towards files whose path or symbols match it. Matching files are expanded with members.
+	Query string
+	// TokenBudget caps the estimated number of tokens of the rendered map
+	TokenBudget int
+}
+
+type MapOption func(opts *MapOptions)
+
+func MapWithQuery(query string) MapOption {
+	return func(opts *MapOptions) {
+		opts.Query = query
+	}
+}
+
+func MapWithTokenBudget(budget int) MapOption {
+	return func(opts *MapOptions) {
+		opts.TokenBudget = budget
+	}
+}
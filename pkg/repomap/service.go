@@ -0,0 +1,185 @@
+package repomap
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/outline"
+	"github.com/hide-org/hide/pkg/symbols"
+)
+
+var identifier = regexp.MustCompile(`[A-Za-z_$][A-Za-z0-9_$]*`)
+
+// top-level kinds of the map, types and functions
+var topLevelKinds = []string{"Class", "Struct", "Interface", "Enum", "Function", "Method", "Module", "Namespace", "Object", "TypeParameter"}
+
+// member kinds shown for expanded files
+var memberKinds = []string{"Method", "Constructor", "Function", "Field", "Property", "EnumMember"}
+
+type Service interface {
+	// Map summarises the workspace as top-level types and functions per file. Files are ranked by how often their
+	// symbols are referenced in other files and added until the token budget is used up.
+	Map(ctx context.Context, opts ...MapOption) (RepoMap, error)
+}
+
+type ServiceImpl struct {
+	files   files.Service
+	outline outline.Service
+	symbols symbols.Service
+}
+
+func NewService(files files.Service, outline outline.Service, symbols symbols.Service) Service {
+	return &ServiceImpl{files: files, outline: outline, symbols: symbols}
+}
+
+func (s *ServiceImpl) Map(ctx context.Context, opts ...MapOption) (RepoMap, error) {
+	opt := &MapOptions{TokenBudget: defaultTokenBudget}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	outlines, err := s.outline.List(ctx, "", outline.ListWithDepth(2))
+	if err != nil {
+		return RepoMap{}, fmt.Errorf("failed to list outlines: %w", err)
+	}
+
+	workspaceFiles, err := s.files.ListFiles(ctx, files.ListFilesWithContent())
+	if err != nil {
+		return RepoMap{}, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	// identifiers of source files, other files such as lock files would skew the counts
+	identifiers := make(map[string]map[string]int, len(outlines))
+	for _, o := range outlines {
+		identifiers[o.Path] = nil
+	}
+	for _, file := range workspaceFiles {
+		if _, ok := identifiers[file.Path]; ok {
+			identifiers[file.Path] = countIdentifiers(file.GetContent())
+		}
+	}
+
+	total := make(map[string]int)
+	for _, counts := range identifiers {
+		for name, count := range counts {
+			total[name] += count
+		}
+	}
+
+	relevant := s.relevantFiles(ctx, opt.Query)
+
+	mapped := make([]File, 0, len(outlines))
+	for _, o := range outlines {
+		file := File{Path: o.Path, Relevant: relevant[o.Path]}
+		references := func(name string) int {
+			return total[name] - identifiers[o.Path][name]
+		}
+
+		for _, symbol := range o.DocumentSymbols {
+			if !slices.Contains(topLevelKinds, symbol.Kind) {
+				continue
+			}
+
+			mappedSymbol := Symbol{Name: symbol.Name, Kind: symbol.Kind, Line: symbol.Range.Start.Line, References: references(symbol.Name)}
+			if opt.Query != "" && matches(symbol.Name, opt.Query) {
+				file.Relevant = true
+			}
+
+			for _, child := range symbol.Children {
+				if !slices.Contains(memberKinds, child.Kind) {
+					continue
+				}
+				if opt.Query != "" && matches(child.Name, opt.Query) {
+					file.Relevant = true
+				}
+				mappedSymbol.Children = append(mappedSymbol.Children, Symbol{Name: child.Name, Kind: child.Kind, Line: child.Range.Start.Line, References: references(child.Name)})
+			}
+
+			file.Rank += mappedSymbol.References
+			file.Symbols = append(file.Symbols, mappedSymbol)
+		}
+
+		if len(file.Symbols) == 0 {
+			continue
+		}
+
+		if opt.Query != "" && matches(o.Path, opt.Query) {
+			file.Relevant = true
+		}
+
+		// only relevant files are expanded with members
+		if !file.Relevant {
+			for i := range file.Symbols {
+				file.Symbols[i].Children = nil
+			}
+		}
+
+		mapped = append(mapped, file)
+	}
+
+	slices.SortStableFunc(mapped, func(a, b File) int {
+		if a.Relevant != b.Relevant {
+			if a.Relevant {
+				return -1
+			}
+			return 1
+		}
+		if a.Rank != b.Rank {
+			return b.Rank - a.Rank
+		}
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	result := RepoMap{Files: []File{}}
+	for _, file := range mapped {
+		tokens := estimateTokens(file.String())
+		if opt.TokenBudget > 0 && result.Tokens+tokens > opt.TokenBudget {
+			// smaller files further down may still fit
+			result.Omitted++
+			continue
+		}
+
+		result.Files = append(result.Files, file)
+		result.Tokens += tokens
+	}
+
+	return result, nil
+}
+
+// relevantFiles returns files with workspace symbols matching the query.
+func (s *ServiceImpl) relevantFiles(ctx context.Context, query string) map[string]bool {
+	relevant := make(map[string]bool)
+	if query == "" {
+		return relevant
+	}
+
+	found, err := s.symbols.Search(ctx, query)
+	if err != nil {
+		log.Warn().Err(err).Str("query", query).Msg("Failed to search symbols for repo map")
+		return relevant
+	}
+
+	for _, symbol := range found {
+		relevant[symbol.Location.Path] = true
+	}
+
+	return relevant
+}
+
+func countIdentifiers(content string) map[string]int {
+	counts := make(map[string]int)
+	for _, name := range identifier.FindAllString(content, -1) {
+		counts[name]++
+	}
+	return counts
+}
+
+func matches(s, query string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(query))
+}
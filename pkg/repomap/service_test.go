@@ -0,0 +1,130 @@
+package repomap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/lsp/v2"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/hide-org/hide/pkg/outline"
+	"github.com/hide-org/hide/pkg/symbols"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+type fakeFiles struct {
+	files.Service
+	files model.Files
+}
+
+func (f fakeFiles) ListFiles(ctx context.Context, opts ...files.ListFileOption) (model.Files, error) {
+	return f.files, nil
+}
+
+type fakeOutline struct {
+	outline.Service
+	outlines []lsp.DocumentOutline
+}
+
+func (f fakeOutline) List(ctx context.Context, pattern string, opts ...outline.ListOption) ([]lsp.DocumentOutline, error) {
+	return f.outlines, nil
+}
+
+type fakeSymbols struct {
+	symbols []lsp.SymbolInfo
+}
+
+func (f fakeSymbols) Search(ctx context.Context, query string, opts ...symbols.SearchOption) ([]lsp.SymbolInfo, error) {
+	var out []lsp.SymbolInfo
+	for _, symbol := range f.symbols {
+		if matches(symbol.Name, query) {
+			out = append(out, symbol)
+		}
+	}
+	return out, nil
+}
+
+func symbol(kind, name string, line int, children ...lsp.DocumentSymbol) lsp.DocumentSymbol {
+	return lsp.DocumentSymbol{Name: name, Kind: kind, Range: lsp.Range{Start: lsp.Position{Line: line}}, Children: children}
+}
+
+type fakeClient struct {
+	lsp.Client
+	symbols []protocol.SymbolInformation
+}
+
+func (c *fakeClient) WaitReady(ctx context.Context) error {
+	return nil
+}
+
+func (c *fakeClient) GetWorkspaceSymbols(ctx context.Context, params protocol.WorkspaceSymbolParams) ([]protocol.SymbolInformation, error) {
+	return c.symbols, nil
+}
+
+// readyLsp reports a ready language server, the symbols come from the clients of the service.
+type readyLsp struct {
+	lsp.Service
+}
+
+func (l readyLsp) Health(ctx context.Context) []lsp.ServerHealth {
+	return []lsp.ServerHealth{{Server: "gopls", Status: lsp.ServerStatusRunning, Ready: true}}
+}
+
+func newTestService() Service {
+	return newTestServiceWithSymbols(fakeSymbols{symbols: []lsp.SymbolInfo{{Name: "NewConfig", Location: lsp.Location{Path: "config.go"}}}})
+}
+
+func newTestServiceWithSymbols(symbols symbols.Service) Service {
+	return NewService(
+		fakeFiles{files: model.Files{
+			model.NewFile("server.go", "type Server struct{}\nfunc (s *Server) Start() {}"),
+			model.NewFile("main.go", "func main() { var s Server = Server{}; s.Start(); NewConfig() }"),
+			model.NewFile("config.go", "func NewConfig() {}"),
+			model.NewFile("go.sum", "Server Server Server"),
+		}},
+		fakeOutline{outlines: []lsp.DocumentOutline{
+			{Path: "config.go", DocumentSymbols: []lsp.DocumentSymbol{symbol("Function", "NewConfig", 1)}},
+			{Path: "main.go", DocumentSymbols: []lsp.DocumentSymbol{symbol("Function", "main", 1)}},
+			{Path: "server.go", DocumentSymbols: []lsp.DocumentSymbol{symbol("Struct", "Server", 1, symbol("Method", "Start", 2)), symbol("Variable", "debug", 3)}},
+		}},
+		symbols,
+	)
+}
+
+func TestMap(t *testing.T) {
+	got, err := newTestService().Map(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "server.go\n  Struct Server :1 (2 refs)\nconfig.go\n  Function NewConfig :1 (1 refs)\nmain.go\n  Function main :1 (0 refs)\n", got.String())
+	assert.Equal(t, 0, got.Omitted)
+}
+
+func TestMapQuery(t *testing.T) {
+	got, err := newTestService().Map(context.Background(), MapWithQuery("config"), MapWithTokenBudget(20))
+	assert.NoError(t, err)
+	assert.Equal(t, "config.go\n  Function NewConfig :1 (1 refs)\nmain.go\n  Function main :1 (0 refs)\n... 1 more files\n", got.String())
+	assert.True(t, got.Files[0].Relevant)
+	assert.Equal(t, 1, got.Omitted)
+}
+
+func TestMapQueryExpandsMembers(t *testing.T) {
+	got, err := newTestService().Map(context.Background(), MapWithQuery("start"))
+	assert.NoError(t, err)
+	assert.Equal(t, "server.go", got.Files[0].Path)
+	assert.Equal(t, []Symbol{{Name: "Start", Kind: "Method", Line: 2, References: 1}}, got.Files[0].Symbols[0].Children)
+}
+
+func TestMapQueryLanguageServerSymbols(t *testing.T) {
+	pool := lsp.NewClientPool()
+	pool.Set("Go", &fakeClient{symbols: []protocol.SymbolInformation{
+		{Name: "NewConfig", Kind: protocol.SymbolKindFunction, Location: protocol.Location{URI: "file:///workspace/config.go"}},
+	}})
+	lspService := readyLsp{lsp.NewService(lsp.NewLanguageDetector(), lsp.NewDiagnosticsStore(), pool, "file:///workspace")}
+
+	got, err := newTestServiceWithSymbols(symbols.NewService(lspService, fakeFiles{})).Map(context.Background(), MapWithQuery("newconf"))
+	require.NoError(t, err)
+	require.NotEmpty(t, got.Files)
+	assert.Equal(t, "config.go", got.Files[0].Path)
+	assert.True(t, got.Files[0].Relevant)
+}
@@ -0,0 +1,84 @@
+package symbols
+
+import (
+	"strings"
+	"unicode"
+)
+
+// score rates how well the name matches the query, higher is better. Returns false if the name does not match in
+// the mode. Qualified names such as "Server.Start" also match by their last segment.
+func score(name, query string, mode MatchMode) (int, bool) {
+	best, ok := scoreName(name, query, mode)
+	if i := strings.LastIndexAny(name, ".:"); i >= 0 && i < len(name)-1 {
+		if s, found := scoreName(name[i+1:], query, mode); found && (!ok || s > best) {
+			best, ok = s, true
+		}
+	}
+	return best, ok
+}
+
+func scoreName(name, query string, mode MatchMode) (int, bool) {
+	lowerName, lowerQuery := strings.ToLower(name), strings.ToLower(query)
+
+	switch {
+	case name == query:
+		return 1000, true
+	case lowerName == lowerQuery:
+		return 900, true
+	case mode == MatchModeExact:
+		return 0, false
+	case strings.HasPrefix(name, query):
+		return 800 - len(name), true
+	case strings.HasPrefix(lowerName, lowerQuery):
+		return 700 - len(name), true
+	case mode == MatchModePrefix:
+		return 0, false
+	case strings.Contains(lowerName, lowerQuery):
+		return 600 - len(name), true
+	}
+
+	return fuzzyScore(name, query)
+}
+
+// fuzzyScore matches the characters of the query in order. Characters at word starts, e.g. "NS" in NewServer,
+// score higher, gaps lower.
+func fuzzyScore(name, query string) (int, bool) {
+	if query == "" {
+		return 0, true
+	}
+
+	runes := []rune(name)
+	q := []rune(strings.ToLower(query))
+	score, qi, last := 0, 0, -1
+	for i, r := range runes {
+		if qi == len(q) {
+			break
+		}
+		if unicode.ToLower(r) != q[qi] {
+			continue
+		}
+
+		if wordStart(runes, i) {
+			score += 10
+		}
+		if last >= 0 {
+			score -= i - last - 1
+		}
+		last = i
+		qi++
+	}
+
+	if qi < len(q) {
+		return 0, false
+	}
+	// substring matches rank higher
+	return min(score, 500), true
+}
+
+func wordStart(runes []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev, r := runes[i-1], runes[i]
+	return (unicode.IsUpper(r) && !unicode.IsUpper(prev)) || (!unicode.IsLetter(prev) && !unicode.IsDigit(prev))
+}
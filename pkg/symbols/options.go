@@ -0,0 +1,43 @@
+package symbols
+
+import (
+	"github.com/hide-org/hide/pkg/lsp/v2"
+)
+
+type MatchMode = string
+
+const (
+	// MatchModeExact keeps symbols named like the query, ignoring case
+	MatchModeExact MatchMode = "exact"
+	// MatchModePrefix keeps symbols whose name starts with the query, ignoring case
+	MatchModePrefix MatchMode = "prefix"
+	// MatchModeFuzzy keeps symbols whose name contains the characters of the query in order, ignoring case
+	MatchModeFuzzy MatchMode = "fuzzy"
+)
+
+type SearchOptions struct {
+	Filter lsp.SymbolFilter
+	// Path is a glob restricting symbols to matching files, e.g. "pkg/**/*.go"
+	Path string
+	Mode MatchMode
+}
+
+type SearchOption func(opts *SearchOptions)
+
+func SearchWithFilter(filter lsp.SymbolFilter) SearchOption {
+	return func(opts *SearchOptions) {
+		opts.Filter = filter
+	}
+}
+
+func SearchWithPath(pattern string) SearchOption {
+	return func(opts *SearchOptions) {
+		opts.Path = pattern
+	}
+}
+
+func SearchWithMode(mode MatchMode) SearchOption {
+	return func(opts *SearchOptions) {
+		opts.Mode = mode
+	}
+}
@@ -3,39 +3,127 @@ package symbols
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
+	"github.com/gobwas/glob"
 	"github.com/rs/zerolog/log"
 
+	"github.com/hide-org/hide/pkg/files"
 	"github.com/hide-org/hide/pkg/lsp/v2"
+	"github.com/hide-org/hide/pkg/parser"
 )
 
 type Service interface {
-	Search(ctx context.Context, query string, symbolFilter lsp.SymbolFilter) ([]lsp.SymbolInfo, error)
+	// Search returns symbols matching the query, best matches first.
+	Search(ctx context.Context, query string, opts ...SearchOption) ([]lsp.SymbolInfo, error)
 }
 
 type ServiceImpl struct {
-	lsp lsp.Service
+	lsp   lsp.Service
+	files files.Service
 }
 
-func NewService(lsp lsp.Service) Service {
-	return &ServiceImpl{lsp: lsp}
+func NewService(lsp lsp.Service, files files.Service) Service {
+	return &ServiceImpl{lsp: lsp, files: files}
 }
 
-func (s *ServiceImpl) Search(ctx context.Context, query string, symbolFilter lsp.SymbolFilter) ([]lsp.SymbolInfo, error) {
+// Search returns workspace symbols from language servers. Symbols are parsed instead if no language server is ready,
+// or language servers find nothing.
+func (s *ServiceImpl) Search(ctx context.Context, query string, opts ...SearchOption) ([]lsp.SymbolInfo, error) {
 	log.Debug().Str("query", query).Msg("Searching symbols")
 
+	opt := &SearchOptions{Mode: MatchModeFuzzy}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	var path glob.Glob
+	if opt.Path != "" {
+		var err error
+		if path, err = glob.Compile(opt.Path, '/'); err != nil {
+			return nil, fmt.Errorf("invalid path pattern %s: %w", opt.Path, err)
+		}
+	}
+
 	select {
 	case <-ctx.Done():
 		return nil, fmt.Errorf("context cancelled")
 	default:
 	}
 
-	symbols, err := s.lsp.GetWorkspaceSymbols(ctx, query, symbolFilter)
+	if s.ready(ctx) {
+		symbols, err := s.lsp.GetWorkspaceSymbols(ctx, query, opt.Filter)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to get workspace symbols")
+		}
+
+		symbols = rank(symbols, query, opt.Mode, path)
+		if len(symbols) > 0 {
+			log.Debug().Str("query", query).Msgf("found %d symbols", len(symbols))
+			return symbols, nil
+		}
+	}
+
+	workspaceFiles, err := s.files.ListFiles(ctx, files.ListFilesWithContent(), files.ListFilesWithFilter(files.PatternFilter{Include: parser.Patterns()}))
 	if err != nil {
-		log.Error().Err(err).Msg("failed to get workspace symbols")
-		return nil, fmt.Errorf("failed to get workspace symbols: %w", err)
+		return nil, fmt.Errorf("failed to list files: %w", err)
 	}
 
-	log.Debug().Str("query", query).Msgf("found %d symbols", len(symbols))
+	// all symbols are parsed, matching is up to the mode
+	symbols := rank(parser.Symbols(workspaceFiles, "", opt.Filter), query, opt.Mode, path)
+	log.Debug().Str("query", query).Msgf("parsed %d symbols", len(symbols))
 	return symbols, nil
 }
+
+// ready reports whether any language server is running and done indexing.
+func (s *ServiceImpl) ready(ctx context.Context) bool {
+	for _, health := range s.lsp.Health(ctx) {
+		if health.Status == lsp.ServerStatusRunning && health.Ready {
+			return true
+		}
+	}
+	return false
+}
+
+// rank keeps symbols matching the query in files matching path, best matches first. Equal matches keep shorter
+// names, then sort by location.
+func rank(symbols []lsp.SymbolInfo, query string, mode MatchMode, path glob.Glob) []lsp.SymbolInfo {
+	type scored struct {
+		symbol lsp.SymbolInfo
+		score  int
+	}
+
+	matches := make([]scored, 0, len(symbols))
+	for _, symbol := range symbols {
+		if path != nil && !path.Match(symbol.Location.Path) {
+			continue
+		}
+
+		score, ok := score(symbol.Name, query, mode)
+		if !ok {
+			continue
+		}
+
+		matches = append(matches, scored{symbol: symbol, score: score})
+	}
+
+	slices.SortStableFunc(matches, func(a, b scored) int {
+		if a.score != b.score {
+			return b.score - a.score
+		}
+		if len(a.symbol.Name) != len(b.symbol.Name) {
+			return len(a.symbol.Name) - len(b.symbol.Name)
+		}
+		if c := strings.Compare(a.symbol.Location.Path, b.symbol.Location.Path); c != 0 {
+			return c
+		}
+		return a.symbol.Location.Range.Start.Line - b.symbol.Location.Range.Start.Line
+	})
+
+	result := make([]lsp.SymbolInfo, 0, len(matches))
+	for _, m := range matches {
+		result = append(result, m.symbol)
+	}
+	return result
+}
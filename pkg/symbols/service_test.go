@@ -0,0 +1,120 @@
+package symbols
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gobwas/glob"
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/lsp/v2"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		mode  MatchMode
+		want  bool
+	}{
+		{name: "Server", query: "server", mode: MatchModeExact, want: true},
+		{name: "NewServer", query: "server", mode: MatchModeExact, want: false},
+		{name: "Server.Start", query: "start", mode: MatchModeExact, want: true},
+		{name: "ServerError", query: "serv", mode: MatchModePrefix, want: true},
+		{name: "NewServer", query: "serv", mode: MatchModePrefix, want: false},
+		{name: "NewServer", query: "nsrv", mode: MatchModeFuzzy, want: true},
+		{name: "NewServer", query: "rvs", mode: MatchModeFuzzy, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+"/"+tt.query, func(t *testing.T) {
+			_, ok := score(tt.name, tt.query, tt.mode)
+			assert.Equal(t, tt.want, ok)
+		})
+	}
+}
+
+func TestRank(t *testing.T) {
+	symbol := func(name, path string) lsp.SymbolInfo {
+		return lsp.SymbolInfo{Name: name, Location: lsp.Location{Path: path}}
+	}
+
+	symbols := []lsp.SymbolInfo{
+		symbol("newServerConfig", "pkg/config/config.go"),
+		symbol("SERVICE_ERROR", "pkg/errors.go"),
+		symbol("NewServer", "pkg/server/server.go"),
+		symbol("ServerTest", "test/server_test.go"),
+		symbol("Server", "pkg/server/server.go"),
+		symbol("server", "cmd/main.go"),
+	}
+
+	names := func(symbols []lsp.SymbolInfo) []string {
+		var out []string
+		for _, s := range symbols {
+			out = append(out, s.Name)
+		}
+		return out
+	}
+
+	got := rank(symbols, "Server", MatchModeFuzzy, nil)
+	assert.Equal(t, []string{"Server", "server", "ServerTest", "NewServer", "newServerConfig", "SERVICE_ERROR"}, names(got))
+
+	got = rank(symbols, "Server", MatchModePrefix, glob.MustCompile("pkg/**", '/'))
+	assert.Equal(t, []string{"Server"}, names(got))
+}
+
+type fakeClient struct {
+	lsp.Client
+	symbols []protocol.SymbolInformation
+}
+
+func (c *fakeClient) WaitReady(ctx context.Context) error {
+	return nil
+}
+
+func (c *fakeClient) GetWorkspaceSymbols(ctx context.Context, params protocol.WorkspaceSymbolParams) ([]protocol.SymbolInformation, error) {
+	return c.symbols, nil
+}
+
+// readyLsp reports a ready language server, the symbols come from the clients of the service.
+type readyLsp struct {
+	lsp.Service
+}
+
+func (l readyLsp) Health(ctx context.Context) []lsp.ServerHealth {
+	return []lsp.ServerHealth{{Server: "gopls", Status: lsp.ServerStatusRunning, Ready: true}}
+}
+
+type fakeFiles struct {
+	files.Service
+}
+
+func (f fakeFiles) ListFiles(ctx context.Context, opts ...files.ListFileOption) (model.Files, error) {
+	return nil, nil
+}
+
+func TestService_Search_Path(t *testing.T) {
+	symbol := func(name string, uri protocol.DocumentUri) protocol.SymbolInformation {
+		return protocol.SymbolInformation{Name: name, Kind: protocol.SymbolKindStruct, Location: protocol.Location{URI: uri}}
+	}
+
+	pool := lsp.NewClientPool()
+	pool.Set("Go", &fakeClient{symbols: []protocol.SymbolInformation{
+		symbol("Server", "file:///workspace/pkg/server/server.go"),
+		symbol("Server", "file:///workspace/cmd/server.go"),
+		symbol("ServerConfig", "file:///workspace/pkg/config/server.go"),
+	}})
+	service := NewService(readyLsp{lsp.NewService(lsp.NewLanguageDetector(), lsp.NewDiagnosticsStore(), pool, "file:///workspace")}, fakeFiles{})
+
+	symbols, err := service.Search(context.Background(), "Server", SearchWithPath("pkg/**"))
+	require.NoError(t, err)
+
+	var paths []string
+	for _, s := range symbols {
+		paths = append(paths, s.Name+" "+s.Location.Path)
+	}
+	assert.Equal(t, []string{"Server pkg/server/server.go", "ServerConfig pkg/config/server.go"}, paths)
+}
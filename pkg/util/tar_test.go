@@ -0,0 +1,80 @@
+package util_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/util"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tarEntry struct {
+	name    string
+	content string
+	dir     bool
+}
+
+func writeTar(t *testing.T, entries ...tarEntry) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, entry := range entries {
+		if entry.dir {
+			require.NoError(t, tw.WriteHeader(&tar.Header{Name: entry.name, Mode: 0o755, Typeflag: tar.TypeDir}))
+			continue
+		}
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: entry.name, Mode: 0o644, Size: int64(len(entry.content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(entry.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return &buf
+}
+
+func TestExtractTar(t *testing.T) {
+	tests := []struct {
+		name          string
+		entries       []tarEntry
+		expected      map[string]string
+		expectedError string
+	}{
+		{
+			name:     "Files",
+			entries:  []tarEntry{{name: "install.sh", content: "#!/bin/sh"}, {name: "lib/util.sh", content: "util"}},
+			expected: map[string]string{"/out/install.sh": "#!/bin/sh", "/out/lib/util.sh": "util"},
+		},
+		{
+			name:     "Root entry",
+			entries:  []tarEntry{{name: "./", dir: true}, {name: "./install.sh", content: "#!/bin/sh"}},
+			expected: map[string]string{"/out/install.sh": "#!/bin/sh"},
+		},
+		{
+			name:          "Path outside of dir",
+			entries:       []tarEntry{{name: "../escape.sh", content: "rm -rf /"}},
+			expectedError: "invalid path ../escape.sh in archive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+
+			err := util.ExtractTar(fs, writeTar(t, tt.entries...), "/out")
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			for path, want := range tt.expected {
+				got, err := afero.ReadFile(fs, path)
+				require.NoError(t, err)
+				assert.Equal(t, want, string(got))
+			}
+		})
+	}
+}